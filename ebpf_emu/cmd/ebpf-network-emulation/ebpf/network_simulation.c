@@ -194,6 +194,10 @@ int tc_main(struct __sk_buff *skb)
     if (!val_struct) {
         return TC_ACT_OK;
     }
+    // 链路被管理性关闭（断开/网络分区）时丢弃所有匹配流量
+    if (val_struct->admin_state == LINK_ADMIN_DOWN) {
+        return TC_ACT_SHOT;
+    }
     throttle_rate_bps = &val_struct->throttle_rate_bps;
     // Safety check, go on if no handle could be retrieved
     if (!throttle_rate_bps)  {
@@ -22,6 +22,7 @@ type edtHandleBpsDelay struct {
 	TcHandle        uint32
 	ThrottleRateBps uint32
 	DelayMs         uint32
+	AdminState      uint32
 }
 
 // loadEdt returns the embedded CollectionSpec for edt.
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"netsimlation/distribute/ebpf/internal/linkmap"
+	"netsimlation/distribute/ebpf/pkg/instance"
+
+	"github.com/cilium/ebpf"
+)
+
+// checkOwner 接口已由其他实例挂载时返回错误
+func checkOwner(iface string) error {
+	other, err := inst.Owner(netns_id, iface)
+	if err != nil {
+		return fmt.Errorf("check instances attached to %s: %w", iface, err)
+	}
+	if other != nil {
+		return fmt.Errorf("%s is already attached by instance %s, run -clear -instance %s first", iface, other.Name, other.Name)
+	}
+	return nil
+}
+
+// printInstances 列出本机的全部实例：固定目录、映射中的链路数以及挂载的接口
+func printInstances() error {
+	all, err := instance.List()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "INSTANCE\tPIN PATH\tLINKS\tINTERFACES")
+	for _, in := range all {
+		links := "-"
+		m, err := ebpf.LoadPinnedMap(in.MapPath(), &ebpf.LoadPinOptions{ReadOnly: true})
+		if err == nil {
+			entries, err := linkmap.Dump(m)
+			m.Close()
+			if err != nil {
+				return fmt.Errorf("read %s: %w", in.MapPath(), err)
+			}
+			links = fmt.Sprint(len(entries))
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("load pinned map %s: %w", in.MapPath(), err)
+		}
+
+		attachments, err := in.Attachments()
+		if err != nil {
+			return err
+		}
+		// 默认实例在从未使用时不列出
+		if in.Name == instance.Default && links == "-" && len(attachments) == 0 {
+			continue
+		}
+		names := make([]string, 0, len(attachments))
+		for _, a := range attachments {
+			name := a.String()
+			if a.TCX {
+				name += " (tcx)"
+			}
+			names = append(names, name)
+		}
+		ifaces := strings.Join(names, ", ")
+		if ifaces == "" {
+			ifaces = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", in.Name, in.PinPath, links, ifaces)
+	}
+	return w.Flush()
+}
@@ -1,10 +1,17 @@
 package main
 
 import (
+	"errors"
 	"flag"
+	"fmt"
 	"log"
+	"os"
+	"path/filepath"
 
-	"netsimlation/distribute/ebpf/internal/utils"
+	"netsimlation/distribute/ebpf/internal/linkmap"
+	"netsimlation/distribute/ebpf/pkg/instance"
+	"netsimlation/distribute/ebpf/pkg/netns"
+	"netsimlation/distribute/ebpf/pkg/tc"
 
 	"github.com/cilium/ebpf"
 	"github.com/vishvananda/netlink"
@@ -12,50 +19,280 @@ import (
 
 //go:generate go run github.com/cilium/ebpf/cmd/bpf2go edt ebpf/network_simulation.c -- -I../headers
 
-const (
-	PIN_PATH = "/sys/fs/bpf/"
-)
+const FILTER_NAME = "edt_bandwidth"
+
+// PINNED_MAPS 按名称固定在实例的 pin_path 下、由该实例所有接口共享的映射
+var PINNED_MAPS = []string{linkmap.MapName, "progs"}
 
 var (
-	iface_name *string
-	clear_flag *bool
+	iface_name     *string
+	clear_flag     *bool
+	status_flag    *bool
+	watch_flag     *bool
+	netns_flag     *string
+	attach_mode    *string
+	upgrade_flag   *bool
+	preflight_flag *bool
+	instance_flag  *string
+	pin_path_flag  *string
+	instances_flag *bool
+
+	// inst 本次运行操作的实例，程序数组与映射固定在 pin_path 下
+	inst     *instance.Instance
+	pin_path string
+	// state_dir 挂载前的 qdisc 快照。bpffs 中只能固定 bpf 对象，快照放在同样随重启清空的 /run 下，
+	// 与固定映射的生命周期一致。指定 -netns 时按命名空间区分，避免不同容器中同名接口的快照互相覆盖
+	state_dir string
+	// link_dir TCX 链接的固定目录，每个接口一个，以接口名命名；与 state_dir 同样按命名空间区分
+	link_dir string
+	// netns_id 目标网络命名空间的 ID，本机命名空间为空
+	netns_id string
 )
 
 func init() {
-	iface_name = flag.String("iface", "", "目标网卡接口名称，用于挂载或清理eBPF程序")
+	iface_name = flag.String("iface", "", "目标网卡接口名称，用于挂载或清理eBPF程序；支持逗号分隔的列表和 glob 模式，如 eth0,veth*")
 	clear_flag = flag.Bool("clear", false, "清理指定网卡接口上的eBPF程序和TC组件")
+	netns_flag = flag.String("netns", "", "在指定的网络命名空间中操作接口：ip netns 名称、命名空间文件路径或容器进程PID")
+	status_flag = flag.Bool("status", false, "查看挂载状态：qdisc、程序ID/tag、固定映射以及每个接口的链路数；未指定 -iface 时列出所有已挂载的接口")
+	watch_flag = flag.Bool("watch", false, "挂载后持续监听网卡事件：自动挂载到之后创建的匹配接口，并清理已删除接口的链路条目")
+	upgrade_flag = flag.Bool("upgrade", false, "用本程序内置的新版本原子替换指定接口上正在运行的程序：复用固定映射与各流的状态，不修改 qdisc，映射布局不兼容时不做任何修改")
+	preflight_flag = flag.Bool("preflight", false, "检查内核与环境是否满足数据面的全部要求（bpffs、fq、clsact/TCX、辅助函数、skb->tstamp 等）并给出修复建议，不修改任何配置；指定 -iface 时同时报告这些接口")
+	instance_flag = flag.String("instance", "", "实例名称：不同实例使用独立的固定映射、TCX 链接与 qdisc 快照，同一主机上的多个实验互不干扰；留空为默认实例（"+linkmap.DefaultPinPath+"）")
+	pin_path_flag = flag.String("pin-path", "", "固定映射所在的 bpffs 目录，默认为实例的目录（命名实例为 "+instance.PinRoot+"<instance>/）")
+	instances_flag = flag.Bool("instances", false, "列出本机上的全部实例及其固定目录、链路数和挂载的接口")
+	attach_mode = flag.String("attach-mode", "auto", "挂载方式：tcx 通过固定在实例目录 "+instance.LinkDirName+"/ 下的 bpf_link 挂载（内核 6.6+，不占用 tc 优先级，可原子替换），clsact 使用传统的 tc 过滤器，auto 优先 tcx 并在旧内核上回退到 clsact")
+}
+
+// attachOptions 接口的挂载参数
+func attachOptions(iface netlink.Link, progFD int) tc.AttachOptions {
+	mode, _ := tc.ParseAttachMode(*attach_mode)
+	return tc.AttachOptions{
+		ProgFD:      progFD,
+		Name:        FILTER_NAME,
+		StateDir:    state_dir,
+		Mode:        mode,
+		LinkPinPath: filepath.Join(link_dir, iface.Attrs().Name),
+	}
+}
+
+// printStatus 打印固定映射以及各接口的挂载状态
+func printStatus(ifaces []netlink.Link, all bool) error {
+	// 按网卡索引统计已配置的链路数
+	links := make(map[uint32]int)
+
+	fmt.Printf("Instance %s, pinned maps:\n", inst.Name)
+	for _, name := range PINNED_MAPS {
+		path := filepath.Join(pin_path, name)
+		m, err := ebpf.LoadPinnedMap(path, &ebpf.LoadPinOptions{ReadOnly: true})
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Printf("  %-22s not pinned\n", name)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("load pinned map %s: %w", path, err)
+		}
+		fmt.Printf("  %-22s %s, max entries %d", path, m.Type(), m.MaxEntries())
+		if name == linkmap.MapName {
+			entries, err := linkmap.Dump(m)
+			if err != nil {
+				m.Close()
+				return err
+			}
+			for key := range entries {
+				links[key.Ifindex]++
+			}
+			fmt.Printf(", %d entries", len(entries))
+		}
+		fmt.Println()
+		m.Close()
+	}
+
+	shown := 0
+	for _, iface := range ifaces {
+		status, err := tc.GetStatus(iface)
+		if err != nil {
+			return fmt.Errorf("read tc status of %s: %w", iface.Attrs().Name, err)
+		}
+		link, tcx := tc.TCXAttached(iface, filepath.Join(link_dir, iface.Attrs().Name))
+		attached := status.Attached(FILTER_NAME) || tcx
+		if all && !attached {
+			continue
+		}
+		shown++
+
+		fmt.Printf("\nInterface %s (ifindex %d): ", status.Name, status.Index)
+		switch {
+		case tcx:
+			fmt.Printf("attached (tcx link %d, prog id %d)\n", link.ID, link.ProgID)
+		case attached:
+			fmt.Println("attached (clsact)")
+		default:
+			fmt.Println("not attached")
+		}
+		for _, q := range status.Qdiscs {
+			fmt.Printf("  qdisc  %-8s handle %-8s parent %s\n", q.Kind, q.Handle, q.Parent)
+		}
+		for _, f := range status.Filters {
+			fmt.Printf("  filter %-14s egress pref %d, prog id %d, tag %s\n", f.Name, f.Priority, f.ProgID, f.ProgTag)
+		}
+		fmt.Printf("  links  %d", links[uint32(status.Index)])
+		// 映射键不含命名空间，其他命名空间中同一索引的接口的条目也计入此处
+		if shared, err := inst.SharedIfindex(netns_id, status.Index); err == nil && len(shared) > 0 {
+			fmt.Printf(" (ifindex shared with %s of this instance; their entries are counted too)", attachmentList(shared))
+		}
+		fmt.Println()
+	}
+	if all && shown == 0 {
+		fmt.Println("\nNo interface has the eBPF program attached")
+	}
+	return nil
+}
+
+// attach 在网卡上安装 fq 并通过 TCX 或 clsact 挂载已加载的程序，重复执行时原子替换程序或复用已有组件，失败时不留下部分配置
+func attach(iface netlink.Link, objs *edtObjects) error {
+	// 已被其他实例挂载的接口不能再挂载，否则会替换对方的 fq 与程序
+	if err := checkOwner(iface.Attrs().Name); err != nil {
+		return err
+	}
+	log.Printf("Attaching eBPF program to the egress direction of %s...", iface.Attrs().Name)
+	mode, err := tc.Attach(iface, attachOptions(iface, objs.edtPrograms.TcMain.FD()))
+	if err != nil {
+		return err
+	}
+	log.Printf("已挂载到 %s (%s)", iface.Attrs().Name, mode)
+	return nil
 }
 
 func main() {
 	flag.Parse()
 
+	var err error
+	if inst, err = instance.Lookup(*instance_flag, *pin_path_flag); err != nil {
+		log.Fatalf("错误: %v", err)
+	}
+	pin_path = inst.PinPath
+	state_dir = inst.SnapshotDir("")
+	link_dir = inst.LinkDir("")
+
+	if *instances_flag {
+		if err := printInstances(); err != nil {
+			log.Fatalf("错误: %v", err)
+		}
+		return
+	}
+
+	// 切换到目标命名空间后，接口查询、TC 操作和网卡事件订阅都在该命名空间中进行；
+	// 程序与固定映射属于全局的 bpffs，不受影响。映射中的网卡索引即命名空间内的索引，与数据面看到的一致
+	if *netns_flag != "" {
+		ns, err := netns.Open(*netns_flag)
+		if err != nil {
+			log.Fatalf("错误: %v", err)
+		}
+		netns_id, err = ns.ID()
+		if err != nil {
+			log.Fatalf("错误: 读取网络命名空间 %s 失败: %v", ns.Path, err)
+		}
+		if _, err := ns.Enter(); err != nil {
+			log.Fatalf("错误: %v", err)
+		}
+		state_dir = inst.SnapshotDir(netns_id)
+		link_dir = inst.LinkDir(netns_id)
+		log.Printf("已进入网络命名空间 %s", ns.Path)
+	}
+	if inst.Name != instance.Default {
+		log.Printf("实例 %s，固定目录 %s", inst.Name, pin_path)
+	}
+
+	if _, err := tc.ParseAttachMode(*attach_mode); err != nil {
+		log.Fatalf("错误: %v", err)
+	}
+
+	if *preflight_flag {
+		var ifaces []netlink.Link
+		if *iface_name != "" {
+			patterns, err := tc.ParseIfacePatterns(*iface_name)
+			if err == nil {
+				ifaces, err = patterns.Links()
+			}
+			if err != nil {
+				log.Fatalf("错误: %v", err)
+			}
+		}
+		if !preflight(ifaces) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *status_flag {
+		var ifaces []netlink.Link
+		var err error
+		if *iface_name == "" {
+			ifaces, err = netlink.LinkList()
+		} else {
+			var patterns tc.IfacePatterns
+			patterns, err = tc.ParseIfacePatterns(*iface_name)
+			if err == nil {
+				ifaces, err = patterns.Links()
+			}
+		}
+		if err != nil {
+			log.Fatalf("错误: 获取网卡接口失败: %v", err)
+		}
+		if err := printStatus(ifaces, *iface_name == ""); err != nil {
+			log.Fatalf("错误: %v", err)
+		}
+		return
+	}
+
 	// 检查是否指定了网卡接口
 	if *iface_name == "" {
 		log.Fatalf("错误: 必须使用 -iface 参数指定目标网卡接口名称")
 	}
-
-	// 获取网络接口
-	iface, err := utils.GetIface(*iface_name)
+	patterns, err := tc.ParseIfacePatterns(*iface_name)
 	if err != nil {
-		log.Fatalf("错误: 找不到指定的网卡接口 %s: %v\n请使用 -iface 参数指定正确的网卡名称", *iface_name, err)
+		log.Fatalf("错误: %v", err)
+	}
+
+	// 获取匹配的网络接口，-watch 模式下允许暂时没有匹配的接口
+	ifaces, err := patterns.Links()
+	if err != nil && !*watch_flag {
+		log.Fatalf("错误: %v\n请使用 -iface 参数指定正确的网卡名称或模式", err)
 	}
 
 	// 检查是否只需要清理
 	if *clear_flag {
-		log.Printf("正在清理 %s 接口上的 eBPF 程序和 TC 组件...", *iface_name)
-		if err := utils.ClearEbpf(iface); err != nil {
-			log.Fatalf("清理失败: %v", err)
+		for _, iface := range ifaces {
+			log.Printf("正在清理 %s 接口上的 eBPF 程序和 TC 组件...", iface.Attrs().Name)
+			if err := tc.Detach(iface, attachOptions(iface, 0)); err != nil {
+				log.Fatalf("清理失败: %v", err)
+			}
 		}
 		log.Printf("清理完成")
 		return
 	}
 
-	// 正常加载 eBPF 程序
+	if *upgrade_flag {
+		if err := upgrade(ifaces); err != nil {
+			log.Fatalf("升级失败: %v", err)
+		}
+		log.Printf("已升级 %d 个接口上的程序", len(ifaces))
+		return
+	}
+
+	if err := os.MkdirAll(pin_path, 0755); err != nil {
+		log.Fatalf("错误: 创建固定目录 %s 失败: %v", pin_path, err)
+	}
+	if err := inst.Save(); err != nil {
+		log.Fatalf("错误: 记录实例 %s 失败: %v", inst.Name, err)
+	}
+
+	// 正常加载 eBPF 程序，实例的所有接口共享同一份程序和固定映射
 	objs := edtObjects{}
 
 	opts := ebpf.CollectionOptions{
 		Maps: ebpf.MapOptions{
-			PinPath: PIN_PATH,
+			PinPath: pin_path,
 		},
 	}
 
@@ -64,30 +301,28 @@ func main() {
 	}
 	defer objs.Close()
 
-	progFd := objs.edtPrograms.TcMain.FD()
-
-	// Create clsact qdisc
-	if _, err := utils.CreateClsactQdisc(iface); err != nil {
-		log.Fatalf("cannot create clsact qdisc: %v", err)
-	}
-
-	// Create fq qdisc
-	if _, err := utils.CreateFQdisc(iface); err != nil {
-		log.Fatalf("cannot create fq qdisc: %v", err)
-	}
-
-	// 固定使用egress方向
-	handle := uint32(netlink.HANDLE_MIN_EGRESS)
-	log.Printf("Attaching eBPF program to the egress direction of %s...", *iface_name)
-	
-	// Attach bpf program
-	if _, err := utils.CreateTCBpfFilter(iface, progFd, handle, "edt_bandwidth"); err != nil {
-		log.Fatalf("cannot create bpf filter: %v", err)
-	}
-
 	// Update jump map with delay prog
 	err = objs.Progs.Update(uint32(0), uint32(objs.SetDelay.FD()), ebpf.UpdateAny)
 	if err != nil {
 		println("Update", err.Error())
 	}
+
+	for _, iface := range ifaces {
+		if err := attach(iface, &objs); err != nil {
+			if errors.Is(err, tc.ErrTCXUnsupported) {
+				log.Fatalf("挂载失败，内核不支持 TCX，请使用 -attach-mode auto 或 clsact: %v", err)
+			}
+			if errors.Is(err, tc.ErrQdiscUnsupported) {
+				log.Fatalf("挂载失败，内核缺少 fq 或 clsact 支持（sch_fq/sch_ingress 模块），可运行 -preflight 检查: %v", err)
+			}
+			log.Fatalf("挂载失败: %v", err)
+		}
+	}
+	log.Printf("已挂载到 %d 个接口", len(ifaces))
+
+	if *watch_flag {
+		if err := watchLinks(patterns, ifaces, &objs); err != nil {
+			log.Fatalf("监听网卡事件失败: %v", err)
+		}
+	}
 }
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"netsimlation/distribute/ebpf/internal/linkmap"
+	"netsimlation/distribute/ebpf/pkg/netns"
+	"netsimlation/distribute/ebpf/pkg/tc"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/asm"
+	"github.com/cilium/ebpf/btf"
+	"github.com/cilium/ebpf/features"
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// 检查结果的级别：WARN 表示缺少时仍可运行但功能受限，FAIL 表示挂载或仿真无法工作
+const (
+	checkOK = iota
+	checkWarn
+	checkFail
+)
+
+// preflightReport 汇总各项检查的输出
+type preflightReport struct {
+	failed int
+	warned int
+}
+
+// check 打印一项检查。err 为 nil 时通过；level 为不通过时的级别，hint 为可执行的修复建议
+func (r *preflightReport) check(name string, detail string, err error, level int, hint string) {
+	if err == nil {
+		if detail != "" {
+			name += ": " + detail
+		}
+		fmt.Printf("[ OK ] %s\n", name)
+		return
+	}
+	tag := "WARN"
+	if level == checkFail {
+		tag = "FAIL"
+		r.failed++
+	} else {
+		r.warned++
+	}
+	if errors.Is(err, ebpf.ErrNotSupported) {
+		err = errors.New("not supported by the kernel")
+	}
+	fmt.Printf("[%s] %s: %v\n", tag, name, err)
+	if hint != "" {
+		fmt.Printf("       -> %s\n", hint)
+	}
+}
+
+// preflight 检查运行 EDT 数据面所需的全部条件，ifaces 非空时额外报告这些接口的情况。
+// 只在临时网络命名空间中试探 qdisc 与 TCX，不修改本机的接口和固定映射
+func preflight(ifaces []netlink.Link) bool {
+	r := &preflightReport{}
+
+	// 权限
+	r.check("privileges", "", checkCapabilities(), checkFail,
+		"run as root, or grant CAP_BPF (or CAP_SYS_ADMIN) and CAP_NET_ADMIN")
+
+	if code, err := features.LinuxVersionCode(); err == nil {
+		r.check("kernel", fmt.Sprintf("%d.%d.%d", code>>16, (code>>8)&0xff, code&0xff), nil, checkOK, "")
+	}
+
+	// 文件系统
+	r.check("bpffs at "+pin_path, "instance "+inst.Name, checkBpffs(pin_path), checkFail,
+		"mount -t bpf bpf "+linkmap.DefaultPinPath+" (or add it to /etc/fstab), or choose a -pin-path inside bpffs")
+	r.check("state dir "+state_dir, "", checkWritable(state_dir), checkFail,
+		"make "+state_dir+" writable; qdisc snapshots used by -clear are stored there")
+	_, err := btf.LoadKernelSpec()
+	r.check("kernel BTF", "/sys/kernel/btf/vmlinux", err, checkWarn,
+		"not needed by the EDT program, but bpftool and map pretty-printing need CONFIG_DEBUG_INFO_BTF=y")
+
+	// 程序、映射与辅助函数
+	r.check("program type sched_cls", "", features.HaveProgramType(ebpf.SchedCLS), checkFail,
+		"kernel needs CONFIG_NET_CLS_BPF and CONFIG_BPF_SYSCALL")
+	for _, m := range []struct {
+		typ   ebpf.MapType
+		level int
+		hint  string
+	}{
+		{ebpf.Hash, checkFail, "kernel needs CONFIG_BPF_SYSCALL"},
+		{ebpf.ProgramArray, checkFail, "tail calls into set_delay need BPF_MAP_TYPE_PROG_ARRAY"},
+		{ebpf.RingBuf, checkWarn, "ring buffers need Linux 5.8+; only future per-packet telemetry uses them"},
+	} {
+		r.check("map type "+m.typ.String(), "", features.HaveMapType(m.typ), m.level, m.hint)
+	}
+	for _, fn := range []asm.BuiltinFunc{
+		asm.FnMapLookupElem, asm.FnMapUpdateElem, asm.FnKtimeGetNs,
+		asm.FnProbeReadKernel, asm.FnTailCall, asm.FnSkbEcnSetCe,
+	} {
+		hint := "upgrade the kernel"
+		if fn == asm.FnSkbEcnSetCe {
+			hint = "bpf_skb_ecn_set_ce needs Linux 5.1+"
+		}
+		r.check("helper "+helperName(fn), "", features.HaveProgramHelper(ebpf.SchedCLS, fn), checkFail, hint)
+	}
+	r.check("skb->tstamp writes in tc programs", "", probeTstampWrite(), checkFail,
+		"earliest departure time pacing needs Linux 4.20+")
+	r.check("EDT program", "passes the verifier", probeEdtObjects(), checkFail,
+		"the built-in object does not load on this kernel; see the verifier log above")
+	r.check("pinned maps", "compatible with the built-in object", checkPinnedLayout(), checkFail,
+		"export the entries with map-populator, run -clear on every interface, remove the pins and attach again")
+
+	// qdisc 与挂载方式
+	var clsactErr, fqErr, tcxErr error
+	err = netns.Temporary(func() error {
+		lo, err := netlink.LinkByName("lo")
+		if err != nil {
+			return err
+		}
+		if err := netlink.LinkSetUp(lo); err != nil {
+			return err
+		}
+		clsactErr = netlink.QdiscAdd(&netlink.GenericQdisc{
+			QdiscAttrs: netlink.QdiscAttrs{LinkIndex: lo.Attrs().Index, Handle: netlink.MakeHandle(0xffff, 0), Parent: netlink.HANDLE_CLSACT},
+			QdiscType:  "clsact",
+		})
+		fqErr = netlink.QdiscAdd(&netlink.Fq{
+			QdiscAttrs: netlink.QdiscAttrs{LinkIndex: lo.Attrs().Index, Handle: tc.FQ_HANDLE, Parent: netlink.HANDLE_ROOT},
+		})
+		tcxErr = probeTCX(lo)
+		return nil
+	})
+	if err != nil {
+		r.check("qdisc probes", "", err, checkFail, "probing needs CAP_SYS_ADMIN to create a temporary network namespace")
+	} else {
+		r.check("clsact qdisc", "", qdiscError(clsactErr), checkWarn,
+			"modprobe sch_ingress (CONFIG_NET_SCH_INGRESS); needed unless tcx is available")
+		r.check("fq qdisc", "", qdiscError(fqErr), checkFail,
+			"modprobe sch_fq (CONFIG_NET_SCH_FQ); fq enforces the departure times set by the program")
+		r.check("tcx attachment", "", tcxErr, checkWarn,
+			"needs Linux 6.6+; -attach-mode auto falls back to the clsact filter")
+		if clsactErr != nil && tcxErr != nil {
+			r.check("attach path", "", errors.New("neither tcx nor clsact is available"), checkFail, "")
+		}
+	}
+
+	for _, iface := range ifaces {
+		attrs := iface.Attrs()
+		detail := fmt.Sprintf("ifindex %d, %d tx queues", attrs.Index, attrs.NumTxQueues)
+		if attrs.NumTxQueues > 1 {
+			detail += " (mq with one fq per queue)"
+		}
+		if _, mode, err := tc.AttachedProgram(iface, attachOptions(iface, 0)); err == nil {
+			detail += ", attached (" + string(mode) + ")"
+		}
+		r.check("interface "+attrs.Name, detail, nil, checkOK, "")
+	}
+
+	fmt.Printf("\n%d failed, %d warnings\n", r.failed, r.warned)
+	return r.failed == 0
+}
+
+// checkCapabilities 读取 /proc/self/status 中的有效能力集
+func checkCapabilities() error {
+	data, err := os.ReadFile("/proc/self/status")
+	if err != nil {
+		return err
+	}
+	var eff uint64
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "CapEff:") {
+			eff, err = strconv.ParseUint(strings.TrimSpace(strings.TrimPrefix(line, "CapEff:")), 16, 64)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	has := func(c int) bool { return eff&(1<<uint(c)) != 0 }
+	var missing []string
+	if !has(unix.CAP_NET_ADMIN) {
+		missing = append(missing, "CAP_NET_ADMIN")
+	}
+	if !has(unix.CAP_BPF) && !has(unix.CAP_SYS_ADMIN) {
+		missing = append(missing, "CAP_BPF")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// checkBpffs 检查 path 位于 bpffs 中，尚未创建的实例目录检查其最近的已存在上级目录
+func checkBpffs(path string) error {
+	var st unix.Statfs_t
+	dir := path
+	for {
+		err := unix.Statfs(dir, &st)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, unix.ENOENT) || filepath.Dir(dir) == dir {
+			return err
+		}
+		dir = filepath.Dir(dir)
+	}
+	if st.Type != unix.BPF_FS_MAGIC {
+		return fmt.Errorf("%s is not a bpf filesystem", path)
+	}
+	return nil
+}
+
+// checkWritable 目录或其最近的已存在上级目录须可写，挂载时才能创建快照。只做 access 检查，不创建任何文件
+func checkWritable(dir string) error {
+	for {
+		err := unix.Access(dir, unix.W_OK|unix.X_OK)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, unix.ENOENT) || filepath.Dir(dir) == dir {
+			return fmt.Errorf("%s: %w", dir, err)
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// probeTstampWrite 加载一个写 skb->tstamp 的 sched_cls 程序，旧内核的验证器会拒绝该访问
+func probeTstampWrite() error {
+	const tstampOffset = 152 // offsetof(struct __sk_buff, tstamp)
+	prog, err := ebpf.NewProgram(&ebpf.ProgramSpec{
+		Type:    ebpf.SchedCLS,
+		License: "GPL",
+		Instructions: asm.Instructions{
+			// 旧内核不允许对上下文使用 BPF_ST，经寄存器写入与 tc_main 生成的代码一致
+			asm.Mov.Imm(asm.R2, 0),
+			asm.StoreMem(asm.R1, tstampOffset, asm.R2, asm.DWord),
+			asm.Mov.Imm(asm.R0, 0),
+			asm.Return(),
+		},
+	})
+	if err != nil {
+		return errors.New("verifier rejects writing skb->tstamp")
+	}
+	return prog.Close()
+}
+
+// probeEdtObjects 以不固定映射的方式加载内置对象，确认其能通过本机内核的验证器
+func probeEdtObjects() error {
+	spec, err := loadEdt()
+	if err != nil {
+		return err
+	}
+	for _, m := range spec.Maps {
+		m.Pinning = ebpf.PinNone
+	}
+	coll, err := ebpf.NewCollection(spec)
+	if err != nil {
+		var ve *ebpf.VerifierError
+		if errors.As(err, &ve) {
+			fmt.Printf("%+v\n", ve)
+		}
+		return err
+	}
+	coll.Close()
+	return nil
+}
+
+// checkPinnedLayout 已固定的映射须与内置对象布局一致，否则加载时会复用失败或读写错位
+func checkPinnedLayout() error {
+	spec, err := loadEdt()
+	if err != nil {
+		return err
+	}
+	for _, name := range PINNED_MAPS {
+		if _, err := os.Stat(filepath.Join(pin_path, name)); errors.Is(err, os.ErrNotExist) {
+			delete(spec.Maps, name)
+		}
+	}
+	return checkPinnedMaps(spec)
+}
+
+// probeTCX 在 lo 上以 TCX 挂载一个空程序后立即卸下
+func probeTCX(lo netlink.Link) error {
+	prog, err := ebpf.NewProgram(&ebpf.ProgramSpec{
+		Type:         ebpf.SchedCLS,
+		License:      "GPL",
+		Instructions: asm.Instructions{asm.Mov.Imm(asm.R0, 0), asm.Return()},
+	})
+	if err != nil {
+		return err
+	}
+	defer prog.Close()
+	l, err := tc.AttachTCX(lo, prog.FD())
+	if err != nil {
+		if errors.Is(err, tc.ErrTCXUnsupported) {
+			return tc.ErrTCXUnsupported
+		}
+		return err
+	}
+	return l.Close()
+}
+
+// qdiscError 内核没有对应的 qdisc 模块时返回 ENOENT
+func qdiscError(err error) error {
+	if errors.Is(err, unix.ENOENT) {
+		return errors.New("qdisc not available in the kernel")
+	}
+	return err
+}
+
+func helperName(fn asm.BuiltinFunc) string {
+	// FnSkbEcnSetCe -> bpf_skb_ecn_set_ce
+	name := strings.TrimPrefix(fn.String(), "Fn")
+	var b strings.Builder
+	b.WriteString("bpf")
+	for _, c := range name {
+		if c >= 'A' && c <= 'Z' {
+			b.WriteByte('_')
+			c += 'a' - 'A'
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+
+	"netsimlation/distribute/ebpf/pkg/tc"
+
+	"github.com/cilium/ebpf"
+	"github.com/vishvananda/netlink"
+)
+
+// upgrade 加载新编译的程序对象并原子替换各接口上正在运行的程序，不修改 qdisc，也不卸载程序：
+// 固定映射（MAC_HANDLE_BPS_DELAY、progs）按名称复用，未固定的映射（flow_map）从正在运行的程序中取出复用，
+// 链路参数与各流的发送时间戳都得以保留。映射布局不兼容时在修改任何接口之前退出
+func upgrade(ifaces []netlink.Link) error {
+	spec, err := loadEdt()
+	if err != nil {
+		return err
+	}
+	if err := checkPinnedMaps(spec); err != nil {
+		return err
+	}
+
+	// 每次加载都会产生一份独立的程序与 flow_map，按正在运行的程序分组，组内共享同一份流状态
+	groups := make(map[uint32][]netlink.Link)
+	for _, iface := range ifaces {
+		id, _, err := tc.AttachedProgram(iface, attachOptions(iface, 0))
+		if errors.Is(err, tc.ErrNotAttached) {
+			return fmt.Errorf("%s: program not attached, attach it without -upgrade first", iface.Attrs().Name)
+		}
+		if err != nil {
+			return err
+		}
+		groups[id] = append(groups[id], iface)
+	}
+	ids := make([]uint32, 0, len(groups))
+	for id := range groups {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	// 先为所有分组取出并校验要复用的映射，任何一组不兼容都不会替换程序
+	replacements := make(map[uint32]map[string]*ebpf.Map, len(ids))
+	defer func() {
+		for _, maps := range replacements {
+			for _, m := range maps {
+				m.Close()
+			}
+		}
+	}()
+	for _, id := range ids {
+		maps, err := programMaps(ebpf.ProgramID(id), spec)
+		if err != nil {
+			return fmt.Errorf("running program %d: %w", id, err)
+		}
+		replacements[id] = maps
+	}
+
+	// 再加载所有分组的新程序，全部通过验证器后才开始修改内核中的状态
+	loaded := make([]*edtObjects, 0, len(ids))
+	defer func() {
+		// 程序由过滤器或 TCX 链接持有，关闭本进程的引用不影响运行
+		for _, objs := range loaded {
+			objs.Close()
+		}
+	}()
+	for _, id := range ids {
+		objs := &edtObjects{}
+		opts := ebpf.CollectionOptions{
+			Maps:            ebpf.MapOptions{PinPath: pin_path},
+			MapReplacements: replacements[id],
+		}
+		if err := loadEdtObjects(objs, &opts); err != nil {
+			return fmt.Errorf("loading objects for running program %d: %w", id, err)
+		}
+		loaded = append(loaded, objs)
+	}
+
+	// 持有旧程序的引用，替换失败时据此回滚；否则旧程序在被替换下来后即由内核释放
+	old := &upgradeRollback{progs: loaded[0].Progs, tcMain: make(map[uint32]*ebpf.Program, len(ids))}
+	defer old.Close()
+	for _, id := range ids {
+		prog, err := ebpf.NewProgramFromID(ebpf.ProgramID(id))
+		if err != nil {
+			return fmt.Errorf("open running program %d: %w", id, err)
+		}
+		old.tcMain[id] = prog
+	}
+	var setDelayID uint32
+	switch err := loaded[0].Progs.Lookup(uint32(0), &setDelayID); {
+	case err == nil:
+		if old.setDelay, err = ebpf.NewProgramFromID(ebpf.ProgramID(setDelayID)); err != nil {
+			return fmt.Errorf("open running set_delay %d: %w", setDelayID, err)
+		}
+	case !errors.Is(err, ebpf.ErrKeyNotExist):
+		return fmt.Errorf("lookup progs: %w", err)
+	}
+
+	// 尾调用表是固定映射，所有分组共用，只更新一次；之后旧的 tc_main 也会跳转到新的 set_delay
+	if err := loaded[0].Progs.Update(uint32(0), uint32(loaded[0].SetDelay.FD()), ebpf.UpdateAny); err != nil {
+		return fmt.Errorf("update progs: %w", err)
+	}
+
+	for i, id := range ids {
+		objs := loaded[i]
+		newID := uint32(0)
+		if info, err := objs.TcMain.Info(); err == nil {
+			if pid, ok := info.ID(); ok {
+				newID = uint32(pid)
+			}
+		}
+		for _, iface := range groups[id] {
+			mode, err := tc.Replace(iface, attachOptions(iface, objs.TcMain.FD()))
+			if err != nil {
+				if rerr := old.restore(); rerr != nil {
+					return fmt.Errorf("%w; rollback failed, interfaces may run a mix of old and new programs: %v", err, rerr)
+				}
+				return fmt.Errorf("%w; rolled back to the running programs", err)
+			}
+			old.switched = append(old.switched, switchedIface{iface: iface, id: id})
+			log.Printf("已升级 %s (%s): prog id %d -> %d", iface.Attrs().Name, mode, id, newID)
+		}
+	}
+	return nil
+}
+
+// switchedIface 已替换为新程序的接口及其原来的程序
+type switchedIface struct {
+	iface netlink.Link
+	id    uint32
+}
+
+// upgradeRollback 升级前正在运行的程序，用于替换中途失败时恢复
+type upgradeRollback struct {
+	progs    *ebpf.Map
+	setDelay *ebpf.Program // 为 nil 表示升级前尾调用表为空
+	tcMain   map[uint32]*ebpf.Program
+	switched []switchedIface
+}
+
+// restore 恢复尾调用表并把已替换的接口换回原来的程序，返回第一个失败的错误
+func (r *upgradeRollback) restore() error {
+	var first error
+	fail := func(err error) {
+		log.Printf("回滚失败: %v", err)
+		if first == nil {
+			first = err
+		}
+	}
+	if r.setDelay != nil {
+		if err := r.progs.Update(uint32(0), uint32(r.setDelay.FD()), ebpf.UpdateAny); err != nil {
+			fail(fmt.Errorf("restore progs: %w", err))
+		}
+	} else if err := r.progs.Delete(uint32(0)); err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+		fail(fmt.Errorf("restore progs: %w", err))
+	}
+	for _, s := range r.switched {
+		if _, err := tc.Replace(s.iface, attachOptions(s.iface, r.tcMain[s.id].FD())); err != nil {
+			fail(err)
+			continue
+		}
+		log.Printf("已回滚 %s 到 prog id %d", s.iface.Attrs().Name, s.id)
+	}
+	return first
+}
+
+func (r *upgradeRollback) Close() {
+	if r.setDelay != nil {
+		r.setDelay.Close()
+	}
+	for _, prog := range r.tcMain {
+		prog.Close()
+	}
+}
+
+// checkPinnedMaps 检查新对象中的固定映射与已固定映射的布局是否一致
+func checkPinnedMaps(spec *ebpf.CollectionSpec) error {
+	for name, ms := range spec.Maps {
+		if ms.Pinning != ebpf.PinByName {
+			continue
+		}
+		path := filepath.Join(pin_path, name)
+		m, err := ebpf.LoadPinnedMap(path, &ebpf.LoadPinOptions{ReadOnly: true})
+		if err != nil {
+			return fmt.Errorf("load pinned map %s: %w", path, err)
+		}
+		err = mapCompatible(ms, m)
+		m.Close()
+		if err != nil {
+			return fmt.Errorf("pinned map %s: %w; export the entries with map-populator, run -clear and attach again", name, err)
+		}
+	}
+	return nil
+}
+
+// programMaps 从正在运行的程序中取出新对象里未固定的映射（按内核中截断后的名称匹配），并检查布局
+func programMaps(id ebpf.ProgramID, spec *ebpf.CollectionSpec) (map[string]*ebpf.Map, error) {
+	prog, err := ebpf.NewProgramFromID(id)
+	if err != nil {
+		return nil, err
+	}
+	defer prog.Close()
+	info, err := prog.Info()
+	if err != nil {
+		return nil, err
+	}
+	mapIDs, ok := info.MapIDs()
+	if !ok {
+		return nil, fmt.Errorf("kernel does not report the maps of a program")
+	}
+
+	maps := make(map[string]*ebpf.Map)
+	for _, mid := range mapIDs {
+		m, err := ebpf.NewMapFromID(mid)
+		if err != nil {
+			return nil, err
+		}
+		mi, err := m.Info()
+		if err != nil {
+			m.Close()
+			return nil, err
+		}
+		name := specName(spec, mi.Name)
+		if name == "" || spec.Maps[name].Pinning == ebpf.PinByName {
+			m.Close()
+			continue
+		}
+		if err := mapCompatible(spec.Maps[name], m); err != nil {
+			m.Close()
+			for _, m := range maps {
+				m.Close()
+			}
+			return nil, fmt.Errorf("map %s: %w", name, err)
+		}
+		maps[name] = m
+	}
+	return maps, nil
+}
+
+// specName 内核只保留映射名的前 15 个字符
+func specName(spec *ebpf.CollectionSpec, kernelName string) string {
+	if kernelName == "" {
+		return ""
+	}
+	for name := range spec.Maps {
+		n := name
+		if len(n) > 15 {
+			n = n[:15]
+		}
+		if n == kernelName {
+			return name
+		}
+	}
+	return ""
+}
+
+// mapCompatible 映射的类型、键值大小、最大条目数与标志必须与新对象一致，新程序才能直接使用其中的数据
+func mapCompatible(ms *ebpf.MapSpec, m *ebpf.Map) error {
+	switch {
+	case ms.Type != m.Type():
+		return fmt.Errorf("type changed from %v to %v", m.Type(), ms.Type)
+	case ms.KeySize != m.KeySize():
+		return fmt.Errorf("key size changed from %d to %d", m.KeySize(), ms.KeySize)
+	case ms.ValueSize != m.ValueSize():
+		return fmt.Errorf("value size changed from %d to %d", m.ValueSize(), ms.ValueSize)
+	case ms.MaxEntries != m.MaxEntries():
+		return fmt.Errorf("max entries changed from %d to %d", m.MaxEntries(), ms.MaxEntries)
+	case ms.Flags != m.Flags():
+		return fmt.Errorf("flags changed from %#x to %#x", m.Flags(), ms.Flags)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"netsimlation/distribute/ebpf/internal/linkmap"
+	"netsimlation/distribute/ebpf/pkg/instance"
+	"netsimlation/distribute/ebpf/pkg/tc"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// watchLinks 监听网卡的创建与删除，直到收到 SIGINT/SIGTERM。
+// 新出现的匹配接口自动挂载；接口删除时内核会一并移除其 qdisc 和过滤器，
+// 这里只需清理 qdisc 快照和映射中该网卡索引的条目，避免索引被新接口复用后继承旧的链路参数；
+// 同一实例在其他命名空间中有相同索引的接口时条目无法区分归属，不做清理。
+func watchLinks(patterns tc.IfacePatterns, attached []netlink.Link, objs *edtObjects) error {
+	updates := make(chan netlink.LinkUpdate, 64)
+	done := make(chan struct{})
+	defer close(done)
+	if err := netlink.LinkSubscribe(updates, done); err != nil {
+		return err
+	}
+
+	// 网卡索引 -> 名称
+	known := make(map[int32]string)
+	for _, iface := range attached {
+		known[int32(iface.Attrs().Index)] = iface.Attrs().Name
+	}
+
+	// 订阅建立之前创建的接口不会产生事件，这里补挂一次
+	if links, err := patterns.Links(); err == nil {
+		for _, iface := range links {
+			if _, ok := known[int32(iface.Attrs().Index)]; !ok {
+				if err := attach(iface, objs); err != nil {
+					log.Printf("挂载 %s 失败: %v", iface.Attrs().Name, err)
+					continue
+				}
+				known[int32(iface.Attrs().Index)] = iface.Attrs().Name
+			}
+		}
+	}
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	log.Printf("正在监听匹配 %v 的网卡事件，按 Ctrl+C 退出（已挂载的程序保持生效）", []string(patterns))
+
+	for {
+		select {
+		case <-sig:
+			log.Printf("停止监听网卡事件")
+			return nil
+		case u, ok := <-updates:
+			if !ok {
+				return fmt.Errorf("netlink subscription closed")
+			}
+			index := u.Index
+			name := u.Link.Attrs().Name
+
+			switch u.Header.Type {
+			case unix.RTM_NEWLINK:
+				// 状态变化同样产生 RTM_NEWLINK，只处理尚未挂载的接口
+				if _, ok := known[index]; ok || !patterns.Match(name) {
+					continue
+				}
+				log.Printf("检测到新接口 %s (ifindex %d)", name, index)
+				if err := attach(u.Link, objs); err != nil {
+					// 接口可能在挂载过程中被删除，记录后继续监听
+					log.Printf("挂载 %s 失败: %v", name, err)
+					continue
+				}
+				known[index] = name
+
+			case unix.RTM_DELLINK:
+				if _, ok := known[index]; !ok {
+					continue
+				}
+				delete(known, index)
+				if err := tc.RemoveQdiscSnapshot(state_dir, name); err != nil {
+					log.Printf("Warning: 无法删除 %s 的 qdisc 快照: %v", name, err)
+				}
+				// 接口删除后 TCX 链接已失效，只剩固定路径
+				if err := os.Remove(filepath.Join(link_dir, name)); err != nil && !os.IsNotExist(err) {
+					log.Printf("Warning: 无法删除 %s 的 TCX 链接: %v", name, err)
+				}
+				// 映射键不含命名空间，实例在其他命名空间中挂载了同一索引的接口时无法区分条目归属，保留不删
+				shared, err := inst.SharedIfindex(netns_id, int(index))
+				if err != nil {
+					log.Printf("查询实例 %s 的挂载失败，保留接口 %s (ifindex %d) 的映射条目: %v", inst.Name, name, index, err)
+					continue
+				}
+				if len(shared) > 0 {
+					log.Printf("接口 %s (ifindex %d) 已删除；%s 使用相同的网卡索引，保留映射条目", name, index, attachmentList(shared))
+					continue
+				}
+				n, err := purgeIfindex(objs, uint32(index))
+				if err != nil {
+					log.Printf("清理接口 %s (ifindex %d) 的映射条目失败: %v", name, index, err)
+					continue
+				}
+				log.Printf("接口 %s (ifindex %d) 已删除，清理了 %d 条链路条目", name, index, n)
+			}
+		}
+	}
+}
+
+// purgeIfindex 删除链路映射与流时间戳映射中属于 ifindex 的条目，返回删除的链路条目数
+func purgeIfindex(objs *edtObjects, ifindex uint32) (int, error) {
+	entries, err := linkmap.Dump(objs.MAC_HANDLE_BPS_DELAY)
+	if err != nil {
+		return 0, err
+	}
+	var stale []linkmap.FlowKey
+	for key := range entries {
+		if key.Ifindex == ifindex {
+			stale = append(stale, key)
+		}
+	}
+	if err := linkmap.BatchDelete(objs.MAC_HANDLE_BPS_DELAY, stale); err != nil {
+		return 0, err
+	}
+
+	var flows []linkmap.FlowKey
+	var key linkmap.FlowKey
+	var tstamp uint64
+	iter := objs.FlowMap.Iterate()
+	for iter.Next(&key, &tstamp) {
+		if key.Ifindex == ifindex {
+			flows = append(flows, key)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return len(stale), err
+	}
+	return len(stale), linkmap.BatchDelete(objs.FlowMap, flows)
+}
+
+// attachmentList 以逗号连接挂载列表，用于日志与状态输出
+func attachmentList(attachments []instance.Attachment) string {
+	names := make([]string, len(attachments))
+	for i, a := range attachments {
+		names[i] = a.String()
+	}
+	return strings.Join(names, ", ")
+}
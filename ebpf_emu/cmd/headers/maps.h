@@ -6,10 +6,15 @@ struct flow_key {
     unsigned char src_mac[ETH_ALEN];  // 源MAC地址
 } __attribute__((packed)); // 确保结构体按照实际大小对齐
 
+// 链路管理状态：默认0为开启，关闭时 tc_main 丢弃该链路的全部流量
+#define LINK_ADMIN_UP   0
+#define LINK_ADMIN_DOWN 1
+
 struct handle_bps_delay {
     __u32 tc_handle;
     __u32 throttle_rate_bps;
     __u32 delay_ms;
+    __u32 admin_state;
 } HANDLE_BPS_DELAY;
 
 // 修改映射键类型为复合键（网卡index + MAC地址）
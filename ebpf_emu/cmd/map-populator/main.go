@@ -1,28 +1,22 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"math"
 	"net"
 	"os"
+	"sort"
+
+	"netsimlation/distribute/ebpf/internal/linkmap"
+	"netsimlation/distribute/ebpf/pkg/instance"
+	"netsimlation/distribute/ebpf/pkg/netns"
 
 	"github.com/cilium/ebpf"
 	"github.com/vishvananda/netlink"
 )
 
-// 复合键结构体：对应C代码中的flow_key
-type flowKey struct {
-	Ifindex  uint32  // 网卡接口索引
-	SrcMac   [6]byte // 源MAC地址
-}
-
-// HANDLE_BPS map value struct
-type handleBpsDelay struct {
-	TcHandle        uint32
-	ThrottleRateBps uint32
-	DelayMs         uint32
-}
-
 // parseMacToBytes parses MAC address from string into byte array
 func parseMacToBytes(mac string) ([]byte, error) {
 	macAddr, err := net.ParseMAC(mac)
@@ -45,33 +39,85 @@ func parseBytesToMac(macBytes []byte) string {
 		macBytes[0], macBytes[1], macBytes[2], macBytes[3], macBytes[4], macBytes[5])
 }
 
-// printMap iterates through the eBPF map and prints all entries
-func printMap(ebpfMap *ebpf.Map) {
-	var count int
+// entryFilter 按网卡和MAC地址过滤条目，零值表示不过滤
+type entryFilter struct {
+	ifindex uint32
+	mac     string
+}
 
-	// Create an iterator for the map
-	iter := ebpfMap.Iterate()
-	var key flowKey // 使用复合键
-	var value handleBpsDelay
+// newEntryFilter 解析 -iface/-mac 过滤条件
+func newEntryFilter(ifname string, mac string) (entryFilter, error) {
+	var f entryFilter
+	if ifname != "" {
+		ifindex, err := getInterfaceIndex(ifname)
+		if err != nil {
+			return f, err
+		}
+		f.ifindex = ifindex
+	}
+	if mac != "" {
+		hw, err := net.ParseMAC(mac)
+		if err != nil {
+			return f, fmt.Errorf("invalid MAC address %s: %v", mac, err)
+		}
+		f.mac = hw.String()
+	}
+	return f, nil
+}
 
-	// Print table header
-	fmt.Println("\nInterface Index\tMAC Address\t\tTC Handle\tBandwidth (Mbps)\tDelay (ms)")
-	fmt.Println("----------------------------------------------------------------------------")
+func (f entryFilter) match(key linkmap.FlowKey) bool {
+	return (f.ifindex == 0 || key.Ifindex == f.ifindex) && (f.mac == "" || key.MAC() == f.mac)
+}
 
-	// Iterate through all entries
-	for iter.Next(&key, &value) {
-		count++
-		mac := parseBytesToMac(key.SrcMac[:])
-		bandwidthMbps := float64(value.ThrottleRateBps) / 1000000.0
-		fmt.Printf("%d\t\t%s\t\t0x%x\t\t%.2f\t\t%d\n", key.Ifindex, mac, value.TcHandle, bandwidthMbps, value.DelayMs)
+// collectEntries 读取映射中符合过滤条件的条目，按网卡索引和MAC排序，保证多次输出的结果可比较
+func collectEntries(ebpfMap *ebpf.Map, filter entryFilter) ([]linkmap.Entry, error) {
+	snapshot, err := linkmap.Dump(ebpfMap)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]linkmap.FlowKey, 0, len(snapshot))
+	for key := range snapshot {
+		if filter.match(key) {
+			keys = append(keys, key)
+		}
 	}
+	sort.Slice(keys, func(i, j int) bool { return linkmap.LessKey(keys[i], keys[j]) })
 
-	// Check for iteration errors
-	if err := iter.Err(); err != nil {
-		fmt.Printf("Error iterating map: %v\n", err)
+	entries := make([]linkmap.Entry, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, linkmap.NewEntry(key, snapshot[key]))
+	}
+	return entries, nil
+}
+
+// bytesPerMbps 1 Mbit/s 对应的字节每秒数，映射中的 throttle_rate_bps 以字节每秒为单位
+const bytesPerMbps = 1000000 / 8
+
+// throttleRate 将 -bandwidth 的 Mbps 转换为映射中的字节每秒，超出 uint32 时报错
+func throttleRate(bandwidthMbps uint) (uint32, error) {
+	if uint64(bandwidthMbps) > math.MaxUint32/bytesPerMbps {
+		return 0, fmt.Errorf("bandwidth %d Mbps exceeds the datapath limit of %d Mbps", bandwidthMbps, math.MaxUint32/bytesPerMbps)
+	}
+	return uint32(bandwidthMbps * bytesPerMbps), nil
+}
+
+// mbps 将映射中的字节每秒换算为 Mbps 用于显示
+func mbps(throttleRateBps uint32) float64 {
+	return float64(throttleRateBps) / bytesPerMbps
+}
+
+// printMap prints the entries as a human readable table
+func printMap(entries []linkmap.Entry) {
+	// Print table header
+	fmt.Println("\nInterface Index\tMAC Address\t\tTC Handle\tBandwidth (Mbps)\tDelay (ms)\tState")
+	fmt.Println("----------------------------------------------------------------------------")
+
+	for _, e := range entries {
+		bandwidthMbps := mbps(e.BandwidthBps)
+		fmt.Printf("%d\t\t%s\t\t0x%x\t\t%.2f\t\t%d\t\t%s\n", e.Ifindex, e.MAC, e.TcHandle, bandwidthMbps, e.DelayMs, e.State)
 	}
 
-	fmt.Printf("\nTotal entries in map: %d\n", count)
+	fmt.Printf("\nTotal entries in map: %d\n", len(entries))
 }
 
 // clearMap removes all entries from the eBPF map
@@ -80,8 +126,8 @@ func clearMap(ebpfMap *ebpf.Map) error {
 
 	// Create an iterator for the map
 	iter := ebpfMap.Iterate()
-	var key flowKey // 使用复合键
-	var value handleBpsDelay
+	var key linkmap.FlowKey // 使用复合键
+	var value linkmap.HandleBpsDelay
 
 	// Iterate through all entries and delete them
 	for iter.Next(&key, &value) {
@@ -111,7 +157,7 @@ func getInterfaceIndex(ifname string) (uint32, error) {
 }
 
 // addMapEntry adds a single entry to the eBPF map
-func addMapEntry(ebpfMap *ebpf.Map, ifname string, mac string, tcHandle uint32, throttleRateBps uint32, delayMs uint32) error {
+func addMapEntry(ebpfMap *ebpf.Map, ifname string, mac string, tcHandle uint32, throttleRateBps uint32, delayMs uint32, adminState uint32) error {
 	// 获取网卡接口索引
 	ifindex, err := getInterfaceIndex(ifname)
 	if err != nil {
@@ -125,15 +171,16 @@ func addMapEntry(ebpfMap *ebpf.Map, ifname string, mac string, tcHandle uint32,
 	}
 	
 	// Create composite key
-	var key flowKey
+	var key linkmap.FlowKey
 	key.Ifindex = ifindex
 	copy(key.SrcMac[:], keyBytes)
 	
 	// Create value struct
-	value := handleBpsDelay{
+	value := linkmap.HandleBpsDelay{
 		TcHandle:        tcHandle,
 		ThrottleRateBps: throttleRateBps,
 		DelayMs:         delayMs,
+		AdminState:      adminState,
 	}
 	
 	// Update the map
@@ -141,13 +188,198 @@ func addMapEntry(ebpfMap *ebpf.Map, ifname string, mac string, tcHandle uint32,
 		return fmt.Errorf("error adding entry for ifindex %d, MAC %s: %v", ifindex, mac, err)
 	}
 	
-	fmt.Printf("Successfully added entry for ifindex %d, MAC %s (TC: 0x%x, Bandwidth: %.2f Mbps, Delay: %d ms)\n",
-		ifindex, mac, tcHandle, float64(throttleRateBps)/1000000.0, delayMs)
+	fmt.Printf("Successfully added entry for ifindex %d, MAC %s (TC: 0x%x, Bandwidth: %.2f Mbps, Delay: %d ms, State: %s)\n",
+		ifindex, mac, tcHandle, mbps(throttleRateBps), delayMs, linkmap.AdminStateString(adminState))
+	return nil
+}
+
+// lookupKey 根据网卡名和MAC地址构造复合键
+func lookupKey(ifname string, mac string) (linkmap.FlowKey, error) {
+	ifindex, err := getInterfaceIndex(ifname)
+	if err != nil {
+		return linkmap.FlowKey{}, err
+	}
+	return linkmap.NewFlowKey(ifindex, mac)
+}
+
+// deleteMapEntry 删除单个条目
+func deleteMapEntry(ebpfMap *ebpf.Map, ifname string, mac string) error {
+	key, err := lookupKey(ifname, mac)
+	if err != nil {
+		return err
+	}
+	if err := ebpfMap.Delete(key); err != nil {
+		if errors.Is(err, ebpf.ErrKeyNotExist) {
+			return fmt.Errorf("no entry for %s (ifindex %d), MAC %s", ifname, key.Ifindex, mac)
+		}
+		return fmt.Errorf("error deleting entry for ifindex %d, MAC %s: %v", key.Ifindex, mac, err)
+	}
+	fmt.Printf("Successfully deleted entry for ifindex %d, MAC %s\n", key.Ifindex, mac)
+	return nil
+}
+
+// entryUpdate 单个条目的字段修改，nil 表示保持原值，因此可以显式设置为0
+type entryUpdate struct {
+	TcHandle        *uint32
+	ThrottleRateBps *uint32
+	DelayMs         *uint32
+	AdminState      *uint32
+}
+
+// empty 是否没有任何字段需要修改
+func (u *entryUpdate) empty() bool {
+	return u.TcHandle == nil && u.ThrottleRateBps == nil && u.DelayMs == nil && u.AdminState == nil
+}
+
+// apply 将修改合并到条目值
+func (u *entryUpdate) apply(value *linkmap.HandleBpsDelay) {
+	if u.TcHandle != nil {
+		value.TcHandle = *u.TcHandle
+	}
+	if u.ThrottleRateBps != nil {
+		value.ThrottleRateBps = *u.ThrottleRateBps
+	}
+	if u.DelayMs != nil {
+		value.DelayMs = *u.DelayMs
+	}
+	if u.AdminState != nil {
+		value.AdminState = *u.AdminState
+	}
+}
+
+// updateMapEntry 修改已存在条目的部分字段，其余字段保持不变
+func updateMapEntry(ebpfMap *ebpf.Map, ifname string, mac string, update *entryUpdate) error {
+	key, err := lookupKey(ifname, mac)
+	if err != nil {
+		return err
+	}
+	var value linkmap.HandleBpsDelay
+	if err := ebpfMap.Lookup(key, &value); err != nil {
+		if errors.Is(err, ebpf.ErrKeyNotExist) {
+			return fmt.Errorf("no entry for %s (ifindex %d), MAC %s; use -mode add to create it", ifname, key.Ifindex, mac)
+		}
+		return fmt.Errorf("error reading entry for ifindex %d, MAC %s: %v", key.Ifindex, mac, err)
+	}
+	old := value
+	update.apply(&value)
+
+	// UpdateExist：条目在读取后被并发删除时不重新创建
+	if err := ebpfMap.Update(key, value, ebpf.UpdateExist); err != nil {
+		return fmt.Errorf("error updating entry for ifindex %d, MAC %s: %v", key.Ifindex, mac, err)
+	}
+	fmt.Printf("Successfully updated entry for ifindex %d, MAC %s\n    - %s\n    + %s\n",
+		key.Ifindex, mac, describeValue(old), describeValue(value))
+	return nil
+}
+
+// importEntries 从文件读取条目并批量写入映射
+func importEntries(ebpfMap *ebpf.Map, path string, format string) error {
+	entries, err := linkmap.LoadEntries(path, format)
+	if err != nil {
+		return err
+	}
+
+	desired, err := linkmap.Desired(entries)
+	if err != nil {
+		return err
+	}
+	keys := make([]linkmap.FlowKey, 0, len(desired))
+	values := make([]linkmap.HandleBpsDelay, 0, len(desired))
+	for key, value := range desired {
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+
+	if err := linkmap.BatchPut(ebpfMap, keys, values); err != nil {
+		return err
+	}
+	fmt.Printf("Successfully imported %d entries from %s\n", len(keys), path)
+	return nil
+}
+
+// exportEntries 将映射内容按格式输出到文件，path 为空时输出到标准输出
+func exportEntries(ebpfMap *ebpf.Map, path string, format string) error {
+	if format == "" && path == "" {
+		format = linkmap.FormatYAML
+	}
+	format, err := linkmap.DetectFormat(path, format)
+	if err != nil {
+		return err
+	}
+
+	entries, err := collectEntries(ebpfMap, entryFilter{})
+	if err != nil {
+		return err
+	}
+
+	if path == "" {
+		return linkmap.WriteEntries(os.Stdout, format, entries)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := linkmap.WriteEntries(f, format, entries); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Successfully exported %d entries to %s\n", len(entries), path)
 	return nil
 }
 
+// describeValue 返回条目值的可读描述
+func describeValue(v linkmap.HandleBpsDelay) string {
+	return fmt.Sprintf("TC: 0x%x, Bandwidth: %.2f Mbps, Delay: %d ms, State: %s",
+		v.TcHandle, mbps(v.ThrottleRateBps), v.DelayMs, linkmap.AdminStateString(v.AdminState))
+}
+
+// planEntries 读取期望状态文件并与映射当前内容比较
+func planEntries(ebpfMap *ebpf.Map, path string, format string) (*linkmap.Plan, error) {
+	entries, err := linkmap.LoadEntries(path, format)
+	if err != nil {
+		return nil, err
+	}
+	desired, err := linkmap.Desired(entries)
+	if err != nil {
+		return nil, err
+	}
+	current, err := linkmap.Dump(ebpfMap)
+	if err != nil {
+		return nil, err
+	}
+	return linkmap.Diff(current, desired), nil
+}
+
+// printPlan 打印变更：+ 新增，~ 修改，- 删除；未指定 prune 时多余条目仅列出而不删除
+func printPlan(plan *linkmap.Plan, prune bool) {
+	for _, c := range plan.Add {
+		fmt.Printf("+ ifindex %d, MAC %s (%s)\n", c.Key.Ifindex, c.Key.MAC(), describeValue(c.New))
+	}
+	for _, c := range plan.Modify {
+		fmt.Printf("~ ifindex %d, MAC %s\n    - %s\n    + %s\n", c.Key.Ifindex, c.Key.MAC(), describeValue(c.Old), describeValue(c.New))
+	}
+	for _, c := range plan.Remove {
+		mark := "-"
+		if !prune {
+			mark = "?"
+		}
+		fmt.Printf("%s ifindex %d, MAC %s (%s)\n", mark, c.Key.Ifindex, c.Key.MAC(), describeValue(c.Old))
+	}
+
+	fmt.Printf("\n%d to add, %d to modify", len(plan.Add), len(plan.Modify))
+	if prune {
+		fmt.Printf(", %d to remove\n", len(plan.Remove))
+	} else {
+		fmt.Printf(", %d unmanaged entries kept (use -prune to remove)\n", len(plan.Remove))
+	}
+}
+
 func main() {
-	// 操作模式：view（查看）、clear（清空）、add（添加）
+	// 操作模式：view（查看）、clear（清空）、add（添加）、import（导入）、export（导出）、
+	// diff（与期望状态比较）、apply（收敛到期望状态）、update（修改条目）、delete（删除条目）
 	var mode string
 	var unpinMap bool
 	
@@ -157,19 +389,64 @@ func main() {
 	var tcHandle uint
 	var bandwidthMbps uint
 	var delayMs uint
+	var state string
+
+	// 导入导出参数
+	var file string
+	var format string
+	var prune bool
+
+	// 查看参数
+	var output string
 
-	flag.StringVar(&mode, "mode", "view", "Operation mode: view (查看表), clear (清空表), add (添加表)")
+	// 网络命名空间：-iface 与导出时的网卡名称都在该命名空间中解析
+	var netnsSpec string
+
+	// 实例：操作哪个 ebpf-network-emulation 实例的映射
+	var instanceName, pinPath string
+
+	flag.StringVar(&mode, "mode", "view", "Operation mode: view (查看表), clear (清空表), add (添加表), import (批量导入), export (导出表), diff (比较期望状态), apply (应用期望状态), update (修改条目), delete (删除条目)")
 	flag.BoolVar(&unpinMap, "unpin-map", false, "Unpins the map and exits")
-	flag.StringVar(&ifname, "iface", "", "Network interface name (required for add mode)")
-	flag.StringVar(&mac, "mac", "", "MAC address to add (required for add mode)")
+	flag.StringVar(&ifname, "iface", "", "Network interface name (required for add/update/delete mode, filters view)")
+	flag.StringVar(&mac, "mac", "", "MAC address of the entry (required for add/update/delete mode, filters view)")
 	flag.UintVar(&tcHandle, "tc-handle", 0, "TC handle value (required for add mode)")
-	flag.UintVar(&bandwidthMbps, "bandwidth", 0, "Bandwidth in Mbps (required for add mode)")
-	flag.UintVar(&delayMs, "delay", 0, "Delay in ms (required for add mode)")
+	flag.UintVar(&bandwidthMbps, "bandwidth", 0, "Bandwidth in Mbps (required for add mode, 0 disables throttling)")
+	flag.UintVar(&delayMs, "delay", 0, "Delay in ms (required for add mode, 0 adds no delay)")
+	flag.StringVar(&state, "state", "up", "Link admin state: up, or down to drop all matching traffic")
+	flag.StringVar(&file, "file", "", "Entry file for import/export/diff/apply mode (export writes to stdout when empty)")
+	flag.StringVar(&format, "format", "", "Entry file format: json, yaml, csv (inferred from the file extension by default)")
+	flag.StringVar(&output, "output", "table", "view: output format: table, json, yaml, csv (bandwidth in bps, delay in ms); -iface/-mac filter the entries")
+	flag.StringVar(&netnsSpec, "netns", "", "Resolve interfaces in this network namespace: ip netns name, namespace file path or container PID")
+	flag.StringVar(&instanceName, "instance", "", "ebpf-network-emulation instance whose map is used (default: the default instance)")
+	flag.StringVar(&pinPath, "pin-path", "", "bpffs directory of the pinned map, overrides the directory of -instance")
+	flag.BoolVar(&prune, "prune", false, "diff/apply: remove map entries that are not in the file")
 
 	flag.Parse()
 
+	// 记录显式指定的参数，区分"未指定"与"指定为0"
+	setFlags := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+
+	// 容器内接口的网卡索引属于其网络命名空间，映射条目必须使用该索引才能被数据面匹配
+	if netnsSpec != "" {
+		ns, err := netns.Open(netnsSpec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+			os.Exit(1)
+		}
+		if _, err := ns.Enter(); err != nil {
+			fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	// Path to the map file of the eBPF program
-	ebpfMapFile := "/sys/fs/bpf/MAC_HANDLE_BPS_DELAY"
+	inst, err := instance.Lookup(instanceName, pinPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+		os.Exit(1)
+	}
+	ebpfMapFile := inst.MapPath()
 
 	// Load map
 	ipHandleMap, err := ebpf.LoadPinnedMap(ebpfMapFile, &ebpf.LoadPinOptions{})
@@ -191,15 +468,35 @@ func main() {
 		os.Exit(0)
 	}
 
-	// Print map info
-	fmt.Printf("加载的映射: %+v\n", ipHandleMap)
-	fmt.Printf("映射类型: %s\n", ipHandleMap.Type())
-	fmt.Printf("最大条目数: %d\n", ipHandleMap.MaxEntries())
+	// Print map info，导出或以机器可读格式输出到标准输出时不打印，避免混入输出内容
+	machineOutput := (mode == "export" && file == "") || (mode == "view" && output != "table")
+	if !machineOutput {
+		fmt.Printf("加载的映射: %+v\n", ipHandleMap)
+		fmt.Printf("映射类型: %s\n", ipHandleMap.Type())
+		fmt.Printf("最大条目数: %d\n", ipHandleMap.MaxEntries())
+	}
 
 	// 根据模式执行相应操作
 	switch mode {
 	case "view":
-		printMap(ipHandleMap)
+		filter, err := newEntryFilter(ifname, mac)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "错误: %v\n", err)
+			os.Exit(1)
+		}
+		entries, err := collectEntries(ipHandleMap, filter)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "错误: 读取映射失败: %v\n", err)
+			os.Exit(1)
+		}
+		if output == "table" {
+			printMap(entries)
+			break
+		}
+		if err := linkmap.WriteEntries(os.Stdout, output, entries); err != nil {
+			fmt.Fprintf(os.Stderr, "错误: 输出失败: %v\n", err)
+			os.Exit(1)
+		}
 		
 	case "clear":
 		if err := clearMap(ipHandleMap); err != nil {
@@ -209,26 +506,144 @@ func main() {
 		
 	case "add":
 		// 验证添加模式所需的参数
-		if ifname == "" || mac == "" || tcHandle == 0 || bandwidthMbps == 0 || delayMs == 0 {
+		if ifname == "" || mac == "" || tcHandle == 0 || !setFlags["bandwidth"] || !setFlags["delay"] {
 			fmt.Println("错误: 添加模式需要提供以下参数: -iface, -mac, -tc-handle, -bandwidth, -delay")
 			fmt.Println("用法示例: sudo go run main.go -mode add -iface eth0 -mac 00:11:22:33:44:55 -tc-handle 100 -bandwidth 10 -delay 50")
 			os.Exit(1)
 		}
 		
-		// 转换带宽从Mbps到Bps
-		throttleRateBps := bandwidthMbps * 1000000
+		adminState, err := linkmap.ParseAdminState(state)
+		if err != nil {
+			fmt.Printf("错误: %v\n", err)
+			os.Exit(1)
+		}
+
+		// 转换带宽从Mbps到字节每秒
+		throttleRateBps, err := throttleRate(bandwidthMbps)
+		if err != nil {
+			fmt.Printf("错误: %v\n", err)
+			os.Exit(1)
+		}
 		
-		if err := addMapEntry(ipHandleMap, ifname, mac, uint32(tcHandle), uint32(throttleRateBps), uint32(delayMs)); err != nil {
+		if err := addMapEntry(ipHandleMap, ifname, mac, uint32(tcHandle), throttleRateBps, uint32(delayMs), adminState); err != nil {
 			fmt.Printf("错误: 添加表条目失败: %v\n", err)
 			os.Exit(1)
 		}
+
+	case "import":
+		if file == "" {
+			fmt.Println("错误: 导入模式需要提供 -file 参数")
+			fmt.Println("用法示例: sudo go run main.go -mode import -file links.yaml")
+			os.Exit(1)
+		}
+		if err := importEntries(ipHandleMap, file, format); err != nil {
+			fmt.Printf("错误: 导入表条目失败: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "export":
+		if err := exportEntries(ipHandleMap, file, format); err != nil {
+			fmt.Fprintf(os.Stderr, "错误: 导出表失败: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "diff", "apply":
+		if file == "" {
+			fmt.Printf("错误: %s 模式需要提供 -file 参数\n", mode)
+			fmt.Printf("用法示例: sudo go run main.go -mode %s -file desired.yaml [-prune]\n", mode)
+			os.Exit(1)
+		}
+		plan, err := planEntries(ipHandleMap, file, format)
+		if err != nil {
+			fmt.Printf("错误: 比较期望状态失败: %v\n", err)
+			os.Exit(1)
+		}
+		printPlan(plan, prune)
+
+		if mode == "diff" {
+			// 与 diff(1) 一致：存在差异时退出码为2，便于在CI中检查配置漂移
+			if !plan.Empty(prune) {
+				os.Exit(2)
+			}
+			break
+		}
+		if plan.Empty(prune) {
+			fmt.Println("映射已与期望状态一致")
+			break
+		}
+		if err := linkmap.Converge(ipHandleMap, plan, prune); err != nil {
+			fmt.Printf("错误: 应用期望状态失败: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Successfully applied desired state")
+
+	case "delete":
+		if ifname == "" || mac == "" {
+			fmt.Println("错误: 删除模式需要提供以下参数: -iface, -mac")
+			fmt.Println("用法示例: sudo go run main.go -mode delete -iface eth0 -mac 00:11:22:33:44:55")
+			os.Exit(1)
+		}
+		if err := deleteMapEntry(ipHandleMap, ifname, mac); err != nil {
+			fmt.Printf("错误: 删除表条目失败: %v\n", err)
+			os.Exit(1)
+		}
+
+	case "update":
+		if ifname == "" || mac == "" {
+			fmt.Println("错误: 修改模式需要提供以下参数: -iface, -mac，以及至少一个要修改的字段")
+			fmt.Println("用法示例: sudo go run main.go -mode update -iface eth0 -mac 00:11:22:33:44:55 -delay 0")
+			os.Exit(1)
+		}
+
+		// 只修改显式指定的字段
+		var update entryUpdate
+		if setFlags["tc-handle"] {
+			v := uint32(tcHandle)
+			update.TcHandle = &v
+		}
+		if setFlags["bandwidth"] {
+			v, err := throttleRate(bandwidthMbps)
+			if err != nil {
+				fmt.Printf("错误: %v\n", err)
+				os.Exit(1)
+			}
+			update.ThrottleRateBps = &v
+		}
+		if setFlags["delay"] {
+			v := uint32(delayMs)
+			update.DelayMs = &v
+		}
+		if setFlags["state"] {
+			v, err := linkmap.ParseAdminState(state)
+			if err != nil {
+				fmt.Printf("错误: %v\n", err)
+				os.Exit(1)
+			}
+			update.AdminState = &v
+		}
+		if update.empty() {
+			fmt.Println("错误: 修改模式需要至少指定 -tc-handle, -bandwidth, -delay, -state 中的一个")
+			os.Exit(1)
+		}
+
+		if err := updateMapEntry(ipHandleMap, ifname, mac, &update); err != nil {
+			fmt.Printf("错误: 修改表条目失败: %v\n", err)
+			os.Exit(1)
+		}
 		
 	default:
-		fmt.Println("错误: 无效的操作模式。可用模式: view, clear, add")
+		fmt.Println("错误: 无效的操作模式。可用模式: view, clear, add, update, delete, import, export, diff, apply")
 		fmt.Println("用法示例:")
 		fmt.Println("  查看表: sudo go run main.go -mode view")
+		fmt.Println("  过滤并输出JSON: sudo go run main.go -mode view -iface eth0 -output json")
 		fmt.Println("  清空表: sudo go run main.go -mode clear")
 		fmt.Println("  添加表: sudo go run main.go -mode add -iface eth0 -mac 00:11:22:33:44:55 -tc-handle 100 -bandwidth 10 -delay 50")
+		fmt.Println("  修改条目: sudo go run main.go -mode update -iface eth0 -mac 00:11:22:33:44:55 -delay 0")
+		fmt.Println("  删除条目: sudo go run main.go -mode delete -iface eth0 -mac 00:11:22:33:44:55")
+		fmt.Println("  批量导入: sudo go run main.go -mode import -file links.csv")
+		fmt.Println("  导出表: sudo go run main.go -mode export -file links.json")
+		fmt.Println("  比较期望状态: sudo go run main.go -mode diff -file desired.yaml")
+		fmt.Println("  应用期望状态: sudo go run main.go -mode apply -file desired.yaml -prune")
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"text/tabwriter"
+
+	"netsimlation/distribute/ebpf/pkg/instance"
+)
+
+// STATE_DIR 已构建测试床的状态，与 ebpf-network-emulation 的 qdisc 快照同在 /run 下，随重启清空
+const STATE_DIR = "/run/ebpf-network-emulation/testbed/"
+
+const usage = `Usage: testbed <command> [flags]
+
+Build a single-machine testbed of network namespaces connected by veth pairs,
+attach the EDT program in every namespace and populate MAC_HANDLE_BPS_DELAY.
+
+Commands:
+  up    -f topology.yaml   create namespaces and veths, attach and populate the map
+  down  -f topology.yaml | -name NAME
+                           remove everything created by up
+  show  -f topology.yaml | -name NAME
+                           print the interfaces, addresses and map entries of a testbed
+`
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	file := fs.String("f", "", "Topology file")
+	name := fs.String("name", "", "Testbed name (down/show), defaults to the name in -f")
+	inst := fs.String("instance", "", "ebpf-network-emulation instance to attach and populate, so the testbed does not share maps with other experiments")
+	pinPath := fs.String("pin-path", "", "Directory of the pinned MAC_HANDLE_BPS_DELAY map, defaults to the directory of -instance")
+	loader := fs.String("loader", "ebpf-network-emulation", "ebpf-network-emulation binary used to attach the program in each namespace; empty to skip attaching")
+
+	switch os.Args[1] {
+	case "up", "down", "show":
+	case "-h", "-help", "--help", "help":
+		fmt.Print(usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	fs.Parse(os.Args[2:])
+
+	var topo *Topology
+	var err error
+	if *file != "" {
+		if topo, err = LoadTopology(*file); err != nil {
+			log.Fatalf("错误: %v", err)
+		}
+		if *name == "" {
+			*name = topo.Name
+		}
+	}
+	if *loader != "" {
+		path, err := exec.LookPath(*loader)
+		if err != nil && os.Args[1] == "up" {
+			log.Fatalf("错误: 找不到 %s，请用 -loader 指定路径，或 -loader '' 跳过挂载: %v", *loader, err)
+		}
+		*loader = path
+	}
+	in, err := instance.Lookup(*inst, *pinPath)
+	if err != nil {
+		log.Fatalf("错误: %v", err)
+	}
+	opts := Options{Instance: *inst, PinPath: in.PinPath, Loader: *loader}
+
+	switch os.Args[1] {
+	case "up":
+		if topo == nil {
+			log.Fatalf("错误: up 需要 -f 参数")
+		}
+		st, err := Up(topo, *file, opts)
+		if err != nil {
+			log.Fatalf("构建测试床失败: %v", err)
+		}
+		log.Printf("测试床 %s 已就绪: %d 个节点, %d 条链路", st.Name, len(st.Namespaces), len(st.Links))
+		printState(st)
+
+	case "down":
+		if *name == "" {
+			log.Fatalf("错误: down 需要 -f 或 -name 参数")
+		}
+		st, err := LoadState(*name)
+		if errors.Is(err, os.ErrNotExist) {
+			if topo == nil {
+				log.Fatalf("错误: 没有测试床 %s 的状态文件，请用 -f 指定拓扑文件", *name)
+			}
+			log.Printf("没有测试床 %s 的状态文件，按拓扑文件清理", *name)
+			st, err = StateFromTopology(topo, opts)
+		}
+		if err != nil {
+			log.Fatalf("错误: %v", err)
+		}
+		if err := Down(st, *loader); err != nil {
+			log.Fatalf("删除测试床失败: %v", err)
+		}
+		log.Printf("测试床 %s 已删除 (%d 个命名空间, %d 个映射条目)", st.Name, len(st.Namespaces), len(st.Entries))
+
+	case "show":
+		if *name == "" {
+			log.Fatalf("错误: show 需要 -f 或 -name 参数")
+		}
+		st, err := LoadState(*name)
+		if err != nil {
+			log.Fatalf("错误: 读取测试床 %s 失败: %v", *name, err)
+		}
+		printState(st)
+	}
+}
+
+// printState 打印测试床中每条链路两端的网卡与参数
+func printState(st *State) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "NETNS\tIFACE\tIFINDEX\tMAC\tADDR\tPEER\tBANDWIDTH(Mbps)\tDELAY(ms)\tSTATE")
+	for _, l := range st.Links {
+		state := l.State
+		if state == "" {
+			state = "up"
+		}
+		for _, side := range [][2]Endpoint{{l.A, l.B}, {l.B, l.A}} {
+			ep, peer := side[0], side[1]
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s/%s\t%d\t%d\t%s\n",
+				ep.Netns, ep.Iface, ep.Ifindex, ep.MAC, ep.Addr, peer.Node, peer.Iface, l.BandwidthMbps, l.DelayMs, state)
+		}
+	}
+	w.Flush()
+}
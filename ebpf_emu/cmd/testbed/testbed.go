@@ -0,0 +1,343 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"netsimlation/distribute/ebpf/internal/linkmap"
+	"netsimlation/distribute/ebpf/pkg/netns"
+
+	"github.com/vishvananda/netlink"
+)
+
+// State 已构建的测试床，保存在 STATE_DIR 下，teardown 据此删除构建时创建的全部对象
+type State struct {
+	Name      string    `json:"name"`
+	Topology  string    `json:"topology"`
+	CreatedAt time.Time `json:"created_at"`
+	// Instance 挂载与写入映射所用的 ebpf-network-emulation 实例，空为默认实例
+	Instance   string   `json:"instance,omitempty"`
+	PinPath    string   `json:"pin_path"`
+	Namespaces []string `json:"namespaces"`
+	Links      []Link   `json:"links"`
+	// Attached 已成功挂载程序的命名空间
+	Attached []string `json:"attached,omitempty"`
+	// Entries 写入映射的条目，teardown 时只删除这些键
+	Entries []linkmap.FlowKey `json:"entries"`
+}
+
+func statePath(name string) string {
+	return filepath.Join(STATE_DIR, name+".json")
+}
+
+// LoadState 读取测试床状态，不存在时返回 os.ErrNotExist
+func LoadState(name string) (*State, error) {
+	data, err := os.ReadFile(statePath(name))
+	if err != nil {
+		return nil, err
+	}
+	var st State
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", statePath(name), err)
+	}
+	return &st, nil
+}
+
+func (st *State) save() error {
+	if err := os.MkdirAll(STATE_DIR, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := statePath(st.Name) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, statePath(st.Name))
+}
+
+// Options 构建参数
+type Options struct {
+	Instance string
+	PinPath  string
+	// Loader ebpf-network-emulation 可执行文件，为空时不挂载程序（例如之后以 -watch 方式单独运行）
+	Loader string
+}
+
+// Up 构建测试床：命名空间、veth、挂载程序并写入映射。任一步骤失败时删除已创建的对象
+func Up(topo *Topology, file string, opts Options) (*State, error) {
+	if _, err := LoadState(topo.Name); err == nil {
+		return nil, fmt.Errorf("testbed %s is already up, tear it down first", topo.Name)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	links, err := topo.Plan()
+	if err != nil {
+		return nil, err
+	}
+
+	st := &State{
+		Name:      topo.Name,
+		Topology:  file,
+		CreatedAt: time.Now(),
+		Instance:  opts.Instance,
+		PinPath:   opts.PinPath,
+	}
+	if err := build(topo, links, st, opts); err != nil {
+		if derr := Down(st, opts.Loader); derr != nil {
+			log.Printf("回滚测试床 %s 失败: %v", st.Name, derr)
+		}
+		return nil, err
+	}
+	if err := st.save(); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+func build(topo *Topology, links []Link, st *State, opts Options) error {
+	namespaces := make(map[string]*netns.Namespace, len(topo.Nodes))
+	defer func() {
+		for _, ns := range namespaces {
+			ns.Close()
+		}
+	}()
+
+	for _, node := range topo.Nodes {
+		name := topo.Netns(node)
+		ns, err := netns.Create(name)
+		if err != nil {
+			return err
+		}
+		namespaces[node] = ns
+		st.Namespaces = append(st.Namespaces, name)
+		if err := ns.Do(func() error {
+			lo, err := netlink.LinkByName("lo")
+			if err != nil {
+				return err
+			}
+			return netlink.LinkSetUp(lo)
+		}); err != nil {
+			return fmt.Errorf("bring up lo in %s: %w", name, err)
+		}
+	}
+	log.Printf("已创建 %d 个网络命名空间", len(namespaces))
+
+	for i := range links {
+		l := &links[i]
+		if err := createVeth(namespaces[l.A.Node], namespaces[l.B.Node], &l.A, &l.B); err != nil {
+			return fmt.Errorf("link %s/%s - %s/%s: %w", l.A.Node, l.A.Iface, l.B.Node, l.B.Iface, err)
+		}
+		st.Links = append(st.Links, *l)
+	}
+	log.Printf("已创建 %d 对 veth", len(links))
+
+	// 挂载失败时 ebpf-network-emulation 自身会回滚该命名空间，只需清理之前已挂载的
+	if opts.Loader != "" {
+		for _, name := range st.Namespaces {
+			if err := runLoader(opts.Loader, st.loaderArgs(name)...); err != nil {
+				return fmt.Errorf("attach in %s: %w", name, err)
+			}
+			st.Attached = append(st.Attached, name)
+		}
+	}
+
+	m, err := linkmap.OpenPinned(opts.PinPath)
+	if err != nil {
+		return fmt.Errorf("open pinned map (is ebpf-network-emulation loaded?): %w", err)
+	}
+	defer m.Close()
+
+	var keys []linkmap.FlowKey
+	var values []linkmap.HandleBpsDelay
+	for _, l := range links {
+		value, err := l.Value()
+		if err != nil {
+			return err
+		}
+		// 每一端以自己的网卡和 MAC 为键，整形该端发出的流量
+		for _, ep := range []Endpoint{l.A, l.B} {
+			key, err := linkmap.NewFlowKey(uint32(ep.Ifindex), ep.MAC)
+			if err != nil {
+				return err
+			}
+			keys = append(keys, key)
+			values = append(values, value)
+		}
+	}
+	st.Entries = keys
+	if err := linkmap.BatchPut(m, keys, values); err != nil {
+		return fmt.Errorf("populate %s: %w", linkmap.MapName, err)
+	}
+	log.Printf("已写入 %d 个映射条目", len(keys))
+	return nil
+}
+
+// createVeth 在 b 中创建 veth 对并将 a 端直接创建到 a 的命名空间中，随后配置 MAC、地址并启用两端
+func createVeth(nsA, nsB *netns.Namespace, a, b *Endpoint) error {
+	macA, err := net.ParseMAC(a.MAC)
+	if err != nil {
+		return err
+	}
+	macB, err := net.ParseMAC(b.MAC)
+	if err != nil {
+		return err
+	}
+	veth := &netlink.Veth{
+		LinkAttrs: netlink.LinkAttrs{
+			Name:         a.Iface,
+			HardwareAddr: macA,
+			Namespace:    netlink.NsFd(nsA.Fd()),
+		},
+		PeerName:         b.Iface,
+		PeerHardwareAddr: macB,
+	}
+	if err := nsB.Do(func() error { return netlink.LinkAdd(veth) }); err != nil {
+		return fmt.Errorf("create veth: %w", err)
+	}
+
+	for _, side := range []struct {
+		ns *netns.Namespace
+		ep *Endpoint
+	}{{nsA, a}, {nsB, b}} {
+		ep := side.ep
+		err := side.ns.Do(func() error {
+			link, err := netlink.LinkByName(ep.Iface)
+			if err != nil {
+				return err
+			}
+			addr, err := netlink.ParseAddr(ep.Addr)
+			if err != nil {
+				return err
+			}
+			if err := netlink.AddrAdd(link, addr); err != nil {
+				return fmt.Errorf("add address %s: %w", ep.Addr, err)
+			}
+			if err := netlink.LinkSetUp(link); err != nil {
+				return err
+			}
+			ep.Ifindex = link.Attrs().Index
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("%s in %s: %w", ep.Iface, ep.Netns, err)
+		}
+	}
+	return nil
+}
+
+// Down 删除测试床：映射条目、挂载前的 qdisc 快照与命名空间。
+// 尽量删除全部对象，遇到错误继续执行并返回第一个错误
+func Down(st *State, loader string) error {
+	var first error
+	keep := func(err error) {
+		if err != nil {
+			log.Printf("%v", err)
+			if first == nil {
+				first = err
+			}
+		}
+	}
+
+	if len(st.Entries) > 0 {
+		m, err := linkmap.OpenPinned(st.PinPath)
+		if errors.Is(err, os.ErrNotExist) {
+			log.Printf("映射 %s 未固定，跳过删除条目", linkmap.MapName)
+		} else if err != nil {
+			keep(fmt.Errorf("open pinned map: %w", err))
+		} else {
+			keep(linkmap.BatchDelete(m, st.Entries))
+			m.Close()
+		}
+	}
+
+	// 命名空间删除后其中的 qdisc 随网卡一起销毁，-clear 只为清理 ebpf-network-emulation 按命名空间保存的快照
+	if loader != "" {
+		for _, name := range st.Attached {
+			if err := runLoader(loader, st.loaderArgs(name, "-clear")...); err != nil {
+				log.Printf("清理 %s 中的挂载失败: %v", name, err)
+			}
+		}
+	}
+	for _, name := range st.Namespaces {
+		keep(netns.Remove(name))
+	}
+
+	if err := os.Remove(statePath(st.Name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		keep(err)
+	}
+	return first
+}
+
+// loaderArgs 在命名空间 netnsName 中以测试床的实例操作全部 eth* 接口的参数
+func (st *State) loaderArgs(netnsName string, extra ...string) []string {
+	args := []string{"-netns", netnsName, "-iface", "eth*", "-pin-path", st.PinPath}
+	if st.Instance != "" {
+		args = append(args, "-instance", st.Instance)
+	}
+	return append(args, extra...)
+}
+
+// runLoader 运行 ebpf-network-emulation，输出直接转发到终端
+func runLoader(path string, args ...string) error {
+	cmd := exec.Command(path, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// StateFromTopology 状态文件丢失时（例如构建中途进程被杀）按拓扑文件推算需要删除的对象：
+// 仍存在的命名空间，以及其中按计划命名的网卡对应的映射条目
+func StateFromTopology(topo *Topology, opts Options) (*State, error) {
+	links, err := topo.Plan()
+	if err != nil {
+		return nil, err
+	}
+	st := &State{Name: topo.Name, Instance: opts.Instance, PinPath: opts.PinPath}
+	namespaces := make(map[string]*netns.Namespace)
+	defer func() {
+		for _, ns := range namespaces {
+			ns.Close()
+		}
+	}()
+	for _, node := range topo.Nodes {
+		ns, err := netns.Open(topo.Netns(node))
+		if err != nil {
+			continue
+		}
+		namespaces[ns.Spec] = ns
+		st.Namespaces = append(st.Namespaces, ns.Spec)
+		st.Attached = append(st.Attached, ns.Spec)
+	}
+
+	for _, l := range links {
+		for _, ep := range []Endpoint{l.A, l.B} {
+			ns, ok := namespaces[ep.Netns]
+			if !ok {
+				continue
+			}
+			var link netlink.Link
+			if ns.Do(func() error {
+				link, err = netlink.LinkByName(ep.Iface)
+				return err
+			}) != nil {
+				continue
+			}
+			key, err := linkmap.NewFlowKey(uint32(link.Attrs().Index), ep.MAC)
+			if err != nil {
+				return nil, err
+			}
+			st.Entries = append(st.Entries, key)
+		}
+	}
+	return st, nil
+}
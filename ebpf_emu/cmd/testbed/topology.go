@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"netsimlation/distribute/ebpf/internal/linkmap"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DEFAULT_SUBNET 未指定 subnet 时为链路分配地址的网段
+const DEFAULT_SUBNET = "10.250.0.0/16"
+
+// Topology 测试床拓扑文件：
+//
+//	name: demo                 # 命名空间前缀，默认取文件名
+//	subnet: 10.250.0.0/16      # 每条链路依次分配一个 /30
+//	nodes: [a, b, c]
+//	links:
+//	  - {a: a, b: b, bandwidth_mbps: 100, delay_ms: 10}
+//	  - {a: b, b: c, bandwidth_mbps: 10, delay_ms: 50, state: down}
+//
+// 每个节点一个网络命名空间 <name>-<node>，每条链路一对 veth，两端在各自节点中依次命名为 eth0、eth1...，
+// 链路参数同时作用于两个方向
+type Topology struct {
+	Name   string         `yaml:"name"`
+	Subnet string         `yaml:"subnet"`
+	Nodes  []string       `yaml:"nodes"`
+	Links  []TopologyLink `yaml:"links"`
+}
+
+type TopologyLink struct {
+	A             string `yaml:"a"`
+	B             string `yaml:"b"`
+	BandwidthMbps uint32 `yaml:"bandwidth_mbps"`
+	DelayMs       uint32 `yaml:"delay_ms"`
+	State         string `yaml:"state"`
+}
+
+// Endpoint 链路一端在节点命名空间中的网卡
+type Endpoint struct {
+	Node    string `json:"node"`
+	Netns   string `json:"netns"`
+	Iface   string `json:"iface"`
+	Ifindex int    `json:"ifindex,omitempty"` // 创建后填入命名空间内的网卡索引
+	MAC     string `json:"mac"`
+	Addr    string `json:"addr"` // CIDR
+}
+
+// Link 展开后的链路，两端各对应一个 MAC_HANDLE_BPS_DELAY 条目
+type Link struct {
+	A             Endpoint `json:"a"`
+	B             Endpoint `json:"b"`
+	BandwidthMbps uint32   `json:"bandwidth_mbps"`
+	DelayMs       uint32   `json:"delay_ms"`
+	State         string   `json:"state,omitempty"`
+}
+
+// bytesPerMbps 1 Mbit/s 对应的字节每秒数，数据面的 throttle_rate_bps 以字节每秒为单位
+const bytesPerMbps = 1000000 / 8
+
+// Value 链路在映射中的参数
+func (l *Link) Value() (linkmap.HandleBpsDelay, error) {
+	state, err := linkmap.ParseAdminState(l.State)
+	if err != nil {
+		return linkmap.HandleBpsDelay{}, err
+	}
+	return linkmap.HandleBpsDelay{
+		ThrottleRateBps: uint32(uint64(l.BandwidthMbps) * bytesPerMbps),
+		DelayMs:         l.DelayMs,
+		AdminState:      state,
+	}, nil
+}
+
+// LoadTopology 读取并校验拓扑文件
+func LoadTopology(path string) (*Topology, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var t Topology
+	if err := yaml.UnmarshalStrict(data, &t); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if t.Name == "" {
+		t.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if t.Subnet == "" {
+		t.Subnet = DEFAULT_SUBNET
+	}
+	if err := t.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &t, nil
+}
+
+func (t *Topology) validate() error {
+	if strings.ContainsAny(t.Name, "/ ") {
+		return fmt.Errorf("invalid name %q", t.Name)
+	}
+	if len(t.Nodes) == 0 {
+		return fmt.Errorf("no nodes")
+	}
+	nodes := make(map[string]bool, len(t.Nodes))
+	for _, n := range t.Nodes {
+		if n == "" || strings.ContainsAny(n, "/ ") {
+			return fmt.Errorf("invalid node name %q", n)
+		}
+		if nodes[n] {
+			return fmt.Errorf("duplicate node %s", n)
+		}
+		nodes[n] = true
+	}
+	for i, l := range t.Links {
+		if !nodes[l.A] || !nodes[l.B] {
+			return fmt.Errorf("link #%d: unknown node %q or %q", i, l.A, l.B)
+		}
+		if l.A == l.B {
+			return fmt.Errorf("link #%d: both ends on node %s", i, l.A)
+		}
+		if uint64(l.BandwidthMbps)*bytesPerMbps > math.MaxUint32 {
+			return fmt.Errorf("link #%d: bandwidth %d Mbps exceeds the datapath limit of %d Mbps", i, l.BandwidthMbps, math.MaxUint32/bytesPerMbps)
+		}
+		if _, err := linkmap.ParseAdminState(l.State); err != nil {
+			return fmt.Errorf("link #%d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Netns 节点的网络命名空间名称
+func (t *Topology) Netns(node string) string {
+	return t.Name + "-" + node
+}
+
+// Plan 按文件中的顺序展开链路，确定性地分配网卡名、MAC 与地址：
+// 同一拓扑文件每次构建得到相同的结果，映射条目与抓包结果可以直接对照
+func (t *Topology) Plan() ([]Link, error) {
+	ip, subnet, err := net.ParseCIDR(t.Subnet)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subnet: %w", err)
+	}
+	ip = ip.To4()
+	if ip == nil {
+		return nil, fmt.Errorf("subnet %s is not IPv4", t.Subnet)
+	}
+	ones, bits := subnet.Mask.Size()
+	if available := (uint64(1) << uint(bits-ones)) / 4; uint64(len(t.Links)) > available {
+		return nil, fmt.Errorf("subnet %s has room for %d links, topology has %d", t.Subnet, available, len(t.Links))
+	}
+	base := binary.BigEndian.Uint32(subnet.IP.To4())
+
+	index := make(map[string]int, len(t.Nodes))
+	for i, n := range t.Nodes {
+		index[n] = i + 1
+	}
+	// 节点已分配的网卡数，用于命名 eth<k>
+	ifaces := make(map[string]int, len(t.Nodes))
+	tag := nameTag(t.Name)
+
+	endpoint := func(node string, addr uint32) Endpoint {
+		k := ifaces[node]
+		ifaces[node]++
+		n := index[node]
+		ep := Endpoint{
+			Node:  node,
+			Netns: t.Netns(node),
+			Iface: fmt.Sprintf("eth%d", k),
+			MAC:   net.HardwareAddr{0x02, tag, byte(n >> 8), byte(n), byte(k >> 8), byte(k)}.String(),
+		}
+		a := make(net.IP, 4)
+		binary.BigEndian.PutUint32(a, addr)
+		ep.Addr = (&net.IPNet{IP: a, Mask: net.CIDRMask(30, 32)}).String()
+		return ep
+	}
+
+	links := make([]Link, 0, len(t.Links))
+	for i, l := range t.Links {
+		network := base + uint32(i)*4
+		links = append(links, Link{
+			A:             endpoint(l.A, network+1),
+			B:             endpoint(l.B, network+2),
+			BandwidthMbps: l.BandwidthMbps,
+			DelayMs:       l.DelayMs,
+			State:         l.State,
+		})
+	}
+	return links, nil
+}
+
+// nameTag 由测试床名称得到 MAC 的第二个字节，使同一主机上的多个测试床不共用 MAC
+func nameTag(name string) byte {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return byte(h.Sum32())
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"netsimlation/distribute/ebpf/internal/linkmap"
+	"netsimlation/distribute/ebpf/internal/trace"
+	"netsimlation/distribute/ebpf/pkg/instance"
+	"netsimlation/distribute/ebpf/pkg/tc"
+
+	"github.com/cilium/ebpf"
+)
+
+func main() {
+	var ifname string
+	var mac string
+	var file string
+	var format string
+	var window time.Duration
+	var loop bool
+	var delayMs uint
+	var tcHandle uint
+	var instanceName, pinPath string
+
+	flag.StringVar(&ifname, "iface", "", "Network interface name of the replayed link")
+	flag.StringVar(&mac, "mac", "", "Source MAC address of the replayed link")
+	flag.StringVar(&file, "file", "", "Trace file (Mahimahi packet delivery trace or CSV time_ms,bandwidth_bps[,delay_ms] with bandwidth in bytes/s)")
+	flag.StringVar(&format, "format", "", "Trace format: mahimahi or csv (default: inferred from extension)")
+	flag.DurationVar(&window, "window", 10*time.Millisecond, "Averaging window for Mahimahi traces, i.e. the map update interval")
+	flag.BoolVar(&loop, "loop", false, "Repeat the trace until interrupted")
+	flag.UintVar(&delayMs, "delay", 0, "Delay in ms used when the trace does not specify one")
+	flag.UintVar(&tcHandle, "tc-handle", 0, "TC handle value stored with the entry")
+	flag.StringVar(&instanceName, "instance", "", "ebpf-network-emulation instance whose map is updated (default: the default instance)")
+	flag.StringVar(&pinPath, "pin-path", "", "bpffs directory of the pinned map, overrides the directory of -instance")
+	flag.Parse()
+
+	if ifname == "" || mac == "" || file == "" {
+		log.Fatalf("错误: 必须提供 -iface, -mac, -file 参数")
+	}
+
+	tr, err := trace.Load(file, format, window)
+	if err != nil {
+		log.Fatalf("加载轨迹失败: %v", err)
+	}
+	log.Printf("已加载轨迹 %s: %d 个变化点，周期 %v", file, len(tr.Points), tr.Duration)
+
+	iface, err := tc.GetIface(ifname)
+	if err != nil {
+		log.Fatalf("错误: 找不到指定的网卡接口 %s: %v", ifname, err)
+	}
+	key, err := linkmap.NewFlowKey(uint32(iface.Attrs().Index), mac)
+	if err != nil {
+		log.Fatalf("错误: %v", err)
+	}
+
+	inst, err := instance.Lookup(instanceName, pinPath)
+	if err != nil {
+		log.Fatalf("错误: %v", err)
+	}
+	m, err := linkmap.OpenPinned(inst.PinPath)
+	if err != nil {
+		log.Fatalf("错误: 加载映射文件失败: %v", err)
+	}
+	defer m.Close()
+
+	// 记录回放前的条目，退出时恢复
+	var original linkmap.HandleBpsDelay
+	hadOriginal := true
+	if err := m.Lookup(key, &original); err != nil {
+		if !errors.Is(err, ebpf.ErrKeyNotExist) {
+			log.Fatalf("错误: 查询原有条目失败: %v", err)
+		}
+		hadOriginal = false
+	}
+
+	base := linkmap.HandleBpsDelay{TcHandle: uint32(tcHandle), DelayMs: uint32(delayMs)}
+	if hadOriginal {
+		if tcHandle == 0 {
+			base.TcHandle = original.TcHandle
+		}
+		if delayMs == 0 {
+			base.DelayMs = original.DelayMs
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	replayer := trace.NewReplayer(m, key, tr, base)
+	runErr := replayer.Run(ctx, loop)
+
+	if hadOriginal {
+		err = m.Put(key, original)
+	} else {
+		err = m.Delete(key)
+	}
+	if err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+		log.Printf("Warning: 恢复原有条目失败: %v", err)
+	} else {
+		log.Printf("已恢复 %s MAC %s 的原有链路参数", ifname, mac)
+	}
+
+	if runErr != nil {
+		log.Fatalf("回放失败: %v", runErr)
+	}
+}
@@ -5,7 +5,7 @@ go 1.18
 require (
 	github.com/cilium/ebpf v0.10.0
 	github.com/vishvananda/netlink v1.1.0
+	github.com/vishvananda/netns v0.0.5
 	golang.org/x/sys v0.8.0
+	gopkg.in/yaml.v2 v2.4.0
 )
-
-require github.com/vishvananda/netns v0.0.5 // indirect
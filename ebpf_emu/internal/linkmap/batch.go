@@ -0,0 +1,117 @@
+package linkmap
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cilium/ebpf"
+)
+
+// Dump 读取映射中的全部条目
+func Dump(m *ebpf.Map) (map[FlowKey]HandleBpsDelay, error) {
+	entries := make(map[FlowKey]HandleBpsDelay)
+	var key FlowKey
+	var value HandleBpsDelay
+	iter := m.Iterate()
+	for iter.Next(&key, &value) {
+		entries[key] = value
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("iterate map: %w", err)
+	}
+	return entries, nil
+}
+
+// BatchPut 一次系统调用写入所有条目，内核不支持批量操作时逐条写入
+func BatchPut(m *ebpf.Map, keys []FlowKey, values []HandleBpsDelay) error {
+	if len(keys) != len(values) {
+		return fmt.Errorf("batch put: %d keys but %d values", len(keys), len(values))
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	_, err := m.BatchUpdate(keys, values, &ebpf.BatchOptions{})
+	if errors.Is(err, ebpf.ErrNotSupported) {
+		for i := range keys {
+			if err := m.Put(keys[i], values[i]); err != nil {
+				return fmt.Errorf("update entry ifindex %d MAC %s: %w", keys[i].Ifindex, keys[i].MAC(), err)
+			}
+		}
+		return nil
+	}
+	return err
+}
+
+// BatchDelete 批量删除条目，内核不支持批量操作或部分键已不存在时逐条删除
+func BatchDelete(m *ebpf.Map, keys []FlowKey) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	_, err := m.BatchDelete(keys, &ebpf.BatchOptions{})
+	if errors.Is(err, ebpf.ErrNotSupported) || errors.Is(err, ebpf.ErrKeyNotExist) {
+		for _, k := range keys {
+			if err := m.Delete(k); err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+				return fmt.Errorf("delete entry ifindex %d MAC %s: %w", k.Ifindex, k.MAC(), err)
+			}
+		}
+		return nil
+	}
+	return err
+}
+
+// Converge 执行 plan：批量写入新增和修改的条目，prune 时再批量删除多余条目。
+// 任何一步失败都会把映射恢复为执行前的内容，数据面不会停留在半应用状态。
+func Converge(m *ebpf.Map, plan *Plan, prune bool) error {
+	var keys []FlowKey
+	var values []HandleBpsDelay
+	for _, c := range plan.Add {
+		keys = append(keys, c.Key)
+		values = append(values, c.New)
+	}
+	for _, c := range plan.Modify {
+		keys = append(keys, c.Key)
+		values = append(values, c.New)
+	}
+	var stale []FlowKey
+	if prune {
+		for _, c := range plan.Remove {
+			stale = append(stale, c.Key)
+		}
+	}
+
+	err := BatchPut(m, keys, values)
+	if err == nil {
+		err = BatchDelete(m, stale)
+	}
+	if err == nil {
+		return nil
+	}
+	if rerr := rollback(m, plan, prune); rerr != nil {
+		return fmt.Errorf("%v; restore previous entries: %v", err, rerr)
+	}
+	return err
+}
+
+// rollback 撤销 Converge 可能已经做出的修改
+func rollback(m *ebpf.Map, plan *Plan, prune bool) error {
+	var added []FlowKey
+	for _, c := range plan.Add {
+		added = append(added, c.Key)
+	}
+	var keys []FlowKey
+	var values []HandleBpsDelay
+	for _, c := range plan.Modify {
+		keys = append(keys, c.Key)
+		values = append(values, c.Old)
+	}
+	if prune {
+		for _, c := range plan.Remove {
+			keys = append(keys, c.Key)
+			values = append(values, c.Old)
+		}
+	}
+	if err := BatchDelete(m, added); err != nil {
+		return err
+	}
+	return BatchPut(m, keys, values)
+}
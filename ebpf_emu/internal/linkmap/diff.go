@@ -0,0 +1,84 @@
+package linkmap
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Change 一个条目的变更，新增时 Old 为空，删除时 New 为空
+type Change struct {
+	Key FlowKey
+	Old HandleBpsDelay
+	New HandleBpsDelay
+}
+
+// Plan 将映射收敛到期望状态所需的变更
+type Plan struct {
+	Add    []Change
+	Modify []Change
+	Remove []Change // 映射中存在但期望状态中没有的条目
+}
+
+// Empty 是否没有任何变更，prune 为 false 时不计入 Remove
+func (p *Plan) Empty(prune bool) bool {
+	return len(p.Add) == 0 && len(p.Modify) == 0 && (!prune || len(p.Remove) == 0)
+}
+
+// Desired 将条目列表解析为期望状态，同一个键出现多次时报错
+func Desired(entries []Entry) (map[FlowKey]HandleBpsDelay, error) {
+	desired := make(map[FlowKey]HandleBpsDelay, len(entries))
+	seen := make(map[FlowKey]int, len(entries))
+	for i := range entries {
+		key, value, err := entries[i].Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %v", i, err)
+		}
+		// 同一个键出现多次时批量写入的结果不确定，直接拒绝
+		if j, ok := seen[key]; ok {
+			return nil, fmt.Errorf("entry %d: duplicate of entry %d (ifindex %d, MAC %s)", i, j, key.Ifindex, key.MAC())
+		}
+		seen[key] = i
+		desired[key] = value
+	}
+	return desired, nil
+}
+
+// Diff 比较映射当前内容与期望状态，各类变更按网卡索引和MAC排序
+func Diff(current, desired map[FlowKey]HandleBpsDelay) *Plan {
+	plan := &Plan{}
+	for k, v := range desired {
+		old, ok := current[k]
+		switch {
+		case !ok:
+			plan.Add = append(plan.Add, Change{Key: k, New: v})
+		case old != v:
+			plan.Modify = append(plan.Modify, Change{Key: k, Old: old, New: v})
+		}
+	}
+	for k, v := range current {
+		if _, ok := desired[k]; !ok {
+			plan.Remove = append(plan.Remove, Change{Key: k, Old: v})
+		}
+	}
+	sortChanges(plan.Add)
+	sortChanges(plan.Modify)
+	sortChanges(plan.Remove)
+	return plan
+}
+
+func sortChanges(changes []Change) {
+	sort.Slice(changes, func(i, j int) bool { return LessKey(changes[i].Key, changes[j].Key) })
+}
+
+// LessKey 按网卡索引、MAC地址排序
+func LessKey(a, b FlowKey) bool {
+	if a.Ifindex != b.Ifindex {
+		return a.Ifindex < b.Ifindex
+	}
+	for i := range a.SrcMac {
+		if a.SrcMac[i] != b.SrcMac[i] {
+			return a.SrcMac[i] < b.SrcMac[i]
+		}
+	}
+	return false
+}
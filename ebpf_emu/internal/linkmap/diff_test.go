@@ -0,0 +1,111 @@
+package linkmap
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func mustKey(t *testing.T, ifindex uint32, mac string) FlowKey {
+	t.Helper()
+	key, err := NewFlowKey(ifindex, mac)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestDiff(t *testing.T) {
+	a := mustKey(t, 2, "00:00:00:00:00:0a")
+	b := mustKey(t, 2, "00:00:00:00:00:0b")
+	c := mustKey(t, 1, "00:00:00:00:00:0c")
+	d := mustKey(t, 3, "00:00:00:00:00:01")
+	e := mustKey(t, 1, "00:00:00:00:00:0e")
+
+	slow := HandleBpsDelay{ThrottleRateBps: 1000, DelayMs: 10}
+	fast := HandleBpsDelay{ThrottleRateBps: 100000, DelayMs: 10}
+	down := HandleBpsDelay{ThrottleRateBps: 1000, DelayMs: 10, AdminState: AdminDown}
+
+	current := map[FlowKey]HandleBpsDelay{a: slow, b: slow, c: slow, d: slow}
+	desired := map[FlowKey]HandleBpsDelay{a: slow, b: fast, c: down, e: fast}
+	plan := Diff(current, desired)
+
+	want := &Plan{
+		Add:    []Change{{Key: e, New: fast}},
+		Modify: []Change{{Key: c, Old: slow, New: down}, {Key: b, Old: slow, New: fast}},
+		Remove: []Change{{Key: d, Old: slow}},
+	}
+	if !reflect.DeepEqual(plan, want) {
+		t.Fatalf("Diff =\n%+v\nwant\n%+v", plan, want)
+	}
+	if plan.Empty(false) || plan.Empty(true) {
+		t.Fatal("plan with changes reported as empty")
+	}
+}
+
+func TestDiffEmpty(t *testing.T) {
+	a := mustKey(t, 2, "00:00:00:00:00:0a")
+	b := mustKey(t, 2, "00:00:00:00:00:0b")
+	v := HandleBpsDelay{ThrottleRateBps: 1000}
+
+	plan := Diff(map[FlowKey]HandleBpsDelay{a: v}, map[FlowKey]HandleBpsDelay{a: v})
+	if !plan.Empty(true) {
+		t.Fatalf("identical maps: %+v", plan)
+	}
+
+	// 只有待删除的条目时，不清理多余条目即视为无变更
+	plan = Diff(map[FlowKey]HandleBpsDelay{a: v, b: v}, map[FlowKey]HandleBpsDelay{a: v})
+	if !plan.Empty(false) || plan.Empty(true) {
+		t.Fatalf("remove-only plan: Empty(false)=%v Empty(true)=%v", plan.Empty(false), plan.Empty(true))
+	}
+}
+
+func TestLessKey(t *testing.T) {
+	keys := []FlowKey{
+		mustKey(t, 1, "00:00:00:00:00:01"),
+		mustKey(t, 1, "00:00:00:00:01:00"),
+		mustKey(t, 1, "ff:00:00:00:00:00"),
+		mustKey(t, 2, "00:00:00:00:00:00"),
+	}
+	for i := range keys {
+		for j := range keys {
+			if got := LessKey(keys[i], keys[j]); got != (i < j) {
+				t.Errorf("LessKey(%d, %d) = %v", i, j, got)
+			}
+		}
+	}
+}
+
+func TestDesired(t *testing.T) {
+	entries := []Entry{
+		{Ifindex: 2, MAC: "00:00:00:00:00:0a", BandwidthBps: 1000, DelayMs: 5},
+		{Ifindex: 2, MAC: "00:00:00:00:00:0b", State: "down"},
+	}
+	desired, err := Desired(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[FlowKey]HandleBpsDelay{
+		mustKey(t, 2, "00:00:00:00:00:0a"): {ThrottleRateBps: 1000, DelayMs: 5, AdminState: AdminUp},
+		mustKey(t, 2, "00:00:00:00:00:0b"): {AdminState: AdminDown},
+	}
+	if !reflect.DeepEqual(desired, want) {
+		t.Fatalf("Desired = %+v, want %+v", desired, want)
+	}
+
+	tests := []struct {
+		entries []Entry
+		wantErr string
+	}{
+		{[]Entry{entries[0], {Ifindex: 2, MAC: "00:00:00:00:00:0A"}}, "duplicate of entry 0"},
+		{[]Entry{{MAC: "00:00:00:00:00:0a"}}, "neither iface nor ifindex"},
+		{[]Entry{{Ifindex: 2, MAC: "not-a-mac"}}, "not-a-mac"},
+		{[]Entry{{Ifindex: 2, MAC: "00:00:00:00:00:0a", State: "sideways"}}, "invalid admin state"},
+	}
+	for _, tt := range tests {
+		_, err := Desired(tt.entries)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("Desired(%+v) error %v, want %q", tt.entries, err, tt.wantErr)
+		}
+	}
+}
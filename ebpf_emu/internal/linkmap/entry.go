@@ -0,0 +1,246 @@
+package linkmap
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// 条目文件格式
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatCSV  = "csv"
+)
+
+// Entry 映射条目的可读形式，用于批量导入导出。
+// 导入时优先按 Iface 解析网卡索引，Iface 为空时使用 Ifindex。
+type Entry struct {
+	Iface        string `json:"iface,omitempty" yaml:"iface,omitempty"`
+	Ifindex      uint32 `json:"ifindex,omitempty" yaml:"ifindex,omitempty"`
+	MAC          string `json:"mac" yaml:"mac"`
+	TcHandle     uint32 `json:"tc_handle" yaml:"tc_handle"`
+	BandwidthBps uint32 `json:"bandwidth_bps" yaml:"bandwidth_bps"`
+	DelayMs      uint32 `json:"delay_ms" yaml:"delay_ms"`
+	State        string `json:"state,omitempty" yaml:"state,omitempty"`
+}
+
+// csvHeader CSV文件的列，读取时按列名匹配，顺序与缺省列不影响解析
+var csvHeader = []string{"iface", "ifindex", "mac", "tc_handle", "bandwidth_bps", "delay_ms", "state"}
+
+// NewEntry 将映射中的键值转换为 Entry，网卡名按本机当前的网卡索引解析，找不到时留空
+func NewEntry(key FlowKey, value HandleBpsDelay) Entry {
+	e := Entry{
+		Ifindex:      key.Ifindex,
+		MAC:          key.MAC(),
+		TcHandle:     value.TcHandle,
+		BandwidthBps: value.ThrottleRateBps,
+		DelayMs:      value.DelayMs,
+		State:        AdminStateString(value.AdminState),
+	}
+	if iface, err := net.InterfaceByIndex(int(key.Ifindex)); err == nil {
+		e.Iface = iface.Name
+	}
+	return e
+}
+
+// Resolve 将 Entry 转换为映射中的键值
+func (e *Entry) Resolve() (FlowKey, HandleBpsDelay, error) {
+	ifindex := e.Ifindex
+	if e.Iface != "" {
+		iface, err := net.InterfaceByName(e.Iface)
+		if err != nil {
+			return FlowKey{}, HandleBpsDelay{}, fmt.Errorf("interface %s not found: %v", e.Iface, err)
+		}
+		ifindex = uint32(iface.Index)
+	}
+	if ifindex == 0 {
+		return FlowKey{}, HandleBpsDelay{}, fmt.Errorf("entry for MAC %s has neither iface nor ifindex", e.MAC)
+	}
+	key, err := NewFlowKey(ifindex, e.MAC)
+	if err != nil {
+		return FlowKey{}, HandleBpsDelay{}, err
+	}
+	state, err := ParseAdminState(e.State)
+	if err != nil {
+		return FlowKey{}, HandleBpsDelay{}, err
+	}
+	return key, HandleBpsDelay{
+		TcHandle:        e.TcHandle,
+		ThrottleRateBps: e.BandwidthBps,
+		DelayMs:         e.DelayMs,
+		AdminState:      state,
+	}, nil
+}
+
+// DetectFormat 返回显式指定的格式，未指定时按扩展名推断
+func DetectFormat(path, format string) (string, error) {
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+	switch format {
+	case FormatJSON, FormatCSV:
+		return format, nil
+	case FormatYAML, "yml":
+		return FormatYAML, nil
+	}
+	return "", fmt.Errorf("unknown format %q (json, yaml, csv)", format)
+}
+
+// LoadEntries 读取条目文件
+func LoadEntries(path, format string) ([]Entry, error) {
+	format, err := DetectFormat(path, format)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entries, err := ReadEntries(f, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// ReadEntries 按格式解析条目列表
+func ReadEntries(r io.Reader, format string) ([]Entry, error) {
+	var entries []Entry
+	switch format {
+	case FormatJSON:
+		// 与 YAML 的 UnmarshalStrict 一致，拼错的字段名报错而不是被悄悄忽略
+		dec := json.NewDecoder(r)
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&entries); err != nil {
+			return nil, err
+		}
+	case FormatYAML:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		if err := yaml.UnmarshalStrict(data, &entries); err != nil {
+			return nil, err
+		}
+	case FormatCSV:
+		return readCSV(r)
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	return entries, nil
+}
+
+func readCSV(r io.Reader) ([]Entry, error) {
+	cr := csv.NewReader(r)
+	cr.Comment = '#'
+	cr.TrimLeadingSpace = true
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	cols := make(map[string]int)
+	for i, name := range records[0] {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := cols["mac"]; !ok {
+		return nil, fmt.Errorf("csv header must contain a mac column: %s", strings.Join(csvHeader, ","))
+	}
+
+	entries := make([]Entry, 0, len(records)-1)
+	for n, rec := range records[1:] {
+		get := func(name string) string {
+			if i, ok := cols[name]; ok && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		num := func(name string) (uint32, error) {
+			v := get(name)
+			if v == "" {
+				return 0, nil
+			}
+			// 允许 0x 前缀的十六进制，便于书写 tc handle
+			x, err := strconv.ParseUint(v, 0, 32)
+			if err != nil {
+				return 0, fmt.Errorf("line %d: invalid %s %q", n+2, name, v)
+			}
+			return uint32(x), nil
+		}
+
+		e := Entry{Iface: get("iface"), MAC: get("mac"), State: get("state")}
+		var err error
+		if e.Ifindex, err = num("ifindex"); err != nil {
+			return nil, err
+		}
+		if e.TcHandle, err = num("tc_handle"); err != nil {
+			return nil, err
+		}
+		if e.BandwidthBps, err = num("bandwidth_bps"); err != nil {
+			return nil, err
+		}
+		if e.DelayMs, err = num("delay_ms"); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// WriteEntries 按格式输出条目列表
+func WriteEntries(w io.Writer, format string, entries []Entry) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if entries == nil {
+			entries = []Entry{}
+		}
+		return enc.Encode(entries)
+	case FormatYAML:
+		data, err := yaml.Marshal(entries)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return err
+		}
+		for _, e := range entries {
+			ifindex := ""
+			if e.Ifindex != 0 {
+				ifindex = strconv.FormatUint(uint64(e.Ifindex), 10)
+			}
+			if err := cw.Write([]string{
+				e.Iface,
+				ifindex,
+				e.MAC,
+				fmt.Sprintf("0x%x", e.TcHandle),
+				strconv.FormatUint(uint64(e.BandwidthBps), 10),
+				strconv.FormatUint(uint64(e.DelayMs), 10),
+				e.State,
+			}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
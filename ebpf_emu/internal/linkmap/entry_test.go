@@ -0,0 +1,75 @@
+package linkmap
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEntriesRoundTrip(t *testing.T) {
+	entries := []Entry{
+		{Iface: "eth0", MAC: "00:11:22:33:44:55", TcHandle: 0x10001, BandwidthBps: 1250000, DelayMs: 50, State: "up"},
+		{Ifindex: 7, MAC: "aa:bb:cc:dd:ee:ff", BandwidthBps: 125000, State: "down"},
+		{Ifindex: 4294967295, MAC: "02:00:00:00:00:01", TcHandle: 0xffffffff, BandwidthBps: 4294967295, DelayMs: 4294967295},
+	}
+	for _, format := range []string{FormatJSON, FormatYAML, FormatCSV} {
+		var buf bytes.Buffer
+		if err := WriteEntries(&buf, format, entries); err != nil {
+			t.Fatalf("%s: WriteEntries: %v", format, err)
+		}
+		got, err := ReadEntries(&buf, format)
+		if err != nil {
+			t.Fatalf("%s: ReadEntries: %v", format, err)
+		}
+		if !reflect.DeepEqual(got, entries) {
+			t.Errorf("%s: round trip\n got %+v\nwant %+v", format, got, entries)
+		}
+	}
+}
+
+func TestEntriesRoundTripEmpty(t *testing.T) {
+	for _, format := range []string{FormatJSON, FormatYAML, FormatCSV} {
+		var buf bytes.Buffer
+		if err := WriteEntries(&buf, format, nil); err != nil {
+			t.Fatalf("%s: WriteEntries: %v", format, err)
+		}
+		got, err := ReadEntries(&buf, format)
+		if err != nil {
+			t.Fatalf("%s: ReadEntries: %v", format, err)
+		}
+		if len(got) != 0 {
+			t.Errorf("%s: round trip of no entries = %+v", format, got)
+		}
+	}
+}
+
+func TestReadEntriesStrict(t *testing.T) {
+	tests := []struct {
+		format string
+		in     string
+	}{
+		{FormatJSON, `[{"mac":"00:11:22:33:44:55","bandwith_bps":100}]`},
+		{FormatYAML, "- mac: 00:11:22:33:44:55\n  bandwith_bps: 100\n"},
+		{FormatCSV, "iface,bandwidth_bps\neth0,100\n"},
+		{FormatCSV, "mac,delay_ms\n00:11:22:33:44:55,soon\n"},
+	}
+	for _, tt := range tests {
+		if got, err := ReadEntries(strings.NewReader(tt.in), tt.format); err == nil {
+			t.Errorf("%s: ReadEntries(%q) = %+v, want error", tt.format, tt.in, got)
+		}
+	}
+}
+
+func TestReadCSVColumns(t *testing.T) {
+	// 列顺序任意、缺省列为零值、tc_handle 可写十六进制、# 开头为注释
+	in := "# exported\ndelay_ms, MAC ,tc_handle\n20,00:11:22:33:44:55,0x10\n"
+	got, err := ReadEntries(strings.NewReader(in), FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Entry{{MAC: "00:11:22:33:44:55", TcHandle: 0x10, DelayMs: 20}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ReadEntries = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,79 @@
+package linkmap
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+
+	"github.com/cilium/ebpf"
+)
+
+const (
+	// DefaultPinPath ebpf-network-emulation 固定映射的默认目录
+	DefaultPinPath = "/sys/fs/bpf/"
+	// MapName 按名称固定的链路参数映射
+	MapName = "MAC_HANDLE_BPS_DELAY"
+
+	// AdminUp/AdminDown 对应C代码中的 LINK_ADMIN_UP/LINK_ADMIN_DOWN
+	AdminUp   uint32 = 0
+	AdminDown uint32 = 1
+)
+
+// FlowKey 复合键结构体：对应C代码中的flow_key
+type FlowKey struct {
+	Ifindex uint32  // 网卡接口索引
+	SrcMac  [6]byte // 源MAC地址
+}
+
+// HandleBpsDelay MAC_HANDLE_BPS_DELAY 的值结构体，对应C代码中的handle_bps_delay
+type HandleBpsDelay struct {
+	TcHandle        uint32
+	ThrottleRateBps uint32
+	DelayMs         uint32
+	AdminState      uint32 // AdminDown 时数据面丢弃该链路的全部流量
+}
+
+// NewFlowKey 根据网卡索引和MAC地址字符串构造复合键
+func NewFlowKey(ifindex uint32, mac string) (FlowKey, error) {
+	var key FlowKey
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return key, fmt.Errorf("invalid MAC address %s: %v", mac, err)
+	}
+	// 确保是6字节的MAC地址
+	if len(hw) != 6 {
+		return key, fmt.Errorf("invalid MAC address length: %s", mac)
+	}
+	key.Ifindex = ifindex
+	copy(key.SrcMac[:], hw)
+	return key, nil
+}
+
+// MAC 返回键中的MAC地址字符串
+func (k FlowKey) MAC() string {
+	return net.HardwareAddr(k.SrcMac[:]).String()
+}
+
+// AdminStateString 返回管理状态的可读名称
+func AdminStateString(state uint32) string {
+	if state == AdminDown {
+		return "down"
+	}
+	return "up"
+}
+
+// ParseAdminState 解析 up/down
+func ParseAdminState(s string) (uint32, error) {
+	switch s {
+	case "", "up":
+		return AdminUp, nil
+	case "down":
+		return AdminDown, nil
+	}
+	return 0, fmt.Errorf("invalid admin state %q (up, down)", s)
+}
+
+// OpenPinned 加载 pinPath 目录下固定的 MAC_HANDLE_BPS_DELAY 映射
+func OpenPinned(pinPath string) (*ebpf.Map, error) {
+	return ebpf.LoadPinnedMap(filepath.Join(pinPath, MapName), &ebpf.LoadPinOptions{})
+}
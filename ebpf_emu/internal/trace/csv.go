@@ -0,0 +1,75 @@
+package trace
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LoadCSV 读取 time_ms,bandwidth_bps[,delay_ms] 格式的轨迹，首行可以是表头。
+// bandwidth_bps 与条目文件一样以字节每秒为单位，原样写入 throttle_rate_bps。
+// 每行参数从 time_ms 开始生效直到下一行，最后一行的时间即为周期长度。
+func LoadCSV(path string) (*Trace, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.Comment = '#'
+	r.TrimLeadingSpace = true
+
+	tr := &Trace{}
+	for row := 1; ; row++ {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if row == 1 && len(rec) > 0 && strings.HasPrefix(strings.ToLower(rec[0]), "time") {
+			continue
+		}
+		if len(rec) < 2 {
+			return nil, fmt.Errorf("%s:%d: expected time_ms,bandwidth_bps[,delay_ms]", path, row)
+		}
+
+		ms, err := strconv.ParseUint(rec[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid time %q", path, row, rec[0])
+		}
+		rate, err := strconv.ParseUint(rec[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid bandwidth %q", path, row, rec[1])
+		}
+		p := Point{At: time.Duration(ms) * time.Millisecond, RateBps: rate}
+		if len(rec) > 2 && rec[2] != "" {
+			delay, err := strconv.ParseUint(rec[2], 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: invalid delay %q", path, row, rec[2])
+			}
+			p.DelayMs = uint32(delay)
+			p.HasDelay = true
+		}
+		if n := len(tr.Points); n > 0 && p.At < tr.Points[n-1].At {
+			return nil, fmt.Errorf("%s:%d: time must be non-decreasing", path, row)
+		}
+		tr.Points = append(tr.Points, p)
+	}
+
+	if len(tr.Points) == 0 {
+		return nil, fmt.Errorf("%s: empty trace", path)
+	}
+	tr.Duration = tr.Points[len(tr.Points)-1].At
+	if tr.Duration == 0 {
+		tr.Duration = time.Second
+	}
+	return tr, nil
+}
@@ -0,0 +1,79 @@
+package trace
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MahimahiPacketBytes Mahimahi轨迹中每个投递机会可发送的字节数（一个MTU）
+const MahimahiPacketBytes = 1500
+
+// LoadMahimahi 读取Mahimahi格式的链路轨迹。
+// 文件每行是一个毫秒时间戳，表示该时刻可以投递一个1500字节的包；
+// 同一时间戳出现多次表示可投递多个包。按 window 统计每个窗口内的投递机会换算为带宽。
+func LoadMahimahi(path string, window time.Duration) (*Trace, error) {
+	if window < time.Millisecond {
+		return nil, fmt.Errorf("window must be at least 1ms, got %v", window)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var stamps []uint64
+	scanner := bufio.NewScanner(f)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ms, err := strconv.ParseUint(line, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid timestamp %q", path, lineNo, line)
+		}
+		if len(stamps) > 0 && ms < stamps[len(stamps)-1] {
+			return nil, fmt.Errorf("%s:%d: timestamps must be non-decreasing", path, lineNo)
+		}
+		stamps = append(stamps, ms)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(stamps) == 0 {
+		return nil, fmt.Errorf("%s: empty trace", path)
+	}
+
+	// Mahimahi 以最后一个时间戳作为周期长度循环回放
+	duration := time.Duration(stamps[len(stamps)-1]) * time.Millisecond
+	if duration < window {
+		duration = window
+	}
+	windows := int((duration + window - 1) / window)
+	counts := make([]uint64, windows)
+	for _, ms := range stamps {
+		idx := int(time.Duration(ms) * time.Millisecond / window)
+		if idx >= windows {
+			idx = windows - 1
+		}
+		counts[idx]++
+	}
+
+	tr := &Trace{Duration: time.Duration(windows) * window}
+	for i, n := range counts {
+		rate := n * MahimahiPacketBytes * uint64(time.Second) / uint64(window)
+		// 相邻窗口带宽相同时合并，减少映射更新次数
+		if len(tr.Points) > 0 && tr.Points[len(tr.Points)-1].RateBps == rate {
+			continue
+		}
+		tr.Points = append(tr.Points, Point{At: time.Duration(i) * window, RateBps: rate})
+	}
+	return tr, nil
+}
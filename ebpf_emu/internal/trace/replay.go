@@ -0,0 +1,74 @@
+package trace
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"netsimlation/distribute/ebpf/internal/linkmap"
+
+	"github.com/cilium/ebpf"
+)
+
+// Replayer 按轨迹周期性更新一条链路在 MAC_HANDLE_BPS_DELAY 中的带宽与延迟
+type Replayer struct {
+	m     *ebpf.Map
+	key   linkmap.FlowKey
+	trace *Trace
+	base  linkmap.HandleBpsDelay
+}
+
+// NewReplayer 创建回放器，base 提供轨迹未指定的字段（TC handle、默认延迟）
+func NewReplayer(m *ebpf.Map, key linkmap.FlowKey, tr *Trace, base linkmap.HandleBpsDelay) *Replayer {
+	return &Replayer{m: m, key: key, trace: tr, base: base}
+}
+
+// Run 回放轨迹，loop 为true时周期性重复直到上下文取消
+func (r *Replayer) Run(ctx context.Context, loop bool) error {
+	start := time.Now()
+	for cycle := 0; ; cycle++ {
+		cycleStart := start.Add(time.Duration(cycle) * r.trace.Duration)
+		for i := range r.trace.Points {
+			p := &r.trace.Points[i]
+			timer := time.NewTimer(time.Until(cycleStart.Add(p.At)))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil
+			case <-timer.C:
+			}
+			if err := r.apply(p); err != nil {
+				return err
+			}
+		}
+
+		if !loop {
+			return nil
+		}
+		log.Printf("轨迹第 %d 轮回放完成", cycle+1)
+	}
+}
+
+// apply 将一个轨迹点写入映射
+func (r *Replayer) apply(p *Point) error {
+	value := r.base
+	rate := p.RateBps
+	// 数据面以带宽为除数计算包间隔，0会被当作不限速；轨迹中的断连窗口将链路置为关闭
+	value.AdminState = linkmap.AdminUp
+	if rate == 0 {
+		value.AdminState = linkmap.AdminDown
+	}
+	if rate > math.MaxUint32 {
+		rate = math.MaxUint32
+	}
+	value.ThrottleRateBps = uint32(rate)
+	if p.HasDelay {
+		value.DelayMs = p.DelayMs
+	}
+	if err := r.m.Put(r.key, value); err != nil {
+		return fmt.Errorf("update entry for ifindex %d, MAC %s: %v", r.key.Ifindex, r.key.MAC(), err)
+	}
+	return nil
+}
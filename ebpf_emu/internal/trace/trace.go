@@ -0,0 +1,40 @@
+package trace
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Point 从 At 开始生效、直到下一个点为止的链路参数
+type Point struct {
+	At       time.Duration
+	RateBps  uint64 // 带宽（字节每秒，与数据面的 throttle_rate_bps 相同）
+	DelayMs  uint32 // 延迟（毫秒）
+	HasDelay bool   // 为false时保留链路原有延迟
+}
+
+// Trace 按时间排序的链路参数序列
+type Trace struct {
+	Points   []Point
+	Duration time.Duration // 一个回放周期的长度，循环回放时从头开始
+}
+
+// Load 按格式读取轨迹文件，format 为空时按扩展名推断（.csv 为CSV，其余为Mahimahi）。
+// window 为Mahimahi轨迹统计带宽的时间窗口。
+func Load(path, format string, window time.Duration) (*Trace, error) {
+	if format == "" {
+		format = "mahimahi"
+		if strings.ToLower(filepath.Ext(path)) == ".csv" {
+			format = "csv"
+		}
+	}
+	switch format {
+	case "mahimahi":
+		return LoadMahimahi(path, window)
+	case "csv":
+		return LoadCSV(path)
+	}
+	return nil, fmt.Errorf("unknown trace format %q (mahimahi, csv)", format)
+}
@@ -0,0 +1,320 @@
+package instance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"netsimlation/distribute/ebpf/internal/linkmap"
+	"netsimlation/distribute/ebpf/pkg/tc"
+)
+
+const (
+	// Default 默认实例，沿用未区分实例时的固定目录与快照目录，已有部署无需迁移
+	Default = "default"
+	// PinRoot 命名实例的固定目录 PinRoot/<name>/
+	PinRoot = linkmap.DefaultPinPath + "ebpf-network-emulation/"
+	// StateRoot ebpf-network-emulation 的 qdisc 快照目录，命名实例位于 StateRoot/instances/<name>/
+	StateRoot = "/run/ebpf-network-emulation/"
+
+	// LinkDirName TCX 链接在实例固定目录下的子目录
+	LinkDirName = "edt_links"
+
+	metaFile     = "instance.json"
+	snapshotExt  = ".qdisc.json"
+	netnsDirName = "netns"
+)
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+// Instance 一个相互隔离的仿真实例：独立的固定映射、程序数组、TCX 链接与 qdisc 快照。
+// 同一主机上的多个实验使用不同实例时互不覆盖对方的链路条目
+type Instance struct {
+	Name     string `json:"name"`
+	PinPath  string `json:"pin_path"`
+	StateDir string `json:"-"`
+}
+
+// Attachment 实例挂载到的一个接口
+type Attachment struct {
+	// Netns 接口所在网络命名空间的 ID（见 netns.Namespace.ID），本机命名空间为空
+	Netns string
+	Iface string
+	// Ifindex 接口在其命名空间中的索引，取自 qdisc 快照或 TCX 链接，未知时为0
+	Ifindex int
+	// TCX 是否通过固定的 TCX 链接挂载
+	TCX bool
+}
+
+func (a Attachment) String() string {
+	if a.Netns == "" {
+		return a.Iface
+	}
+	return a.Iface + "@netns:" + a.Netns
+}
+
+// ValidateName 实例名称用作目录名，只允许字母、数字与 _.-
+func ValidateName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid instance name %q: use up to 64 letters, digits, '_', '.' or '-'", name)
+	}
+	return nil
+}
+
+// Lookup 返回名为 name 的实例，name 为空表示默认实例。pinPath 非空时覆盖固定目录；
+// 否则沿用实例上次挂载时记录的目录，从未挂载过的命名实例使用 PinRoot/<name>/
+func Lookup(name, pinPath string) (*Instance, error) {
+	if name == "" {
+		name = Default
+	}
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+	inst := &Instance{Name: name, PinPath: PinRoot + name + "/", StateDir: filepath.Join(StateRoot, "instances", name)}
+	if name == Default {
+		inst.PinPath = linkmap.DefaultPinPath
+		inst.StateDir = StateRoot
+	}
+	if pinPath != "" {
+		inst.PinPath = pinPath
+		return inst, nil
+	}
+	saved, err := load(inst.StateDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if saved != nil && saved.PinPath != "" {
+		inst.PinPath = saved.PinPath
+	}
+	return inst, nil
+}
+
+func load(stateDir string) (*Instance, error) {
+	data, err := os.ReadFile(filepath.Join(stateDir, metaFile))
+	if err != nil {
+		return nil, err
+	}
+	var inst Instance
+	if err := json.Unmarshal(data, &inst); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", filepath.Join(stateDir, metaFile), err)
+	}
+	inst.StateDir = stateDir
+	return &inst, nil
+}
+
+// Save 记录实例的固定目录，供 map-populator、testbed 等只给出实例名称的工具查找映射
+func (inst *Instance) Save() error {
+	if err := os.MkdirAll(inst.StateDir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(inst, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(inst.StateDir, metaFile)
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// LinkDir TCX 链接的固定目录，netnsID 非空时按命名空间区分
+func (inst *Instance) LinkDir(netnsID string) string {
+	if netnsID == "" {
+		return filepath.Join(inst.PinPath, LinkDirName)
+	}
+	return filepath.Join(inst.PinPath, LinkDirName, netnsDirName, netnsID)
+}
+
+// SnapshotDir qdisc 快照目录，netnsID 非空时按命名空间区分
+func (inst *Instance) SnapshotDir(netnsID string) string {
+	if netnsID == "" {
+		return inst.StateDir
+	}
+	return filepath.Join(inst.StateDir, netnsDirName, netnsID)
+}
+
+// MapPath MAC_HANDLE_BPS_DELAY 的固定路径
+func (inst *Instance) MapPath() string {
+	return filepath.Join(inst.PinPath, linkmap.MapName)
+}
+
+// Attachments 列出实例挂载到的接口：挂载时留下的 qdisc 快照与 TCX 链接固定文件的并集
+func (inst *Instance) Attachments() ([]Attachment, error) {
+	type ifaceKey struct{ netns, iface string }
+	found := make(map[ifaceKey]*Attachment)
+	add := func(netnsID, dir, suffix string, tcx bool) error {
+		entries, err := os.ReadDir(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), suffix) {
+				continue
+			}
+			k := ifaceKey{netnsID, strings.TrimSuffix(e.Name(), suffix)}
+			a := found[k]
+			if a == nil {
+				a = &Attachment{Netns: k.netns, Iface: k.iface}
+				found[k] = a
+			}
+			path := filepath.Join(dir, e.Name())
+			if tcx {
+				a.TCX = true
+				if index := tcxIfindex(path); index != 0 {
+					a.Ifindex = index
+				}
+			} else if a.Ifindex == 0 {
+				a.Ifindex = snapshotIfindex(path)
+			}
+		}
+		return nil
+	}
+	for _, id := range inst.netnsIDs() {
+		if err := add(id, inst.SnapshotDir(id), snapshotExt, false); err != nil {
+			return nil, err
+		}
+		if err := add(id, inst.LinkDir(id), "", true); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]Attachment, 0, len(found))
+	for _, a := range found {
+		result = append(result, *a)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Netns != result[j].Netns {
+			return result[i].Netns < result[j].Netns
+		}
+		return result[i].Iface < result[j].Iface
+	})
+	return result, nil
+}
+
+// SharedIfindex 返回实例在 netnsID 以外的命名空间中挂载的、网卡索引同为 ifindex 的接口。
+// 固定映射由实例的所有命名空间共用，键中只有网卡索引，这些接口的链路条目彼此无法区分
+func (inst *Instance) SharedIfindex(netnsID string, ifindex int) ([]Attachment, error) {
+	attachments, err := inst.Attachments()
+	if err != nil {
+		return nil, err
+	}
+	var shared []Attachment
+	for _, a := range attachments {
+		if a.Netns != netnsID && a.Ifindex == ifindex {
+			shared = append(shared, a)
+		}
+	}
+	return shared, nil
+}
+
+// snapshotIfindex 读取 qdisc 快照中记录的网卡索引，失败时返回0
+func snapshotIfindex(path string) int {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	var snap struct {
+		Ifindex int `json:"ifindex"`
+	}
+	if json.Unmarshal(data, &snap) != nil {
+		return 0
+	}
+	return snap.Ifindex
+}
+
+// tcxIfindex 读取固定的 TCX 链接当前挂载的网卡索引，接口已删除或读取失败时返回0
+func tcxIfindex(path string) int {
+	l, err := tc.LoadTCXLink(path)
+	if err != nil {
+		return 0
+	}
+	defer l.Close()
+	info, err := l.Info()
+	if err != nil {
+		return 0
+	}
+	return int(info.Ifindex)
+}
+
+// netnsIDs 本机命名空间（""）以及快照或链接目录中出现过的命名空间
+func (inst *Instance) netnsIDs() []string {
+	ids := map[string]bool{"": true}
+	for _, dir := range []string{inst.SnapshotDir(""), inst.LinkDir("")} {
+		entries, _ := os.ReadDir(filepath.Join(dir, netnsDirName))
+		for _, e := range entries {
+			if e.IsDir() {
+				ids[e.Name()] = true
+			}
+		}
+	}
+	result := make([]string, 0, len(ids))
+	for id := range ids {
+		result = append(result, id)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// List 返回本机上的全部实例：默认实例，以及在 StateRoot/instances 或 PinRoot 下留有目录的命名实例
+func List() ([]*Instance, error) {
+	names := map[string]bool{Default: true}
+	for _, dir := range []string{filepath.Join(StateRoot, "instances"), PinRoot} {
+		entries, err := os.ReadDir(dir)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.IsDir() && ValidateName(e.Name()) == nil {
+				names[e.Name()] = true
+			}
+		}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	result := make([]*Instance, 0, len(sorted))
+	for _, name := range sorted {
+		inst, err := Lookup(name, "")
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, inst)
+	}
+	return result, nil
+}
+
+// Owner 返回已挂载到该接口的其他实例，没有时返回 nil。
+// 两个实例挂载到同一接口时后挂载的程序会覆盖前者的 fq 与过滤器，因此挂载前须检查
+func (inst *Instance) Owner(netnsID, iface string) (*Instance, error) {
+	all, err := List()
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range all {
+		if other.Name == inst.Name {
+			continue
+		}
+		attachments, err := other.Attachments()
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range attachments {
+			if a.Netns == netnsID && a.Iface == iface {
+				return other, nil
+			}
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,174 @@
+// Package netns 在指定的网络命名空间中执行网卡查询与 TC 操作，
+// 用于从宿主机为容器内的接口挂载程序和配置链路。
+package netns
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
+)
+
+// NamedPath ip netns add 创建的命名空间所在目录
+const NamedPath = "/var/run/netns"
+
+// Path 解析命名空间参数：纯数字为进程PID（/proc/<pid>/ns/net），
+// 含 / 的为命名空间文件路径，其余为 ip netns 的名称
+func Path(spec string) (string, error) {
+	switch {
+	case spec == "":
+		return "", fmt.Errorf("empty network namespace")
+	case strings.Contains(spec, "/"):
+		return spec, nil
+	}
+	if pid, err := strconv.Atoi(spec); err == nil {
+		if pid <= 0 {
+			return "", fmt.Errorf("invalid pid %d", pid)
+		}
+		return fmt.Sprintf("/proc/%d/ns/net", pid), nil
+	}
+	return filepath.Join(NamedPath, spec), nil
+}
+
+// Namespace 打开的网络命名空间
+type Namespace struct {
+	Spec   string
+	Path   string
+	handle netns.NsHandle
+}
+
+// Open 打开 spec 指定的网络命名空间
+func Open(spec string) (*Namespace, error) {
+	path, err := Path(spec)
+	if err != nil {
+		return nil, err
+	}
+	h, err := netns.GetFromPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("open network namespace %s: %w", path, err)
+	}
+	return &Namespace{Spec: spec, Path: path, handle: h}, nil
+}
+
+// Create 创建 ip netns 风格的命名空间（绑定挂载到 NamedPath 下），调用者所在的命名空间不变
+func Create(name string) (*Namespace, error) {
+	if name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("invalid network namespace name %q", name)
+	}
+	runtime.LockOSThread()
+	orig, err := netns.Get()
+	if err != nil {
+		runtime.UnlockOSThread()
+		return nil, err
+	}
+	defer orig.Close()
+
+	// NewNamed 会将当前线程切换到新命名空间，无论成功与否都要切回；切回失败时与 Enter 相同，保持线程锁定
+	h, err := netns.NewNamed(name)
+	if serr := netns.Set(orig); serr != nil {
+		if err == nil {
+			h.Close()
+		}
+		return nil, fmt.Errorf("restore network namespace: %w", serr)
+	}
+	runtime.UnlockOSThread()
+	if err != nil {
+		return nil, fmt.Errorf("create network namespace %s: %w", name, err)
+	}
+	return &Namespace{Spec: name, Path: filepath.Join(NamedPath, name), handle: h}, nil
+}
+
+// Temporary 在新建的匿名网络命名空间中执行 fn，用于不影响本机网卡地试探内核功能。
+// 命名空间没有绑定挂载，切回后随线程引用的释放由内核回收
+func Temporary(fn func() error) error {
+	runtime.LockOSThread()
+	orig, err := netns.Get()
+	if err != nil {
+		runtime.UnlockOSThread()
+		return err
+	}
+	defer orig.Close()
+	h, err := netns.New()
+	if err != nil {
+		runtime.UnlockOSThread()
+		return fmt.Errorf("create temporary network namespace: %w", err)
+	}
+	h.Close()
+
+	ferr := fn()
+	if err := netns.Set(orig); err != nil {
+		// 线程保持锁定，goroutine 结束后由运行时销毁
+		return fmt.Errorf("restore network namespace: %w", err)
+	}
+	runtime.UnlockOSThread()
+	return ferr
+}
+
+// Remove 删除 Create 或 ip netns add 创建的命名空间，其中的网卡随之销毁（veth 的对端一并删除）
+func Remove(name string) error {
+	if err := netns.DeleteNamed(name); err != nil {
+		return fmt.Errorf("delete network namespace %s: %w", name, err)
+	}
+	return nil
+}
+
+// Fd 返回命名空间文件描述符，用于 netlink.NsFd 等需要在创建时指定命名空间的场景
+func (n *Namespace) Fd() int {
+	return int(n.handle)
+}
+
+// ID 返回命名空间的 inode 编号，同一命名空间通过名称、路径或PID打开时相同
+func (n *Namespace) ID() (string, error) {
+	var st unix.Stat_t
+	if err := unix.Fstat(int(n.handle), &st); err != nil {
+		return "", err
+	}
+	return strconv.FormatUint(st.Ino, 10), nil
+}
+
+// Enter 将当前 goroutine 固定到所在线程并切换到该命名空间，之后在此 goroutine 中
+// 创建的 netlink 套接字都属于该命名空间。restore 切回原命名空间；切回失败时线程保持锁定，
+// goroutine 结束后由运行时销毁该线程，不会污染其他 goroutine。
+func (n *Namespace) Enter() (restore func() error, err error) {
+	runtime.LockOSThread()
+	orig, err := netns.Get()
+	if err != nil {
+		runtime.UnlockOSThread()
+		return nil, err
+	}
+	if err := netns.Set(n.handle); err != nil {
+		orig.Close()
+		runtime.UnlockOSThread()
+		return nil, fmt.Errorf("enter network namespace %s: %w", n.Path, err)
+	}
+	return func() error {
+		defer orig.Close()
+		if err := netns.Set(orig); err != nil {
+			return fmt.Errorf("restore network namespace: %w", err)
+		}
+		runtime.UnlockOSThread()
+		return nil
+	}, nil
+}
+
+// Do 在该命名空间中执行 fn
+func (n *Namespace) Do(fn func() error) error {
+	restore, err := n.Enter()
+	if err != nil {
+		return err
+	}
+	ferr := fn()
+	if err := restore(); err != nil && ferr == nil {
+		return err
+	}
+	return ferr
+}
+
+// Close 关闭命名空间文件描述符
+func (n *Namespace) Close() error {
+	return n.handle.Close()
+}
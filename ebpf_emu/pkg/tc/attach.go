@@ -0,0 +1,275 @@
+package tc
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/vishvananda/netlink"
+)
+
+// AttachMode 程序挂载到出口方向的方式
+type AttachMode string
+
+const (
+	// ModeAuto 内核支持时使用 TCX，否则回退到 clsact 过滤器
+	ModeAuto AttachMode = "auto"
+	// ModeTCX 通过固定的 bpf_link 挂载到 TCX 出口
+	ModeTCX AttachMode = "tcx"
+	// ModeClsact 传统的 clsact qdisc + bpf 过滤器，使用固定的 handle 与优先级
+	ModeClsact AttachMode = "clsact"
+)
+
+// ParseAttachMode 解析 auto/tcx/clsact，空字符串为 auto
+func ParseAttachMode(s string) (AttachMode, error) {
+	switch m := AttachMode(s); m {
+	case "":
+		return ModeAuto, nil
+	case ModeAuto, ModeTCX, ModeClsact:
+		return m, nil
+	}
+	return "", fmt.Errorf("invalid attach mode %q (auto, tcx, clsact)", s)
+}
+
+// AttachOptions 挂载参数
+type AttachOptions struct {
+	ProgFD int    // 已加载的 tc 程序
+	Name   string // 过滤器名称，用于 -status 识别
+	// StateDir 挂载前 qdisc 快照的目录，Detach 时据此恢复；为空时不记录快照
+	StateDir string
+	// Mode 挂载方式，零值同 ModeAuto
+	Mode AttachMode
+	// LinkPinPath TCX 链接在 bpffs 中的固定路径，每个接口一个。为空时不使用 TCX
+	LinkPinPath string
+}
+
+// Attach 在接口出口方向挂载程序：记录 qdisc 快照、安装 fq（多队列为 mq+fq），
+// 再通过 TCX 链接或 clsact 过滤器挂载程序，返回实际使用的方式。
+// 重复执行是幂等的：已固定的 TCX 链接原地原子替换程序，两种方式之间切换时清除另一种方式的挂载；
+// 任何一步失败都会撤销本次调用已做的修改，接口保持调用前的配置。
+func Attach(iface netlink.Link, opts AttachOptions) (mode AttachMode, err error) {
+	mode = opts.Mode
+	if mode == "" {
+		mode = ModeAuto
+	}
+	if mode == ModeTCX && opts.LinkPinPath == "" {
+		return "", fmt.Errorf("attach %s: tcx mode requires a link pin path", iface.Attrs().Name)
+	}
+	if mode == ModeAuto && opts.LinkPinPath == "" {
+		mode = ModeClsact
+	}
+
+	var undo []func() error
+	defer func() {
+		if err == nil {
+			return
+		}
+		for i := len(undo) - 1; i >= 0; i-- {
+			if rerr := undo[i](); rerr != nil {
+				err = fmt.Errorf("%w; rollback: %v", err, rerr)
+			}
+		}
+	}()
+
+	if opts.StateDir != "" {
+		created, err := EnsureQdiscSnapshot(opts.StateDir, iface)
+		if err != nil {
+			return "", err
+		}
+		if created {
+			undo = append(undo, func() error { return RemoveQdiscSnapshot(opts.StateDir, iface.Attrs().Name) })
+		}
+	}
+
+	// 替换根 qdisc 前记录当前的 qdisc 树，失败时原样恢复（包括多队列网卡只替换了部分队列的情况）
+	before, err := TakeQdiscSnapshot(iface)
+	if err != nil {
+		return "", err
+	}
+	undo = append(undo, func() error { return before.Restore(iface) })
+	if _, err := CreateFQdisc(iface); err != nil {
+		return "", err
+	}
+
+	if mode != ModeClsact {
+		rollback, err := attachTCX(iface, opts)
+		switch {
+		case err == nil:
+			undo = append(undo, rollback)
+			// 之前以 clsact 方式挂载过时删除旧过滤器，避免两份程序同时存在
+			if !before.Clsact {
+				return ModeTCX, nil
+			}
+			if status, serr := GetStatus(iface); serr == nil && status.Attached(opts.Name) {
+				if err := DeleteTCBpfFilter(iface, netlink.HANDLE_MIN_EGRESS); err != nil {
+					return "", err
+				}
+			}
+			return ModeTCX, nil
+		case mode == ModeAuto && errors.Is(err, ErrTCXUnsupported):
+			log.Printf("内核不支持 TCX，%s 使用 clsact 过滤器挂载", iface.Attrs().Name)
+		default:
+			return "", err
+		}
+	}
+
+	if _, err := CreateClsactQdisc(iface); err != nil {
+		return "", err
+	}
+	if !before.Clsact {
+		undo = append(undo, func() error { deleteClsact(iface); return nil })
+	}
+	// 固定使用egress方向
+	if _, err := CreateTCBpfFilter(iface, opts.ProgFD, netlink.HANDLE_MIN_EGRESS, opts.Name); err != nil {
+		return "", err
+	}
+	// 之前以 TCX 方式挂载过时卸下旧链接
+	if opts.LinkPinPath != "" {
+		if err := detachTCX(opts.LinkPinPath); err != nil {
+			return "", err
+		}
+	}
+	return ModeClsact, nil
+}
+
+// attachTCX 复用固定路径上仍挂在该接口的链接并原子替换程序，否则新建链接并固定。
+// 返回撤销本次修改的函数
+func attachTCX(iface netlink.Link, opts AttachOptions) (func() error, error) {
+	l, err := LoadTCXLink(opts.LinkPinPath)
+	switch {
+	case err == nil:
+		info, ierr := l.Info()
+		if ierr == nil && int(info.Ifindex) == iface.Attrs().Index {
+			defer l.Close()
+			if err := l.Update(opts.ProgFD); err != nil {
+				return nil, newError("update", "tcx link", iface, err)
+			}
+			log.Printf("已原子替换 %s 上 TCX 链接 %d 的程序", iface.Attrs().Name, info.ID)
+			// 旧程序已被替换且可能已释放，无法撤销；后续步骤失败时保留新程序
+			return func() error { return nil }, nil
+		}
+		// 接口已删除并重建（链接失效）或固定路径被其他接口的链接占用
+		log.Printf("移除 %s 上失效的 TCX 链接", opts.LinkPinPath)
+		err = l.Detach()
+		l.Close()
+		if err != nil {
+			return nil, err
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, err
+	}
+
+	l, err = AttachTCX(iface, opts.ProgFD)
+	if err != nil {
+		return nil, err
+	}
+	defer l.Close()
+	if err := l.Pin(opts.LinkPinPath); err != nil {
+		l.Detach()
+		return nil, err
+	}
+	info, err := l.Info()
+	if err == nil {
+		log.Printf("已通过 TCX 链接 %d 挂载到 %s 的出口方向，固定于 %s", info.ID, iface.Attrs().Name, opts.LinkPinPath)
+	}
+	return func() error { return detachTCX(opts.LinkPinPath) }, nil
+}
+
+// detachTCX 卸下固定在 path 的 TCX 链接，不存在时什么也不做
+func detachTCX(path string) error {
+	l, err := LoadTCXLink(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+	return l.Detach()
+}
+
+// TCXAttached 固定在 path 的 TCX 链接是否仍挂在该接口上，返回链接信息
+func TCXAttached(iface netlink.Link, path string) (*TCXLinkInfo, bool) {
+	if path == "" {
+		return nil, false
+	}
+	l, err := LoadTCXLink(path)
+	if err != nil {
+		return nil, false
+	}
+	defer l.Close()
+	info, err := l.Info()
+	if err != nil || int(info.Ifindex) != iface.Attrs().Index {
+		return nil, false
+	}
+	return info, true
+}
+
+// AttachedProgram 返回接口上由 Attach 挂载、当前正在运行的程序ID及其挂载方式，
+// 没有挂载时返回 ErrNotAttached
+func AttachedProgram(iface netlink.Link, opts AttachOptions) (uint32, AttachMode, error) {
+	if info, ok := TCXAttached(iface, opts.LinkPinPath); ok {
+		return info.ProgID, ModeTCX, nil
+	}
+	status, err := GetStatus(iface)
+	if err != nil {
+		return 0, "", err
+	}
+	for _, f := range status.Filters {
+		if f.Name == opts.Name {
+			return uint32(f.ProgID), ModeClsact, nil
+		}
+	}
+	return 0, "", newError("find", "program", iface, ErrNotAttached)
+}
+
+// Replace 原子地将接口上由 Attach 挂载的程序替换为 opts.ProgFD，不修改 qdisc：
+// TCX 方式更新链接，clsact 方式以相同的 handle/优先级替换过滤器，替换期间报文始终经过其中一个程序。
+// 没有挂载时返回 ErrNotAttached
+func Replace(iface netlink.Link, opts AttachOptions) (AttachMode, error) {
+	_, mode, err := AttachedProgram(iface, opts)
+	if err != nil {
+		return "", err
+	}
+	if mode == ModeTCX {
+		l, err := LoadTCXLink(opts.LinkPinPath)
+		if err != nil {
+			return "", err
+		}
+		defer l.Close()
+		if err := l.Update(opts.ProgFD); err != nil {
+			return "", newError("update", "tcx link", iface, err)
+		}
+		return ModeTCX, nil
+	}
+	if _, err := CreateTCBpfFilter(iface, opts.ProgFD, netlink.HANDLE_MIN_EGRESS, opts.Name); err != nil {
+		return "", err
+	}
+	return ModeClsact, nil
+}
+
+// Detach 卸载 Attach 挂载的程序（TCX 链接与 clsact 过滤器）并恢复 StateDir 中记录的 qdisc 树，随后删除快照
+func Detach(iface netlink.Link, opts AttachOptions) error {
+	if opts.LinkPinPath != "" {
+		if err := detachTCX(opts.LinkPinPath); err != nil {
+			return err
+		}
+	}
+	var snap *QdiscSnapshot
+	if opts.StateDir != "" {
+		var err error
+		if snap, err = LoadQdiscSnapshot(opts.StateDir, iface); err != nil {
+			return err
+		}
+	}
+	if err := ClearEbpf(iface, snap); err != nil {
+		return err
+	}
+	if opts.StateDir != "" {
+		if err := RemoveQdiscSnapshot(opts.StateDir, iface.Attrs().Name); err != nil {
+			log.Printf("Warning: 无法删除 %s 的 qdisc 快照: %v", iface.Attrs().Name, err)
+		}
+	}
+	return nil
+}
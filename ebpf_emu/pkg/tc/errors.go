@@ -0,0 +1,47 @@
+package tc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+var (
+	// ErrIfaceNotFound 网卡接口不存在
+	ErrIfaceNotFound = errors.New("interface not found")
+	// ErrQdiscUnsupported 内核不支持所需的 qdisc 类型（如未加载 sch_fq 模块）
+	ErrQdiscUnsupported = errors.New("qdisc kind not supported by the kernel")
+	// ErrTCXUnsupported 内核不支持 TCX 挂载（需要 6.6 及以上）
+	ErrTCXUnsupported = errors.New("tcx attachment not supported by the kernel")
+	// ErrNotAttached 接口上没有 Attach 挂载的程序
+	ErrNotAttached = errors.New("program not attached")
+)
+
+// Error TC 操作失败，记录失败的步骤、对象和接口，可用 errors.Is 判断底层的 errno
+type Error struct {
+	Op     string // add、replace、delete、list、restore 等
+	Object string // 如 "clsact qdisc"、"bpf filter"
+	Iface  string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s %s on %s: %v", e.Op, e.Object, e.Iface, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is 创建或替换 qdisc 时内核找不到对应类型会返回 ENOENT
+func (e *Error) Is(target error) bool {
+	return target == ErrQdiscUnsupported && (e.Op == "add" || e.Op == "replace") &&
+		strings.Contains(e.Object, "qdisc") && errors.Is(e.Err, unix.ENOENT)
+}
+
+func newError(op, object string, iface netlink.Link, err error) error {
+	return &Error{Op: op, Object: object, Iface: iface.Attrs().Name, Err: err}
+}
@@ -0,0 +1,69 @@
+package tc
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/vishvananda/netlink"
+)
+
+// IfacePatterns 逗号分隔的网卡名称或 glob 模式，如 "eth0,veth*"
+type IfacePatterns []string
+
+// ParseIfacePatterns 解析 -iface 参数并校验其中的 glob 模式
+func ParseIfacePatterns(spec string) (IfacePatterns, error) {
+	var patterns IfacePatterns
+	for _, p := range strings.Split(spec, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if _, err := filepath.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("invalid interface pattern %q: %v", p, err)
+		}
+		patterns = append(patterns, p)
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("no interface specified")
+	}
+	return patterns, nil
+}
+
+// Match 网卡名称是否匹配任一模式
+func (p IfacePatterns) Match(name string) bool {
+	for _, pattern := range p {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Links 返回匹配的全部网卡，按网卡索引排序。
+// 不含通配符的名称必须存在，整体没有匹配到任何网卡时同样返回错误。
+func (p IfacePatterns) Links() ([]netlink.Link, error) {
+	links, err := netlink.LinkList()
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]bool)
+	var matched []netlink.Link
+	for _, link := range links {
+		if p.Match(link.Attrs().Name) {
+			matched = append(matched, link)
+			found[link.Attrs().Name] = true
+		}
+	}
+	for _, pattern := range p {
+		if !strings.ContainsAny(pattern, "*?[") && !found[pattern] {
+			return nil, fmt.Errorf("interface %s not found", pattern)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("no interface matches %s", strings.Join(p, ","))
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].Attrs().Index < matched[j].Attrs().Index })
+	return matched, nil
+}
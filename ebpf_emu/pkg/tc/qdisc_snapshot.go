@@ -0,0 +1,240 @@
+package tc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/vishvananda/netlink"
+)
+
+// FQ_HANDLE 挂载时安装的根 qdisc（单队列为 fq，多队列为 mq）使用的 handle，
+// 根 qdisc 为该 handle 时说明接口已被挂载过，不再重复记录快照
+var FQ_HANDLE = netlink.MakeHandle(0x123, 0)
+
+// SavedQdisc 快照中的一个 qdisc，Options 为 netlink 对应类型的完整参数
+type SavedQdisc struct {
+	Kind    string          `json:"kind"`
+	Handle  uint32          `json:"handle"`
+	Parent  uint32          `json:"parent"`
+	Options json.RawMessage `json:"options,omitempty"`
+}
+
+// QdiscSnapshot 挂载前接口上的出口 qdisc 树
+type QdiscSnapshot struct {
+	Iface   string       `json:"iface"`
+	Ifindex int          `json:"ifindex"`
+	TakenAt time.Time    `json:"taken_at"`
+	Clsact  bool         `json:"clsact"` // 挂载前已存在 clsact，清理时只删除自己的过滤器
+	Qdiscs  []SavedQdisc `json:"qdiscs"`
+}
+
+// TakeQdiscSnapshot 记录接口当前的 qdisc 树。clsact/ingress 不属于出口树，只记录是否存在。
+func TakeQdiscSnapshot(iface netlink.Link) (*QdiscSnapshot, error) {
+	qdiscs, err := netlink.QdiscList(iface)
+	if err != nil {
+		return nil, newError("list", "qdiscs", iface, err)
+	}
+	snap := &QdiscSnapshot{
+		Iface:   iface.Attrs().Name,
+		Ifindex: iface.Attrs().Index,
+		TakenAt: time.Now(),
+	}
+	for _, q := range qdiscs {
+		attrs := q.Attrs()
+		if attrs.Parent == netlink.HANDLE_INGRESS {
+			if q.Type() == "clsact" {
+				snap.Clsact = true
+			}
+			continue
+		}
+		options, err := json.Marshal(q)
+		if err != nil {
+			return nil, fmt.Errorf("encode %s qdisc %s: %w", q.Type(), netlink.HandleStr(attrs.Handle), err)
+		}
+		snap.Qdiscs = append(snap.Qdiscs, SavedQdisc{
+			Kind:    q.Type(),
+			Handle:  attrs.Handle,
+			Parent:  attrs.Parent,
+			Options: options,
+		})
+	}
+
+	// 只恢复 qdisc，分类和 qdisc 上的过滤器不在快照范围内
+	if classes, err := netlink.ClassList(iface, netlink.HANDLE_ROOT); err == nil {
+		for _, c := range classes {
+			if c.Type() != "mq" {
+				log.Printf("Warning: %s 上存在 %s 分类，清理时不会恢复", snap.Iface, c.Type())
+				break
+			}
+		}
+	}
+	return snap, nil
+}
+
+// Root 返回快照中的根 qdisc
+func (s *QdiscSnapshot) Root() *SavedQdisc {
+	for i := range s.Qdiscs {
+		if s.Qdiscs[i].Parent == netlink.HANDLE_ROOT {
+			return &s.Qdiscs[i]
+		}
+	}
+	return nil
+}
+
+// Restore 用快照替换接口当前的出口 qdisc 树。
+// 先删除根 qdisc 让内核装回默认配置；原来的根由内核创建（handle 为 0）时到此即与挂载前一致，
+// 否则按父节点先于子节点的顺序重建快照中显式配置过的 qdisc。
+func (s *QdiscSnapshot) Restore(iface netlink.Link) error {
+	index := iface.Attrs().Index
+	root := &netlink.GenericQdisc{QdiscAttrs: netlink.QdiscAttrs{LinkIndex: index, Parent: netlink.HANDLE_ROOT}}
+	if err := netlink.QdiscDel(root); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("Warning: 无法删除根 qdisc: %v", err)
+	}
+
+	// 已存在的 qdisc 的主编号，子节点的父节点必须已经存在
+	present := map[uint16]bool{0: true}
+	pending := make([]SavedQdisc, 0, len(s.Qdiscs))
+	for _, q := range s.Qdiscs {
+		// handle 为 0 的 qdisc 由内核随父节点自动创建
+		if q.Handle != 0 {
+			pending = append(pending, q)
+		}
+	}
+	for len(pending) > 0 {
+		var next []SavedQdisc
+		for _, saved := range pending {
+			parentMajor, _ := netlink.MajorMinor(saved.Parent)
+			if saved.Parent != netlink.HANDLE_ROOT && !present[parentMajor] {
+				next = append(next, saved)
+				continue
+			}
+			q, err := saved.qdisc(index)
+			if err != nil {
+				return err
+			}
+			if err := netlink.QdiscReplace(q); err != nil {
+				return newError("restore", fmt.Sprintf("%s qdisc %s parent %s", saved.Kind,
+					netlink.HandleStr(saved.Handle), netlink.HandleStr(saved.Parent)), iface, err)
+			}
+			major, _ := netlink.MajorMinor(saved.Handle)
+			present[major] = true
+		}
+		if len(next) == len(pending) {
+			return fmt.Errorf("restore qdiscs on %s: %d qdiscs have no parent in the snapshot", s.Iface, len(next))
+		}
+		pending = next
+	}
+	return nil
+}
+
+// qdisc 将快照条目还原为 netlink 对应类型的 qdisc
+func (saved *SavedQdisc) qdisc(index int) (netlink.Qdisc, error) {
+	var q netlink.Qdisc
+	switch saved.Kind {
+	case "fq":
+		q = &netlink.Fq{}
+	case "fq_codel":
+		q = &netlink.FqCodel{}
+	case "htb":
+		q = &netlink.Htb{}
+	case "hfsc":
+		q = &netlink.Hfsc{}
+	case "netem":
+		q = &netlink.Netem{}
+	case "tbf":
+		q = &netlink.Tbf{}
+	case "prio":
+		q = &netlink.Prio{}
+	case "pfifo_fast":
+		q = &netlink.PfifoFast{}
+	default:
+		// mq、noqueue 等没有参数或 netlink 无法解析参数的类型只按类型重建
+		q = &netlink.GenericQdisc{QdiscType: saved.Kind}
+	}
+	if len(saved.Options) > 0 {
+		if err := json.Unmarshal(saved.Options, q); err != nil {
+			return nil, fmt.Errorf("decode %s qdisc %s: %w", saved.Kind, netlink.HandleStr(saved.Handle), err)
+		}
+	}
+	if g, ok := q.(*netlink.GenericQdisc); ok {
+		g.QdiscType = saved.Kind
+	}
+	*q.Attrs() = netlink.QdiscAttrs{LinkIndex: index, Handle: saved.Handle, Parent: saved.Parent}
+	return q, nil
+}
+
+func snapshotPath(dir, iface string) string {
+	return filepath.Join(dir, iface+".qdisc.json")
+}
+
+// SaveQdiscSnapshot 将快照写入 dir/<iface>.qdisc.json
+func SaveQdiscSnapshot(dir string, s *QdiscSnapshot) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	// 先写临时文件再改名，避免中断后留下不完整的快照
+	path := snapshotPath(dir, s.Iface)
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// LoadQdiscSnapshot 读取接口的快照，不存在或属于同名的旧接口时返回 nil
+func LoadQdiscSnapshot(dir string, iface netlink.Link) (*QdiscSnapshot, error) {
+	data, err := os.ReadFile(snapshotPath(dir, iface.Attrs().Name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var s QdiscSnapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("decode qdisc snapshot of %s: %w", iface.Attrs().Name, err)
+	}
+	if s.Ifindex != iface.Attrs().Index {
+		log.Printf("Warning: %s 的 qdisc 快照属于已删除的接口 (ifindex %d)，忽略", s.Iface, s.Ifindex)
+		return nil, nil
+	}
+	return &s, nil
+}
+
+// RemoveQdiscSnapshot 删除接口的快照
+func RemoveQdiscSnapshot(dir, iface string) error {
+	err := os.Remove(snapshotPath(dir, iface))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// EnsureQdiscSnapshot 在首次挂载前记录接口的 qdisc 树，返回是否新写入了快照。
+// 已有有效快照或根 qdisc 已经是挂载时安装的 qdisc（例如旧版本挂载过）时不再记录，
+// 以免把自己的配置当成原始配置
+func EnsureQdiscSnapshot(dir string, iface netlink.Link) (bool, error) {
+	existing, err := LoadQdiscSnapshot(dir, iface)
+	if err != nil || existing != nil {
+		return false, err
+	}
+	snap, err := TakeQdiscSnapshot(iface)
+	if err != nil {
+		return false, err
+	}
+	if root := snap.Root(); root != nil && root.Handle == FQ_HANDLE {
+		return false, nil
+	}
+	if err := SaveQdiscSnapshot(dir, snap); err != nil {
+		return false, err
+	}
+	return true, nil
+}
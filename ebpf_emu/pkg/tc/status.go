@@ -0,0 +1,82 @@
+package tc
+
+import (
+	"github.com/vishvananda/netlink"
+)
+
+// QdiscStatus 接口上的一个 qdisc
+type QdiscStatus struct {
+	Kind   string
+	Handle string
+	Parent string
+}
+
+// FilterStatus 出口方向上挂载的一个 bpf 过滤器
+type FilterStatus struct {
+	Name     string
+	Priority uint16
+	ProgID   int
+	ProgTag  string
+}
+
+// IfaceStatus 接口上与流量仿真相关的 TC 组件
+type IfaceStatus struct {
+	Name    string
+	Index   int
+	Qdiscs  []QdiscStatus
+	Filters []FilterStatus
+}
+
+// Attached 接口出口方向上是否挂载了指定名称的 bpf 程序
+func (s *IfaceStatus) Attached(name string) bool {
+	for _, f := range s.Filters {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GetStatus 读取接口上的 qdisc 以及出口方向的 bpf 过滤器
+func GetStatus(iface netlink.Link) (*IfaceStatus, error) {
+	status := &IfaceStatus{Name: iface.Attrs().Name, Index: iface.Attrs().Index}
+
+	qdiscs, err := netlink.QdiscList(iface)
+	if err != nil {
+		return nil, newError("list", "qdiscs", iface, err)
+	}
+	hasClsact := false
+	for _, q := range qdiscs {
+		attrs := q.Attrs()
+		status.Qdiscs = append(status.Qdiscs, QdiscStatus{
+			Kind:   q.Type(),
+			Handle: netlink.HandleStr(attrs.Handle),
+			Parent: netlink.HandleStr(attrs.Parent),
+		})
+		if q.Type() == "clsact" {
+			hasClsact = true
+		}
+	}
+	// 没有 clsact 时出口方向不可能挂载过滤器，内核对不存在的 parent 会返回错误
+	if !hasClsact {
+		return status, nil
+	}
+
+	filters, err := netlink.FilterList(iface, netlink.HANDLE_MIN_EGRESS)
+	if err != nil {
+		return nil, newError("list", "egress filters", iface, err)
+	}
+	for _, f := range filters {
+		bpf, ok := f.(*netlink.BpfFilter)
+		if !ok {
+			continue
+		}
+		status.Filters = append(status.Filters, FilterStatus{
+			Name:     bpf.Name,
+			Priority: bpf.Priority,
+			ProgID:   bpf.Id,
+			ProgTag:  bpf.Tag,
+		})
+	}
+	return status, nil
+}
@@ -0,0 +1,290 @@
+package tc
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// GetIface 按名称查找网卡接口，不存在时返回 ErrIfaceNotFound
+func GetIface(name string) (netlink.Link, error) {
+	iface, err := netlink.LinkByName(name)
+	if err != nil {
+		var notFound netlink.LinkNotFoundError
+		if errors.As(err, &notFound) {
+			return nil, fmt.Errorf("%w: %s", ErrIfaceNotFound, name)
+		}
+		return nil, err
+	}
+	return iface, nil
+}
+
+// CreateFQdisc 安装 fq，已存在的根 qdisc 会被替换，重复执行不会失败。
+// 多队列网卡在根节点安装 mq，并在每个发送队列对应的 mq 子节点下安装 fq，
+// 避免单个根 fq 把所有队列串行化。
+func CreateFQdisc(iface netlink.Link) (*netlink.Fq, error) {
+	//tc qdisc replace dev wlp2s0 root fq ce_threshold 4ms
+	index := iface.Attrs().Index
+	if queues := iface.Attrs().NumTxQueues; queues > 1 {
+		return createMqFq(iface, queues)
+	}
+
+	attrs := netlink.QdiscAttrs{
+		LinkIndex: index,
+		Handle:    FQ_HANDLE,
+		Parent:    netlink.HANDLE_ROOT,
+	}
+
+	//fq := netlink.NewFq(attrs)
+
+	fq := &netlink.Fq{
+		QdiscAttrs: attrs,
+		Pacing:     0,
+	}
+
+	if err := netlink.QdiscReplace(fq); err != nil {
+		return nil, newError("replace", "fq qdisc", iface, err)
+	}
+	log.Printf("Installed fq qdisc %v", fq)
+	return fq, nil
+}
+
+// createMqFq tc qdisc replace dev X root handle 123: mq，然后每个队列 tc qdisc replace dev X parent 123:N fq
+func createMqFq(iface netlink.Link, queues int) (*netlink.Fq, error) {
+	index := iface.Attrs().Index
+	mq := &netlink.GenericQdisc{
+		QdiscAttrs: netlink.QdiscAttrs{
+			LinkIndex: index,
+			Handle:    FQ_HANDLE,
+			Parent:    netlink.HANDLE_ROOT,
+		},
+		QdiscType: "mq",
+	}
+	if err := netlink.QdiscReplace(mq); err != nil {
+		return nil, newError("replace", "mq qdisc", iface, err)
+	}
+
+	major, _ := netlink.MajorMinor(FQ_HANDLE)
+	var fq *netlink.Fq
+	for i := 1; i <= queues; i++ {
+		// handle 为0时由内核分配
+		fq = &netlink.Fq{
+			QdiscAttrs: netlink.QdiscAttrs{
+				LinkIndex: index,
+				Parent:    netlink.MakeHandle(major, uint16(i)),
+			},
+			Pacing: 0,
+		}
+		if err := netlink.QdiscReplace(fq); err != nil {
+			return nil, newError("replace", fmt.Sprintf("fq qdisc under mq queue %d", i), iface, err)
+		}
+	}
+	log.Printf("Installed mq qdisc with fq on %d tx queues of %s", queues, iface.Attrs().Name)
+	return fq, nil
+}
+
+func CreateNetemQdisc(iface netlink.Link, handle, parent uint32, netemAttr netlink.NetemQdiscAttrs) (*netlink.Netem, error) {
+	netemQdiscAttr := netlink.QdiscAttrs{
+		LinkIndex: iface.Attrs().Index,
+		Handle:    handle,
+		Parent:    parent,
+	}
+
+	netemQdisc := netlink.NewNetem(netemQdiscAttr, netemAttr)
+
+	if err := netlink.QdiscAdd(netemQdisc); err != nil {
+		return nil, newError("add", "netem qdisc", iface, err)
+	}
+	//log.Printf("Added netem qdisc %v", netemQdisc)
+	return netemQdisc, nil
+}
+
+func CreateHtbClass(iface netlink.Link, handle, parent uint32, htbClassAttrs netlink.HtbClassAttrs) (*netlink.HtbClass, error) {
+	classAttr := netlink.ClassAttrs{
+		LinkIndex: iface.Attrs().Index,
+		Handle:    handle,
+		Parent:    parent,
+	}
+
+	htbClass := netlink.NewHtbClass(classAttr, htbClassAttrs)
+
+	if err := netlink.ClassAdd(htbClass); err != nil {
+		return nil, newError("add", "htb class", iface, err)
+	}
+	//log.Printf("Added htb class %v", htbClass)
+	return htbClass, nil
+}
+
+func CreateHtbQdisc(iface netlink.Link, handle, parent uint32) (*netlink.Htb, error) {
+	attrs := netlink.QdiscAttrs{
+		LinkIndex: iface.Attrs().Index,
+		Handle:    handle,
+		Parent:    parent,
+	}
+
+	qdiscHtb := netlink.NewHtb(attrs)
+
+	if err := netlink.QdiscAdd(qdiscHtb); err != nil {
+		return nil, newError("add", "htb qdisc", iface, err)
+	}
+	//log.Printf("Added htb qdisc %v", qdiscHtb)
+	return qdiscHtb, nil
+}
+
+// CreateTCBpfFilter 挂载 bpf 过滤器，相同优先级和 handle 的已有过滤器会被原子替换，
+// 重复执行时新程序直接接管流量
+func CreateTCBpfFilter(iface netlink.Link, progFd int, parent uint32, name string) (*netlink.BpfFilter, error) {
+	filterAttrs := netlink.FilterAttrs{
+		LinkIndex: iface.Attrs().Index,
+		Parent:    parent,
+		Handle:    netlink.MakeHandle(0, 1),
+		Protocol:  unix.ETH_P_ALL,
+		Priority:  1,
+	}
+
+	filter := &netlink.BpfFilter{
+		FilterAttrs:  filterAttrs,
+		Fd:           progFd,
+		Name:         name,
+		DirectAction: true,
+	}
+
+	if err := netlink.FilterReplace(filter); err != nil {
+		return nil, newError("replace", "bpf filter", iface, err)
+	}
+	log.Printf("Created bpf filter: %v", filter)
+	return filter, nil
+}
+
+// FindQdisc 返回接口上挂在 parent 下、类型为 kind 的 qdisc，不存在时返回 nil
+func FindQdisc(iface netlink.Link, parent uint32, kind string) (netlink.Qdisc, error) {
+	qdiscs, err := netlink.QdiscList(iface)
+	if err != nil {
+		return nil, newError("list", "qdiscs", iface, err)
+	}
+	for _, q := range qdiscs {
+		if q.Attrs().Parent == parent && q.Type() == kind {
+			return q, nil
+		}
+	}
+	return nil, nil
+}
+
+// CreateClsactQdisc 创建 clsact qdisc，已存在时直接复用，以免删除上面挂载的过滤器
+func CreateClsactQdisc(iface netlink.Link) (*netlink.GenericQdisc, error) {
+	attrs := netlink.QdiscAttrs{
+		LinkIndex: iface.Attrs().Index,
+		Handle:    netlink.MakeHandle(0xffff, 0),
+		Parent:    netlink.HANDLE_CLSACT,
+	}
+
+	qdisc := &netlink.GenericQdisc{
+		QdiscAttrs: attrs,
+		QdiscType:  "clsact",
+	}
+
+	existing, err := FindQdisc(iface, netlink.HANDLE_CLSACT, "clsact")
+	if err != nil {
+		return nil, newError("list", "qdiscs", iface, err)
+	}
+	if existing != nil {
+		log.Printf("Reusing existing clsact qdisc on %s", iface.Attrs().Name)
+		return qdisc, nil
+	}
+
+	if err := netlink.QdiscAdd(qdisc); err != nil {
+		return nil, newError("add", "clsact qdisc", iface, err)
+	}
+	log.Printf("Added clsact qdisc %v", qdisc)
+	return qdisc, nil
+}
+
+// DeleteTCBpfFilter 删除 CreateTCBpfFilter 挂载的过滤器
+func DeleteTCBpfFilter(iface netlink.Link, parent uint32) error {
+	filter := &netlink.BpfFilter{
+		FilterAttrs: netlink.FilterAttrs{
+			LinkIndex: iface.Attrs().Index,
+			Parent:    parent,
+			Handle:    netlink.MakeHandle(0, 1),
+			Protocol:  unix.ETH_P_ALL,
+			Priority:  1,
+		},
+	}
+	if err := netlink.FilterDel(filter); err != nil {
+		return newError("delete", "bpf filter", iface, err)
+	}
+	return nil
+}
+
+// ClearEbpf 清理网络接口上的 eBPF 程序和 TC 组件。
+// snap 为挂载前记录的 qdisc 快照，不为 nil 时恢复原有的 qdisc 树并保留挂载前已存在的 clsact；
+// 没有快照时删除根 qdisc，由内核装回默认配置。
+func ClearEbpf(iface netlink.Link, snap *QdiscSnapshot) error {
+	if snap != nil {
+		if snap.Clsact {
+			// clsact 上可能还有其他程序的过滤器，只删除自己挂载的
+			if err := DeleteTCBpfFilter(iface, netlink.HANDLE_MIN_EGRESS); err != nil {
+				log.Printf("Warning: 无法删除 bpf 过滤器: %v", err)
+			}
+		} else {
+			deleteClsact(iface)
+		}
+		if err := snap.Restore(iface); err != nil {
+			return err
+		}
+		log.Printf("已清理 %s 接口上的 eBPF 程序和 TC 组件，并恢复挂载前的 qdisc", iface.Attrs().Name)
+		return nil
+	}
+
+	deleteClsact(iface)
+
+	// 移除根节点上由挂载安装的 qdisc（fq 或 mq）
+	fqAttrs := netlink.QdiscAttrs{
+		LinkIndex: iface.Attrs().Index,
+		Handle:    FQ_HANDLE,
+		Parent:    netlink.HANDLE_ROOT,
+	}
+	fq := &netlink.Fq{
+		QdiscAttrs: fqAttrs,
+	}
+	if err := netlink.QdiscDel(fq); err != nil {
+		log.Printf("Warning: 无法删除 fq qdisc: %v", err)
+	}
+
+	// 尝试删除所有根节点上的 qdisc
+	rootAttrs := netlink.QdiscAttrs{
+		LinkIndex: iface.Attrs().Index,
+		Parent:    netlink.HANDLE_ROOT,
+	}
+	rootQdisc := &netlink.GenericQdisc{
+		QdiscAttrs: rootAttrs,
+	}
+	if err := netlink.QdiscDel(rootQdisc); err != nil {
+		log.Printf("Warning: 无法删除根 qdisc: %v", err)
+	}
+
+	log.Printf("已清理 %s 接口上的 eBPF 程序和 TC 组件", iface.Attrs().Name)
+	return nil
+}
+
+// deleteClsact 移除 clsact qdisc（包含入口和出口过滤器），以 TCX 方式挂载时可能本就没有
+func deleteClsact(iface netlink.Link) {
+	if q, err := FindQdisc(iface, netlink.HANDLE_CLSACT, "clsact"); err == nil && q == nil {
+		return
+	}
+	clsactAttrs := netlink.QdiscAttrs{
+		LinkIndex: iface.Attrs().Index,
+		Handle:    netlink.MakeHandle(0xffff, 0),
+		Parent:    netlink.HANDLE_CLSACT,
+	}
+	clsact := &netlink.GenericQdisc{
+		QdiscAttrs: clsactAttrs,
+		QdiscType:  "clsact",
+	}
+	if err := netlink.QdiscDel(clsact); err != nil {
+		log.Printf("Warning: 无法删除 clsact qdisc: %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+package tc
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"unsafe"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// cilium/ebpf v0.10 尚不认识 TCX（内核 6.6 引入），LoadPinnedLink 在读取未知类型的链接信息时会失败，
+// 这里直接通过 bpf(2) 创建、固定和更新 TCX 链接
+const (
+	bpfAttachTCXEgress = 47 // BPF_TCX_EGRESS
+	bpfLinkTypeTCX     = 11 // BPF_LINK_TYPE_TCX
+)
+
+// TCXLink 挂载在接口 TCX 出口上的 bpf_link。
+// 链接由文件描述符或 bpffs 中的固定路径持有，与 clsact 过滤器不同，不占用 tc 的 handle/优先级，
+// 也不会被其他 tc 用户误删；固定后加载程序退出不影响挂载，替换程序是原子的
+type TCXLink struct {
+	fd   int
+	Path string // 固定路径，未固定时为空
+}
+
+// TCXLinkInfo 链接的内核信息
+type TCXLinkInfo struct {
+	ID      uint32
+	ProgID  uint32
+	Ifindex uint32 // 接口已删除时为 0
+}
+
+func bpf(cmd int, attr unsafe.Pointer, size uintptr) (uintptr, error) {
+	r, _, errno := unix.Syscall(unix.SYS_BPF, uintptr(cmd), uintptr(attr), size)
+	runtime.KeepAlive(attr)
+	if errno != 0 {
+		return r, errno
+	}
+	return r, nil
+}
+
+// AttachTCX 在接口的 TCX 出口上创建链接。内核不支持 TCX 或 bpf_link 时返回 ErrTCXUnsupported
+func AttachTCX(iface netlink.Link, progFD int) (*TCXLink, error) {
+	attr := struct {
+		progFD           uint32
+		targetIfindex    uint32
+		attachType       uint32
+		flags            uint32
+		relativeFD       uint32
+		_                uint32
+		expectedRevision uint64
+	}{
+		progFD:        uint32(progFD),
+		targetIfindex: uint32(iface.Attrs().Index),
+		attachType:    bpfAttachTCXEgress,
+	}
+	fd, err := bpf(unix.BPF_LINK_CREATE, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	if errors.Is(err, unix.EINVAL) {
+		// 旧内核不认识该挂载类型（或整个 BPF_LINK_CREATE 命令）时返回 EINVAL
+		return nil, newError("create", "tcx link", iface, fmt.Errorf("%w: %v", ErrTCXUnsupported, err))
+	}
+	if err != nil {
+		return nil, newError("create", "tcx link", iface, err)
+	}
+	return &TCXLink{fd: int(fd)}, nil
+}
+
+// LoadTCXLink 打开固定在 path 的链接，不存在时返回 os.ErrNotExist
+func LoadTCXLink(path string) (*TCXLink, error) {
+	p, err := unix.BytePtrFromString(path)
+	if err != nil {
+		return nil, err
+	}
+	attr := struct {
+		pathname  uint64
+		bpfFD     uint32
+		fileFlags uint32
+	}{pathname: uint64(uintptr(unsafe.Pointer(p)))}
+	fd, err := bpf(unix.BPF_OBJ_GET, unsafe.Pointer(&attr), unsafe.Sizeof(attr))
+	if errors.Is(err, unix.ENOENT) {
+		return nil, fmt.Errorf("load tcx link %s: %w", path, os.ErrNotExist)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load tcx link %s: %w", path, err)
+	}
+	l := &TCXLink{fd: int(fd), Path: path}
+	// 确认固定的是 TCX 链接，而不是同名的其他 bpf 对象
+	if _, err := l.Info(); err != nil {
+		l.Close()
+		return nil, fmt.Errorf("load tcx link %s: %w", path, err)
+	}
+	return l, nil
+}
+
+// Pin 将链接固定到 bpffs 中的 path，父目录不存在时创建
+func (l *TCXLink) Pin(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	p, err := unix.BytePtrFromString(path)
+	if err != nil {
+		return err
+	}
+	attr := struct {
+		pathname  uint64
+		bpfFD     uint32
+		fileFlags uint32
+	}{pathname: uint64(uintptr(unsafe.Pointer(p))), bpfFD: uint32(l.fd)}
+	if _, err := bpf(unix.BPF_OBJ_PIN, unsafe.Pointer(&attr), unsafe.Sizeof(attr)); err != nil {
+		return fmt.Errorf("pin tcx link to %s: %w", path, err)
+	}
+	l.Path = path
+	return nil
+}
+
+// Update 原子地将链接上的程序替换为 progFD，替换期间不会有报文绕过程序
+func (l *TCXLink) Update(progFD int) error {
+	attr := struct {
+		linkFD    uint32
+		newProgFD uint32
+		flags     uint32
+		oldProgFD uint32
+	}{linkFD: uint32(l.fd), newProgFD: uint32(progFD)}
+	if _, err := bpf(unix.BPF_LINK_UPDATE, unsafe.Pointer(&attr), unsafe.Sizeof(attr)); err != nil {
+		return fmt.Errorf("update tcx link: %w", err)
+	}
+	return nil
+}
+
+// Info 读取链接信息，链接不是 TCX 类型时返回错误
+func (l *TCXLink) Info() (*TCXLinkInfo, error) {
+	// struct bpf_link_info：type、id、prog_id，之后 8 字节对齐的联合体中 tcx 为 {ifindex, attach_type}
+	var buf [64]byte
+	attr := struct {
+		bpfFD   uint32
+		infoLen uint32
+		info    uint64
+	}{bpfFD: uint32(l.fd), infoLen: uint32(len(buf)), info: uint64(uintptr(unsafe.Pointer(&buf[0])))}
+	if _, err := bpf(unix.BPF_OBJ_GET_INFO_BY_FD, unsafe.Pointer(&attr), unsafe.Sizeof(attr)); err != nil {
+		return nil, fmt.Errorf("tcx link info: %w", err)
+	}
+	field := func(off int) uint32 { return *(*uint32)(unsafe.Pointer(&buf[off])) }
+	if t := field(0); t != bpfLinkTypeTCX {
+		return nil, fmt.Errorf("object is not a tcx link (type %d)", t)
+	}
+	return &TCXLinkInfo{ID: field(4), ProgID: field(8), Ifindex: field(16)}, nil
+}
+
+// Detach 立即从接口上卸下链接并删除固定路径
+func (l *TCXLink) Detach() error {
+	attr := struct{ linkFD uint32 }{uint32(l.fd)}
+	// 接口已被删除时链接已自动失效，内核返回 ENOLINK
+	if _, err := bpf(unix.BPF_LINK_DETACH, unsafe.Pointer(&attr), unsafe.Sizeof(attr)); err != nil && !errors.Is(err, unix.ENOLINK) {
+		return fmt.Errorf("detach tcx link: %w", err)
+	}
+	return l.Unpin()
+}
+
+// Unpin 删除固定路径，之后关闭最后一个文件描述符时链接随之卸下
+func (l *TCXLink) Unpin() error {
+	if l.Path == "" {
+		return nil
+	}
+	if err := os.Remove(l.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	l.Path = ""
+	return nil
+}
+
+// Close 关闭文件描述符，已固定的链接保持挂载
+func (l *TCXLink) Close() error {
+	return unix.Close(l.fd)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"netsimlation/distribute/master_server/internal/clocksync"
+	"netsimlation/distribute/master_server/internal/store"
+)
+
+// clock-server 作为主控参考时钟常驻运行，供从节点估计时钟偏移，并打印生效偏差上报
+func main() {
+	var redisAddr string
+	var redisPassword string
+	var redisDB int
+
+	flag.StringVar(&redisAddr, "addr", "localhost:6379", "Redis server address")
+	flag.StringVar(&redisPassword, "password", "", "Redis password")
+	flag.IntVar(&redisDB, "db", 0, "Redis database")
+	flag.Parse()
+
+	st := store.New(store.Options{
+		Addr:     redisAddr,
+		Password: redisPassword,
+		DB:       redisDB,
+	})
+	defer st.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := st.Ping(ctx); err != nil {
+		log.Fatalf("无法连接到Redis服务器 %s: %v", redisAddr, err)
+	}
+
+	go clocksync.WatchReports(ctx, st.Client(), clocksync.LogReport)
+
+	if err := clocksync.Serve(ctx, st.Client()); err != nil {
+		log.Fatalf("时钟同步服务退出: %v", err)
+	}
+}
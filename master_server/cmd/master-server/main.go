@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"netsimlation/distribute/master_server/internal/api"
+	"netsimlation/distribute/master_server/internal/clocksync"
+	"netsimlation/distribute/master_server/internal/control"
+	"netsimlation/distribute/master_server/internal/controlpb"
+	"netsimlation/distribute/master_server/internal/store"
+)
+
+// master-server 常驻运行的主控服务：提供 HTTP/JSON 控制接口（文档见 /api/v1/openapi.json），
+// 同时作为从节点的参考时钟。指定 -grpc-listen 时另外提供gRPC控制服务，供无法访问Redis的从节点接入。
+func main() {
+	var listenAddr string
+	var grpcAddr string
+	var redisAddr string
+	var redisPassword string
+	var redisDB int
+
+	flag.StringVar(&listenAddr, "listen", ":8080", "HTTP listen address")
+	flag.StringVar(&grpcAddr, "grpc-listen", "", "gRPC control service listen address for slaves using the grpc transport, e.g. :9090 (disabled if empty)")
+	flag.StringVar(&redisAddr, "addr", "localhost:6379", "Redis server address")
+	flag.StringVar(&redisPassword, "password", "", "Redis password")
+	flag.IntVar(&redisDB, "db", 0, "Redis database")
+	flag.Parse()
+
+	st := store.New(store.Options{
+		Addr:     redisAddr,
+		Password: redisPassword,
+		DB:       redisDB,
+	})
+	defer st.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := st.Ping(ctx); err != nil {
+		log.Fatalf("无法连接到Redis服务器 %s: %v", redisAddr, err)
+	}
+
+	go clocksync.WatchReports(ctx, st.Client(), clocksync.LogReport)
+	go func() {
+		if err := clocksync.Serve(ctx, st.Client()); err != nil && ctx.Err() == nil {
+			log.Printf("时钟同步服务退出: %v", err)
+		}
+	}()
+
+	if grpcAddr != "" {
+		lis, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			log.Fatalf("监听gRPC地址 %s 失败: %v", grpcAddr, err)
+		}
+		ctrl := control.NewServer(st)
+		grpcServer := grpc.NewServer()
+		controlpb.RegisterControlServer(grpcServer, ctrl)
+
+		go func() {
+			if err := ctrl.Watch(ctx); err != nil && ctx.Err() == nil {
+				log.Fatalf("订阅Redis键空间事件失败: %v", err)
+			}
+		}()
+		go func() {
+			<-ctx.Done()
+			grpcServer.Stop()
+		}()
+		go func() {
+			log.Printf("gRPC控制服务监听 %s", grpcAddr)
+			if err := grpcServer.Serve(lis); err != nil {
+				log.Printf("gRPC控制服务退出: %v", err)
+			}
+		}()
+	}
+
+	apiServer := api.NewServer(st)
+	srv := &http.Server{
+		Addr:              listenAddr,
+		Handler:           apiServer,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		apiServer.Shutdown()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Printf("关闭HTTP服务失败: %v", err)
+		}
+	}()
+
+	log.Printf("主控服务监听 %s", listenAddr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalf("HTTP服务异常退出: %v", err)
+	}
+	log.Printf("主控服务已退出")
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"netsimlation/distribute/master_server/internal/mobility"
+	"netsimlation/distribute/master_server/internal/store"
+)
+
+func main() {
+	var configPath string
+	var dryRun bool
+	var dryRunDuration time.Duration
+
+	// Redis连接参数
+	var redisAddr string
+	var redisPassword string
+	var redisDB int
+
+	flag.StringVar(&configPath, "config", "", "Mobility config (YAML) with nodes, links and propagation model")
+	flag.BoolVar(&dryRun, "dry-run", false, "Simulate offline and print link updates without touching Redis")
+	flag.DurationVar(&dryRunDuration, "dry-run-duration", time.Minute, "Simulated time for -dry-run when the config has no duration")
+	flag.StringVar(&redisAddr, "addr", "localhost:6379", "Redis server address")
+	flag.StringVar(&redisPassword, "password", "", "Redis password")
+	flag.IntVar(&redisDB, "db", 0, "Redis database")
+	flag.Parse()
+
+	if configPath == "" {
+		log.Fatalf("错误: 必须使用 -config 参数指定移动模型配置文件")
+	}
+	cfg, err := mobility.LoadConfig(configPath)
+	if err != nil {
+		log.Fatalf("加载移动模型配置失败: %v", err)
+	}
+	sim, err := mobility.NewSimulator(cfg)
+	if err != nil {
+		log.Fatalf("创建仿真器失败: %v", err)
+	}
+
+	if dryRun {
+		duration := cfg.SimDuration()
+		if duration == 0 {
+			duration = dryRunDuration
+		}
+		fmt.Println("Time\t\tLink\tDistance (m)\tSNR (dB)\tChange")
+		fmt.Println("----------------------------------------------------------------------------")
+		for t := time.Duration(0); t <= duration; t += cfg.Interval() {
+			for _, u := range sim.Step(t) {
+				fmt.Printf("%v\t\t%s\t%.1f\t\t%.1f\t\t%s\n", t, u.Link, u.DistanceM, u.Quality.SnrDb, u.Patch.String())
+			}
+		}
+		return
+	}
+
+	st := store.New(store.Options{
+		Addr:     redisAddr,
+		Password: redisPassword,
+		DB:       redisDB,
+	})
+	defer st.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := st.Ping(ctx); err != nil {
+		log.Fatalf("无法连接到Redis服务器 %s: %v", redisAddr, err)
+	}
+	log.Printf("开始移动模型仿真: %d 个节点, %d 条链路, 更新周期 %v", len(cfg.Nodes), len(cfg.Links), cfg.Interval())
+
+	if err := sim.Run(ctx, st); err != nil {
+		log.Fatalf("仿真失败: %v", err)
+	}
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"os"
+
+	"netsimlation/distribute/master_server/internal/ctl"
+)
+
+// netsimctl 主控端命令行工具，取代原先的 db-operation，例如：
+//
+//	netsimctl link add 1 -mac 02:00:00:00:00:01 -bw 10mbit -delay 50ms -loss 1%
+//	netsimctl link set 1 -delay 80ms -apply-in 2s
+//	netsimctl topology apply -f configs/topology-example.yaml
+//	netsimctl slave status -o json
+func main() {
+	os.Exit(ctl.Main(os.Args[1:]))
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"netsimlation/distribute/master_server/internal/clocksync"
+	"netsimlation/distribute/master_server/internal/satellite"
+	"netsimlation/distribute/master_server/internal/scenario"
+	"netsimlation/distribute/master_server/internal/store"
+)
+
+func main() {
+	var configPath string
+	var startAt string
+	var startIn time.Duration
+	var lead time.Duration
+	var output string
+	var dryRun bool
+
+	// Redis连接参数
+	var redisAddr string
+	var redisPassword string
+	var redisDB int
+
+	flag.StringVar(&configPath, "config", "", "Constellation config (YAML) with satellites, ground stations and links")
+	flag.StringVar(&startAt, "start", "", "Simulation start time in RFC3339 format (default: now + -start-in)")
+	flag.DurationVar(&startIn, "start-in", 5*time.Second, "Delay before the simulation starts when -start is not given")
+	flag.DurationVar(&lead, "lead", 500*time.Millisecond, "How long before apply_at each update is published to Redis")
+	flag.StringVar(&output, "output", "", "Write the generated timeline to this file (.yaml/.json) instead of running it")
+	flag.BoolVar(&dryRun, "dry-run", false, "Print the generated schedule without touching Redis")
+	flag.StringVar(&redisAddr, "addr", "localhost:6379", "Redis server address")
+	flag.StringVar(&redisPassword, "password", "", "Redis password")
+	flag.IntVar(&redisDB, "db", 0, "Redis database")
+	flag.Parse()
+
+	if configPath == "" {
+		log.Fatalf("错误: 必须使用 -config 参数指定星座配置文件")
+	}
+	cfg, err := satellite.LoadConfig(configPath)
+	if err != nil {
+		log.Fatalf("加载星座配置失败: %v", err)
+	}
+
+	// 首批更新需要提前 lead 发布，起点至少留出这段时间
+	if startIn < lead+time.Second {
+		startIn = lead + time.Second
+	}
+	start := time.Now().Add(startIn)
+	if startAt != "" {
+		start, err = time.Parse(time.RFC3339Nano, startAt)
+		if err != nil {
+			log.Fatalf("无效的开始时间 %q: %v", startAt, err)
+		}
+	}
+
+	gen, err := satellite.NewGenerator(cfg, start)
+	if err != nil {
+		log.Fatalf("创建生成器失败: %v", err)
+	}
+	sc, err := gen.Timeline()
+	if err != nil {
+		log.Fatalf("生成时间线失败: %v", err)
+	}
+	log.Printf("已生成 %d 颗卫星、%d 个地面站、%d 条链路的时间线，共 %d 个事件",
+		len(cfg.Satellites), len(cfg.GroundStations), len(cfg.Links), len(sc.Events))
+
+	if dryRun {
+		sc.PrintSchedule(os.Stdout, start)
+		return
+	}
+	if output != "" {
+		if err := sc.Save(output); err != nil {
+			log.Fatalf("写入时间线失败: %v", err)
+		}
+		log.Printf("时间线已写入 %s，可使用 scenario-runner -lead 执行", output)
+		return
+	}
+
+	st := store.New(store.Options{
+		Addr:     redisAddr,
+		Password: redisPassword,
+		DB:       redisDB,
+	})
+	defer st.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := st.Ping(ctx); err != nil {
+		log.Fatalf("无法连接到Redis服务器 %s: %v", redisAddr, err)
+	}
+
+	// 作为参考时钟响应从节点同步，并记录生效偏差
+	go func() {
+		if err := clocksync.Serve(ctx, st.Client()); err != nil {
+			log.Printf("时钟同步服务退出: %v", err)
+		}
+	}()
+	go clocksync.WatchReports(ctx, st.Client(), clocksync.LogReport)
+
+	runner := scenario.NewRunner(st, sc, start)
+	runner.SetLead(lead)
+	go runner.ListenControl(ctx)
+
+	if err := runner.Run(ctx); err != nil {
+		log.Fatalf("星座链路更新结束: %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"netsimlation/distribute/master_server/internal/clocksync"
+	"netsimlation/distribute/master_server/internal/scenario"
+	"netsimlation/distribute/master_server/internal/store"
+)
+
+func main() {
+	var file string
+	var startAt string
+	var startIn time.Duration
+	var dryRun bool
+	var lead time.Duration
+
+	// Redis连接参数
+	var redisAddr string
+	var redisPassword string
+	var redisDB int
+
+	flag.StringVar(&file, "file", "", "Timeline file (.json/.yaml) describing scheduled link changes")
+	flag.StringVar(&startAt, "start", "", "Experiment start time in RFC3339 format (default: now + -start-in)")
+	flag.DurationVar(&startIn, "start-in", 3*time.Second, "Delay before the experiment starts when -start is not given")
+	flag.BoolVar(&dryRun, "dry-run", false, "Print the schedule without touching Redis")
+	flag.DurationVar(&lead, "lead", 0, "Publish set events this long before they are due with apply_at, letting slaves apply them on synchronized clocks (0 = apply on arrival)")
+	flag.StringVar(&redisAddr, "addr", "localhost:6379", "Redis server address")
+	flag.StringVar(&redisPassword, "password", "", "Redis password")
+	flag.IntVar(&redisDB, "db", 0, "Redis database")
+	flag.Parse()
+
+	if file == "" {
+		log.Fatalf("错误: 必须使用 -file 参数指定时间线文件")
+	}
+
+	sc, err := scenario.Load(file)
+	if err != nil {
+		log.Fatalf("加载时间线失败: %v", err)
+	}
+
+	start := time.Now().Add(startIn)
+	if startAt != "" {
+		start, err = time.Parse(time.RFC3339Nano, startAt)
+		if err != nil {
+			log.Fatalf("无效的开始时间 %q: %v", startAt, err)
+		}
+	}
+
+	if dryRun {
+		sc.PrintSchedule(os.Stdout, start)
+		return
+	}
+
+	st := store.New(store.Options{
+		Addr:     redisAddr,
+		Password: redisPassword,
+		DB:       redisDB,
+	})
+	defer st.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := st.Ping(ctx); err != nil {
+		log.Fatalf("无法连接到Redis服务器 %s: %v", redisAddr, err)
+	}
+
+	runner := scenario.NewRunner(st, sc, start)
+	go runner.ListenControl(ctx)
+
+	if lead > 0 {
+		// 作为参考时钟响应从节点同步，并记录各从节点上报的生效偏差
+		runner.SetLead(lead)
+		go func() {
+			if err := clocksync.Serve(ctx, st.Client()); err != nil {
+				log.Printf("时钟同步服务退出: %v", err)
+			}
+		}()
+		go clocksync.WatchReports(ctx, st.Client(), clocksync.LogReport)
+	}
+
+	// SIGUSR1 暂停，SIGUSR2 恢复，SIGINT/SIGTERM 中止
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGUSR1, syscall.SIGUSR2, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		for sig := range sigChan {
+			switch sig {
+			case syscall.SIGUSR1:
+				runner.Pause()
+			case syscall.SIGUSR2:
+				runner.Resume()
+			default:
+				log.Printf("Received signal: %v, aborting scenario...", sig)
+				runner.Abort()
+			}
+		}
+	}()
+
+	if err := runner.Run(ctx); err != nil {
+		log.Fatalf("场景执行结束: %v", err)
+	}
+}
@@ -0,0 +1,210 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"netsimlation/distribute/master_server/internal/scenario"
+	"netsimlation/distribute/master_server/internal/store"
+)
+
+// experimentRequest 启动实验的参数，start 与 start_in_ms 均为空时立即开始
+type experimentRequest struct {
+	Scenario  *scenario.Scenario `json:"scenario"`
+	Start     *time.Time         `json:"start,omitempty"`       // 绝对开始时间（RFC3339）
+	StartInMs int64              `json:"start_in_ms,omitempty"` // 相对当前时间延迟开始
+	LeadMs    int64              `json:"lead_ms,omitempty"`     // 从节点定时模式的提前量，见 Runner.SetLead
+}
+
+// experimentStatus 实验执行状态
+type experimentStatus struct {
+	Name      string         `json:"name,omitempty"`
+	State     scenario.State `json:"state"`
+	Next      int            `json:"next"`
+	Total     int            `json:"total"`
+	Start     *time.Time     `json:"start,omitempty"`
+	LastError string         `json:"last_error,omitempty"`
+}
+
+// experimentManager 同一时间只运行一个场景，执行器在后台协程中运行，与请求生命周期无关
+type experimentManager struct {
+	st *store.Store
+
+	mu      sync.Mutex
+	runner  *scenario.Runner
+	sc      *scenario.Scenario
+	start   time.Time
+	cancel  context.CancelFunc
+	done    chan struct{}
+	lastErr error
+}
+
+func newExperimentManager(st *store.Store) *experimentManager {
+	return &experimentManager{st: st}
+}
+
+func (m *experimentManager) running() bool {
+	if m.done == nil {
+		return false
+	}
+	select {
+	case <-m.done:
+		return false
+	default:
+		return true
+	}
+}
+
+func (m *experimentManager) startExperiment(req *experimentRequest) error {
+	if req.Scenario == nil {
+		return badRequest("scenario is required")
+	}
+	if err := req.Scenario.Prepare(); err != nil {
+		return badRequest("invalid scenario: %v", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.running() {
+		return &apiError{Status: http.StatusConflict, Message: "an experiment is already running"}
+	}
+
+	start := time.Now().Add(time.Duration(req.StartInMs) * time.Millisecond)
+	if req.Start != nil {
+		start = *req.Start
+	}
+	runner := scenario.NewRunner(m.st, req.Scenario, start)
+	if req.LeadMs > 0 {
+		runner.SetLead(time.Duration(req.LeadMs) * time.Millisecond)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	m.runner, m.sc, m.start = runner, req.Scenario, start
+	m.cancel, m.done, m.lastErr = cancel, done, nil
+
+	go func() {
+		defer close(done)
+		defer cancel()
+		err := runner.Run(ctx)
+		if err != nil && !errors.Is(err, scenario.ErrAborted) {
+			log.Printf("场景 %q 执行失败: %v", req.Scenario.Name, err)
+		}
+		m.mu.Lock()
+		m.lastErr = err
+		m.mu.Unlock()
+	}()
+	return nil
+}
+
+func (m *experimentManager) status() experimentStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.runner == nil {
+		return experimentStatus{State: scenario.StateIdle}
+	}
+	state, next := m.runner.State()
+	start := m.start
+	st := experimentStatus{
+		Name:  m.sc.Name,
+		State: state,
+		Next:  next,
+		Total: len(m.sc.Events),
+		Start: &start,
+	}
+	if m.lastErr != nil {
+		st.LastError = m.lastErr.Error()
+	}
+	return st
+}
+
+// control 向运行中的执行器投递命令
+func (m *experimentManager) control(fn func(r *scenario.Runner)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.running() {
+		return &apiError{Status: http.StatusConflict, Message: "no experiment is running"}
+	}
+	fn(m.runner)
+	return nil
+}
+
+// stop 中止实验并等待执行器退出
+func (m *experimentManager) stop() error {
+	m.mu.Lock()
+	if !m.running() {
+		m.mu.Unlock()
+		return &apiError{Status: http.StatusConflict, Message: "no experiment is running"}
+	}
+	runner, cancel, done := m.runner, m.cancel, m.done
+	m.mu.Unlock()
+
+	runner.Abort()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		// 执行器可能阻塞在Redis调用上，直接取消上下文
+		cancel()
+		<-done
+	}
+	return nil
+}
+
+// Shutdown 中止正在运行的实验，供服务退出时调用
+func (s *Server) Shutdown() {
+	if err := s.exp.stop(); err == nil {
+		log.Printf("服务退出，已中止正在运行的实验")
+	}
+}
+
+func (s *Server) registerExperimentRoutes() {
+	s.handle("GET", "/api/v1/experiment", "Show the state of the current experiment", "experiment",
+		nil, experimentStatus{},
+		func(r *http.Request, _ map[string]string) (interface{}, error) {
+			return s.exp.status(), nil
+		})
+
+	s.handle("POST", "/api/v1/experiment", "Start a scenario timeline as an experiment", "experiment",
+		experimentRequest{}, experimentStatus{},
+		func(r *http.Request, _ map[string]string) (interface{}, error) {
+			var req experimentRequest
+			if err := decodeBody(r, &req); err != nil {
+				return nil, err
+			}
+			if err := s.exp.startExperiment(&req); err != nil {
+				return nil, err
+			}
+			return s.exp.status(), nil
+		})
+
+	s.handle("DELETE", "/api/v1/experiment", "Abort the running experiment", "experiment",
+		nil, experimentStatus{},
+		func(r *http.Request, _ map[string]string) (interface{}, error) {
+			if err := s.exp.stop(); err != nil {
+				return nil, err
+			}
+			return s.exp.status(), nil
+		})
+
+	s.handle("POST", "/api/v1/experiment/pause", "Pause the running experiment", "experiment",
+		nil, experimentStatus{},
+		func(r *http.Request, _ map[string]string) (interface{}, error) {
+			if err := s.exp.control((*scenario.Runner).Pause); err != nil {
+				return nil, err
+			}
+			return s.exp.status(), nil
+		})
+
+	s.handle("POST", "/api/v1/experiment/resume", "Resume a paused experiment", "experiment",
+		nil, experimentStatus{},
+		func(r *http.Request, _ map[string]string) (interface{}, error) {
+			if err := s.exp.control((*scenario.Runner).Resume); err != nil {
+				return nil, err
+			}
+			return s.exp.status(), nil
+		})
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"netsimlation/distribute/master_server/internal/model"
+)
+
+// linkStateRequest 修改链路管理状态的可选参数
+type linkStateRequest struct {
+	ApplyAt int64 `json:"apply_at,omitempty"` // 主控时钟下的计划生效时刻（Unix毫秒）
+}
+
+func (s *Server) registerLinkRoutes() {
+	s.handle("GET", "/api/v1/links", "List all links", "links", nil, []model.LinkRecord{},
+		func(r *http.Request, _ map[string]string) (interface{}, error) {
+			return s.st.ListLinks(r.Context())
+		})
+
+	s.handle("POST", "/api/v1/links", "Create or replace a link", "links", model.LinkRecord{}, model.LinkRecord{},
+		func(r *http.Request, _ map[string]string) (interface{}, error) {
+			var rec model.LinkRecord
+			if err := decodeBody(r, &rec); err != nil {
+				return nil, err
+			}
+			if rec.ID == "" {
+				return nil, badRequest("link id is required")
+			}
+			return s.putLink(r, rec.ID, &rec.NetworkLink)
+		})
+
+	s.handle("GET", "/api/v1/links/{id}", "Get a link", "links", nil, model.LinkRecord{},
+		func(r *http.Request, p map[string]string) (interface{}, error) {
+			link, err := s.st.GetLink(r.Context(), p["id"])
+			if err != nil {
+				return nil, err
+			}
+			return model.LinkRecord{ID: p["id"], NetworkLink: *link}, nil
+		})
+
+	s.handle("PUT", "/api/v1/links/{id}", "Create or replace a link", "links", model.NetworkLink{}, model.LinkRecord{},
+		func(r *http.Request, p map[string]string) (interface{}, error) {
+			var link model.NetworkLink
+			if err := decodeBody(r, &link); err != nil {
+				return nil, err
+			}
+			return s.putLink(r, p["id"], &link)
+		})
+
+	s.handle("PATCH", "/api/v1/links/{id}", "Change individual fields of a link", "links", model.LinkPatch{}, model.LinkRecord{},
+		func(r *http.Request, p map[string]string) (interface{}, error) {
+			var patch model.LinkPatch
+			if err := decodeBody(r, &patch); err != nil {
+				return nil, err
+			}
+			link, err := s.st.PatchLink(r.Context(), p["id"], &patch)
+			if err != nil {
+				return nil, err
+			}
+			return model.LinkRecord{ID: p["id"], NetworkLink: *link}, nil
+		})
+
+	s.handle("DELETE", "/api/v1/links/{id}", "Delete a link", "links", nil, nil,
+		func(r *http.Request, p map[string]string) (interface{}, error) {
+			if _, err := s.st.GetLink(r.Context(), p["id"]); err != nil {
+				return nil, err
+			}
+			return nil, s.st.DeleteLink(r.Context(), p["id"])
+		})
+
+	for _, state := range []string{model.AdminDown, model.AdminUp} {
+		state := state
+		s.handle("POST", "/api/v1/links/{id}/"+state, "Set the link admin state to "+state, "links",
+			linkStateRequest{}, model.LinkRecord{},
+			func(r *http.Request, p map[string]string) (interface{}, error) {
+				var req linkStateRequest
+				if r.ContentLength > 0 {
+					if err := decodeBody(r, &req); err != nil {
+						return nil, err
+					}
+				}
+				link, err := s.st.SetAdminState(r.Context(), p["id"], state, req.ApplyAt)
+				if err != nil {
+					return nil, err
+				}
+				return model.LinkRecord{ID: p["id"], NetworkLink: *link}, nil
+			})
+	}
+}
+
+// putLink 校验并写入完整链路
+func (s *Server) putLink(r *http.Request, id string, link *model.NetworkLink) (interface{}, error) {
+	if link.SourceMAC == "" && link.SourceContainer == "" {
+		return nil, badRequest("source_mac or source_container is required")
+	}
+	if link.AdminState != "" && link.AdminState != model.AdminUp && link.AdminState != model.AdminDown {
+		return nil, badRequest("admin_state must be up or down")
+	}
+	if link.CreatedAt == "" {
+		link.CreatedAt = time.Now().Format(time.RFC3339)
+	}
+	if err := s.st.SetLink(r.Context(), id, link, 0); err != nil {
+		return nil, err
+	}
+	return model.LinkRecord{ID: id, NetworkLink: *link}, nil
+}
+
+// intParam 解析路径中的整数参数
+func intParam(p map[string]string, name string) (int64, error) {
+	v, err := strconv.ParseInt(p[name], 10, 64)
+	if err != nil {
+		return 0, badRequest("invalid %s %q", name, p[name])
+	}
+	return v, nil
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+
+	"netsimlation/distribute/master_server/internal/model"
+)
+
+func (s *Server) registerNodeRoutes() {
+	s.handle("GET", "/api/v1/nodes", "List all nodes", "nodes", nil, []model.Node{},
+		func(r *http.Request, _ map[string]string) (interface{}, error) {
+			return s.st.ListNodes(r.Context())
+		})
+
+	s.handle("POST", "/api/v1/nodes", "Create or replace a node", "nodes", model.Node{}, model.Node{},
+		func(r *http.Request, _ map[string]string) (interface{}, error) {
+			var n model.Node
+			if err := decodeBody(r, &n); err != nil {
+				return nil, err
+			}
+			if n.ID <= 0 {
+				return nil, badRequest("node id must be positive")
+			}
+			return n, s.st.SetNode(r.Context(), &n)
+		})
+
+	s.handle("GET", "/api/v1/nodes/{id}", "Get a node", "nodes", nil, model.Node{},
+		func(r *http.Request, p map[string]string) (interface{}, error) {
+			id, err := intParam(p, "id")
+			if err != nil {
+				return nil, err
+			}
+			return s.st.GetNode(r.Context(), int(id))
+		})
+
+	s.handle("PUT", "/api/v1/nodes/{id}", "Create or replace a node", "nodes", model.Node{}, model.Node{},
+		func(r *http.Request, p map[string]string) (interface{}, error) {
+			id, err := intParam(p, "id")
+			if err != nil {
+				return nil, err
+			}
+			var n model.Node
+			if err := decodeBody(r, &n); err != nil {
+				return nil, err
+			}
+			n.ID = int(id)
+			return n, s.st.SetNode(r.Context(), &n)
+		})
+
+	s.handle("DELETE", "/api/v1/nodes/{id}", "Delete a node", "nodes", nil, nil,
+		func(r *http.Request, p map[string]string) (interface{}, error) {
+			id, err := intParam(p, "id")
+			if err != nil {
+				return nil, err
+			}
+			return nil, s.st.DeleteNode(r.Context(), int(id))
+		})
+}
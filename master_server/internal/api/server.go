@@ -0,0 +1,168 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"netsimlation/distribute/master_server/internal/store"
+)
+
+// Server 主控端的HTTP/JSON控制接口，所有状态都保存在Redis中，与命令行工具共用同一套键
+type Server struct {
+	st     *store.Store
+	routes []*route
+	exp    *experimentManager
+}
+
+// handlerFunc 处理请求并返回要编码为JSON的响应体，返回 nil 时响应 204
+type handlerFunc func(r *http.Request, params map[string]string) (interface{}, error)
+
+// route 一条API路由。Request/Response 为示例值，仅用于生成OpenAPI文档
+type route struct {
+	Method   string
+	Pattern  string
+	Summary  string
+	Tag      string
+	Request  interface{}
+	Response interface{}
+	handler  handlerFunc
+}
+
+// apiError 带HTTP状态码的错误
+type apiError struct {
+	Status  int
+	Message string
+}
+
+func (e *apiError) Error() string { return e.Message }
+
+func badRequest(format string, args ...interface{}) error {
+	return &apiError{Status: http.StatusBadRequest, Message: fmt.Sprintf(format, args...)}
+}
+
+// errorResponse 错误响应体
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewServer 创建API服务并注册全部路由
+func NewServer(st *store.Store) *Server {
+	s := &Server{st: st, exp: newExperimentManager(st)}
+	s.registerNodeRoutes()
+	s.registerLinkRoutes()
+	s.registerGenerationRoutes()
+	s.registerPartitionRoutes()
+	s.registerExperimentRoutes()
+	s.registerSlaveRoutes()
+	s.handle("GET", "/api/v1/openapi.json", "OpenAPI document of this API", "meta", nil, map[string]interface{}{},
+		func(r *http.Request, _ map[string]string) (interface{}, error) {
+			return s.OpenAPI(), nil
+		})
+	return s
+}
+
+func (s *Server) handle(method, pattern, summary, tag string, req, resp interface{}, h handlerFunc) {
+	s.routes = append(s.routes, &route{
+		Method: method, Pattern: pattern, Summary: summary, Tag: tag,
+		Request: req, Response: resp, handler: h,
+	})
+}
+
+// ServeHTTP 按方法与路径模板匹配路由，路径中的 {name} 段作为参数传给处理函数
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	pathMatched := false
+	for _, rt := range s.routes {
+		params, ok := matchPath(rt.Pattern, r.URL.Path)
+		if !ok {
+			continue
+		}
+		pathMatched = true
+		if rt.Method != r.Method {
+			continue
+		}
+
+		result, err := rt.handler(r, params)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if result == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+		return
+	}
+
+	if pathMatched {
+		writeJSON(w, http.StatusMethodNotAllowed, errorResponse{Error: "method not allowed"})
+		return
+	}
+	writeJSON(w, http.StatusNotFound, errorResponse{Error: "not found"})
+}
+
+// matchPath 匹配路径模板，返回模板中的参数
+func matchPath(pattern, path string) (map[string]string, bool) {
+	ps := strings.Split(strings.Trim(pattern, "/"), "/")
+	xs := strings.Split(strings.Trim(path, "/"), "/")
+	if len(ps) != len(xs) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, p := range ps {
+		if strings.HasPrefix(p, "{") && strings.HasSuffix(p, "}") {
+			if xs[i] == "" {
+				return nil, false
+			}
+			params[p[1:len(p)-1]] = xs[i]
+			continue
+		}
+		if p != xs[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// decodeBody 解析JSON请求体，拒绝未知字段以便尽早发现拼写错误
+func decodeBody(r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return badRequest("invalid request body: %v", err)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.Printf("编码响应失败: %v", err)
+	}
+}
+
+// writeError 将错误映射为HTTP状态码
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	var apiErr *apiError
+	switch {
+	case errors.As(err, &apiErr):
+		status = apiErr.Status
+	case errors.Is(err, store.ErrLinkNotFound), errors.Is(err, store.ErrNodeNotFound),
+		errors.Is(err, store.ErrNoGeneration):
+		status = http.StatusNotFound
+	case errors.Is(err, store.ErrPartitionActive), errors.Is(err, store.ErrNoPrevious):
+		status = http.StatusConflict
+	}
+	if status == http.StatusInternalServerError {
+		log.Printf("API请求处理失败: %v", err)
+	}
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"netsimlation/distribute/master_server/internal/model"
+	"netsimlation/distribute/master_server/internal/store"
+)
+
+// generationsResponse 当前生效代与激活历史
+type generationsResponse struct {
+	Active  int64   `json:"active"`
+	History []int64 `json:"history"`
+}
+
+// generationRequest 以一组链路创建新的配置代
+type generationRequest struct {
+	Links    map[string]model.NetworkLink `json:"links"`
+	Activate bool                         `json:"activate"` // 写入后立即激活
+}
+
+// generationResponse 新建或切换后的配置代
+type generationResponse struct {
+	Generation int64 `json:"generation"`
+	Active     bool  `json:"active"`
+}
+
+// partitionRequest 网络分区参数
+type partitionRequest struct {
+	Groups  []store.Group `json:"groups"`
+	ApplyAt int64         `json:"apply_at,omitempty"`
+}
+
+// partitionResponse 分区状态或分区/恢复操作影响的链路
+type partitionResponse struct {
+	Groups []store.Group `json:"groups"`
+	Links  []string      `json:"links,omitempty"`
+}
+
+func (s *Server) registerGenerationRoutes() {
+	s.handle("GET", "/api/v1/generations", "Show the active generation and activation history", "generations",
+		nil, generationsResponse{},
+		func(r *http.Request, _ map[string]string) (interface{}, error) {
+			active, err := s.st.ActiveGeneration(r.Context())
+			if err != nil {
+				return nil, err
+			}
+			history, err := s.st.History(r.Context())
+			if err != nil {
+				return nil, err
+			}
+			return generationsResponse{Active: active, History: history}, nil
+		})
+
+	s.handle("POST", "/api/v1/generations", "Write a complete link set as a new generation", "generations",
+		generationRequest{}, generationResponse{},
+		func(r *http.Request, _ map[string]string) (interface{}, error) {
+			var req generationRequest
+			if err := decodeBody(r, &req); err != nil {
+				return nil, err
+			}
+			gen, err := s.st.WriteGeneration(r.Context(), req.Links)
+			if err != nil {
+				return nil, err
+			}
+			if req.Activate {
+				if err := s.st.Activate(r.Context(), gen); err != nil {
+					return nil, err
+				}
+			}
+			return generationResponse{Generation: gen, Active: req.Activate}, nil
+		})
+
+	s.handle("POST", "/api/v1/generations/{gen}/activate", "Activate a generation", "generations",
+		nil, generationResponse{},
+		func(r *http.Request, p map[string]string) (interface{}, error) {
+			gen, err := intParam(p, "gen")
+			if err != nil {
+				return nil, err
+			}
+			if err := s.st.Activate(r.Context(), gen); err != nil {
+				return nil, err
+			}
+			return generationResponse{Generation: gen, Active: true}, nil
+		})
+
+	s.handle("POST", "/api/v1/generations/rollback", "Roll back to the previously active generation", "generations",
+		nil, generationResponse{},
+		func(r *http.Request, _ map[string]string) (interface{}, error) {
+			gen, err := s.st.Rollback(r.Context())
+			if err != nil {
+				return nil, err
+			}
+			return generationResponse{Generation: gen, Active: true}, nil
+		})
+}
+
+func (s *Server) registerPartitionRoutes() {
+	s.handle("GET", "/api/v1/partition", "Show the active partition", "partition", nil, partitionResponse{},
+		func(r *http.Request, _ map[string]string) (interface{}, error) {
+			groups, err := s.st.ActivePartition(r.Context())
+			if err != nil {
+				return nil, err
+			}
+			return partitionResponse{Groups: groups}, nil
+		})
+
+	s.handle("POST", "/api/v1/partition", "Partition the topology into named node groups", "partition",
+		partitionRequest{}, partitionResponse{},
+		func(r *http.Request, _ map[string]string) (interface{}, error) {
+			var req partitionRequest
+			if err := decodeBody(r, &req); err != nil {
+				return nil, err
+			}
+			if len(req.Groups) < 2 {
+				return nil, badRequest("a partition needs at least two groups")
+			}
+			cut, err := s.st.Partition(r.Context(), req.Groups, req.ApplyAt)
+			if err != nil {
+				return nil, err
+			}
+			return partitionResponse{Groups: req.Groups, Links: cut}, nil
+		})
+
+	s.handle("DELETE", "/api/v1/partition", "Heal the active partition; the optional apply_at query parameter schedules it", "partition",
+		nil, partitionResponse{},
+		func(r *http.Request, _ map[string]string) (interface{}, error) {
+			var applyAt int64
+			if v := r.URL.Query().Get("apply_at"); v != "" {
+				var err error
+				if applyAt, err = strconv.ParseInt(v, 10, 64); err != nil {
+					return nil, badRequest("invalid apply_at %q", v)
+				}
+			}
+			healed, err := s.st.Heal(r.Context(), applyAt)
+			if err != nil {
+				return nil, err
+			}
+			return partitionResponse{Links: healed}, nil
+		})
+}
+
+func (s *Server) registerSlaveRoutes() {
+	s.handle("GET", "/api/v1/slaves", "List online slaves and their status", "slaves", nil, []store.SlaveStatus{},
+		func(r *http.Request, _ map[string]string) (interface{}, error) {
+			return s.st.ListSlaves(r.Context())
+		})
+
+	s.handle("GET", "/api/v1/slaves/{node}", "Get the status of one slave", "slaves", nil, store.SlaveStatus{},
+		func(r *http.Request, p map[string]string) (interface{}, error) {
+			st, err := s.st.GetSlave(r.Context(), p["node"])
+			if err != nil {
+				return nil, err
+			}
+			if st == nil {
+				return nil, &apiError{Status: http.StatusNotFound, Message: "slave " + p["node"] + " is offline"}
+			}
+			return st, nil
+		})
+}
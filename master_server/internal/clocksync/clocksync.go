@@ -0,0 +1,126 @@
+package clocksync
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 时钟同步与生效偏差上报使用的频道。
+// 从节点向 RequestChannel 发送带本地时间 t0 的请求，主控端记录收到时间 t1 与发送时间 t2
+// 后回复到 ResponseChannelPrefix+<node>，从节点记录 t3 并按NTP方式估计偏移。
+const (
+	RequestChannel        = "netsim:clock:req"
+	ResponseChannelPrefix = "netsim:clock:resp:"
+	ReportChannel         = "netsim:apply:report"
+	SkewKeyPrefix         = "netsim:apply:skew:"
+)
+
+// Request 从节点发起的时钟同步请求，时间均为Unix纳秒
+type Request struct {
+	Node string `json:"node"`
+	Seq  int    `json:"seq"`
+	T0   int64  `json:"t0"`
+}
+
+// Response 主控端的回复
+type Response struct {
+	Seq int   `json:"seq"`
+	T0  int64 `json:"t0"`
+	T1  int64 `json:"t1"`
+	T2  int64 `json:"t2"`
+}
+
+// ApplyReport 从节点应用定时变更后上报的偏差
+type ApplyReport struct {
+	Node      string  `json:"node"`
+	Link      string  `json:"link"`
+	ApplyAt   int64   `json:"apply_at"`   // 计划生效时刻（主控时钟，Unix毫秒）
+	AppliedAt int64   `json:"applied_at"` // 实际生效时刻（换算到主控时钟，Unix毫秒）
+	SkewMs    float64 `json:"skew_ms"`    // 实际减计划，正值表示晚于计划
+	OffsetMs  float64 `json:"offset_ms"`  // 当前估计的时钟偏移（主控减从节点）
+}
+
+// Serve 作为参考时钟响应从节点的同步请求，直到上下文取消
+func Serve(ctx context.Context, client *redis.Client) error {
+	pubsub := client.Subscribe(ctx, RequestChannel)
+	defer pubsub.Close()
+
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return err
+	}
+	log.Printf("时钟同步服务已启动，监听频道 %s", RequestChannel)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-pubsub.Channel():
+			if !ok {
+				return nil
+			}
+			t1 := time.Now().UnixNano()
+
+			var req Request
+			if err := json.Unmarshal([]byte(msg.Payload), &req); err != nil || req.Node == "" {
+				log.Printf("忽略无效的时钟同步请求: %s", msg.Payload)
+				continue
+			}
+			resp := Response{Seq: req.Seq, T0: req.T0, T1: t1, T2: time.Now().UnixNano()}
+			data, _ := json.Marshal(resp)
+			if err := client.Publish(ctx, ResponseChannelPrefix+req.Node, data).Err(); err != nil {
+				log.Printf("回复时钟同步请求失败 (%s): %v", req.Node, err)
+			}
+		}
+	}
+}
+
+// WatchReports 订阅从节点的生效偏差上报并交给 fn 处理
+func WatchReports(ctx context.Context, client *redis.Client, fn func(ApplyReport)) error {
+	pubsub := client.Subscribe(ctx, ReportChannel)
+	defer pubsub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-pubsub.Channel():
+			if !ok {
+				return nil
+			}
+			var report ApplyReport
+			if err := json.Unmarshal([]byte(msg.Payload), &report); err != nil {
+				log.Printf("忽略无效的偏差上报: %s", msg.Payload)
+				continue
+			}
+			fn(report)
+		}
+	}
+}
+
+// LogReport 打印一条生效偏差上报，可直接作为 WatchReports 的回调
+func LogReport(r ApplyReport) {
+	log.Printf("从节点 %s 链路 %s 生效偏差 %.3fms（时钟偏移 %.3fms）", r.Node, r.Link, r.SkewMs, r.OffsetMs)
+}
+
+// PublishReport 代替通过gRPC接入的从节点发布偏差上报，并记录到 SkewKeyPrefix+<node>
+func PublishReport(ctx context.Context, client *redis.Client, r ApplyReport) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	pipe := client.Pipeline()
+	pipe.Publish(ctx, ReportChannel, data)
+	pipe.HSet(ctx, SkewKeyPrefix+r.Node, map[string]interface{}{
+		"link":       r.Link,
+		"apply_at":   r.ApplyAt,
+		"applied_at": r.AppliedAt,
+		"skew_ms":    r.SkewMs,
+		"offset_ms":  r.OffsetMs,
+	})
+	_, err = pipe.Exec(ctx)
+	return err
+}
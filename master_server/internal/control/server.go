@@ -0,0 +1,279 @@
+package control
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"netsimlation/distribute/master_server/internal/clocksync"
+	"netsimlation/distribute/master_server/internal/controlpb"
+	"netsimlation/distribute/master_server/internal/model"
+	"netsimlation/distribute/master_server/internal/store"
+)
+
+const (
+	// queueSize 单个从节点待发送推送的缓冲，写满说明从节点处理过慢，断开后由其重连并重新同步
+	queueSize = 1024
+	// defaultStatusTTL 从节点未声明 ttl_ms 时状态键的过期时间
+	defaultStatusTTL = 15 * time.Second
+)
+
+// Server gRPC控制服务。链路配置仍以Redis为准：服务监听Redis键空间事件并推送给已连接的从节点，
+// 从节点的状态与偏差上报写回Redis，因此REST接口与命令行工具无需区分从节点的接入方式。
+type Server struct {
+	controlpb.UnimplementedControlServer
+
+	st *store.Store
+
+	mu     sync.Mutex
+	slaves map[*slaveConn]struct{}
+}
+
+// slaveConn 一个已注册的从节点控制流
+type slaveConn struct {
+	node  string
+	queue chan *controlpb.MasterMessage
+	// overflow 在推送缓冲写满时关闭，使 Connect 返回并断开该从节点
+	overflow chan struct{}
+	once     sync.Once
+}
+
+func (c *slaveConn) push(msg *controlpb.MasterMessage) {
+	select {
+	case c.queue <- msg:
+	default:
+		c.once.Do(func() {
+			log.Printf("从节点 %s 推送缓冲已满，断开连接", c.node)
+			close(c.overflow)
+		})
+	}
+}
+
+func NewServer(st *store.Store) *Server {
+	return &Server{
+		st:     st,
+		slaves: make(map[*slaveConn]struct{}),
+	}
+}
+
+// Connect 处理从节点的控制流：注册后先推送当前生效代与全部链路，再转发后续变更
+func (s *Server) Connect(stream controlpb.Control_ConnectServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	reg := first.GetRegister()
+	if reg == nil || reg.Node == "" {
+		return status.Error(codes.InvalidArgument, "first message must be a register with a node id")
+	}
+
+	conn := &slaveConn{
+		node:     reg.Node,
+		queue:    make(chan *controlpb.MasterMessage, queueSize),
+		overflow: make(chan struct{}),
+	}
+	// 先登记再读取快照：快照之后到达的变更在缓冲中排队，随后按序发送，不会丢失
+	s.mu.Lock()
+	s.slaves[conn] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.slaves, conn)
+		s.mu.Unlock()
+		log.Printf("从节点 %s 已断开", reg.Node)
+	}()
+	log.Printf("从节点 %s 已连接 (version %s, iface %s)", reg.Node, reg.Version, reg.Iface)
+
+	ctx := stream.Context()
+	if err := s.sendSnapshot(ctx, stream); err != nil {
+		return err
+	}
+
+	recvErr := make(chan error, 1)
+	go func() {
+		recvErr <- s.receive(ctx, reg.Node, stream)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-recvErr:
+			return err
+		case <-conn.overflow:
+			return status.Error(codes.ResourceExhausted, "push queue overflow, reconnect to resync")
+		case msg := <-conn.queue:
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// sendSnapshot 推送当前生效代与全部链路，最后以 SnapshotDone 结束。
+// 已过期的 apply_at 被清除，避免从节点上报无意义的偏差。
+func (s *Server) sendSnapshot(ctx context.Context, stream controlpb.Control_ConnectServer) error {
+	gen, err := s.st.ActiveGeneration(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "read active generation: %v", err)
+	}
+	if gen > 0 {
+		if err := stream.Send(generationMessage(gen)); err != nil {
+			return err
+		}
+	}
+
+	links, err := s.st.ListLinks(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "list links: %v", err)
+	}
+	now := time.Now().UnixMilli()
+	for i := range links {
+		if links[i].ApplyAt <= now {
+			links[i].ApplyAt = 0
+		}
+		if err := stream.Send(linkUpdateMessage(links[i].ID, &links[i].NetworkLink)); err != nil {
+			return err
+		}
+	}
+	return stream.Send(&controlpb.MasterMessage{Msg: &controlpb.MasterMessage_SnapshotDone{
+		SnapshotDone: &controlpb.SnapshotDone{},
+	}})
+}
+
+// receive 处理从节点上报，状态与偏差写回Redis
+func (s *Server) receive(ctx context.Context, node string, stream controlpb.Control_ConnectServer) error {
+	for {
+		msg, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch m := msg.Msg.(type) {
+		case *controlpb.SlaveMessage_Status:
+			st := m.Status
+			ttl := time.Duration(st.TtlMs) * time.Millisecond
+			if ttl <= 0 {
+				ttl = defaultStatusTTL
+			}
+			err = s.st.SetSlave(ctx, &store.SlaveStatus{
+				Node:       node,
+				Version:    st.Version,
+				Iface:      st.Iface,
+				AppliedGen: st.AppliedGen,
+				Links:      int(st.Links),
+				OffsetMs:   st.OffsetMs,
+				ClockSync:  st.ClockSynced,
+				LastSeen:   time.UnixMilli(st.LastSeen),
+			}, ttl)
+		case *controlpb.SlaveMessage_ApplyReport:
+			r := m.ApplyReport
+			err = clocksync.PublishReport(ctx, s.st.Client(), clocksync.ApplyReport{
+				Node:      node,
+				Link:      r.Link,
+				ApplyAt:   r.ApplyAt,
+				AppliedAt: r.AppliedAt,
+				SkewMs:    r.SkewMs,
+				OffsetMs:  r.OffsetMs,
+			})
+		}
+		if err != nil && ctx.Err() == nil {
+			log.Printf("记录从节点 %s 的上报失败: %v", node, err)
+		}
+	}
+}
+
+// GetGeneration 返回某一代的链路集合，generation 为0时返回当前生效代
+func (s *Server) GetGeneration(ctx context.Context, req *controlpb.GenerationRequest) (*controlpb.Generation, error) {
+	gen := req.Generation
+	if gen == 0 {
+		active, err := s.st.ActiveGeneration(ctx)
+		if err != nil {
+			return nil, status.Errorf(codes.Unavailable, "read active generation: %v", err)
+		}
+		gen = active
+	}
+	resp := &controlpb.Generation{Generation: gen}
+	if gen == 0 || req.HeaderOnly {
+		return resp, nil
+	}
+
+	links, err := s.st.LoadGeneration(ctx, gen)
+	if errors.Is(err, store.ErrNoGeneration) {
+		return nil, status.Errorf(codes.NotFound, "generation %d does not exist", gen)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "load generation %d: %v", gen, err)
+	}
+	ids := make([]string, 0, len(links))
+	for id := range links {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		l := links[id]
+		resp.Links = append(resp.Links, toPB(id, &l))
+	}
+	return resp, nil
+}
+
+// SyncClock 记录收到请求与发出回复的时间，从节点据此估计时钟偏移
+func (s *Server) SyncClock(ctx context.Context, req *controlpb.ClockRequest) (*controlpb.ClockResponse, error) {
+	t1 := time.Now().UnixNano()
+	return &controlpb.ClockResponse{Seq: req.Seq, T0: req.T0, T1: t1, T2: time.Now().UnixNano()}, nil
+}
+
+// broadcast 将推送投递给所有已连接的从节点
+func (s *Server) broadcast(msg *controlpb.MasterMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.slaves {
+		conn.push(msg)
+	}
+}
+
+func toPB(id string, l *model.NetworkLink) *controlpb.Link {
+	return &controlpb.Link{
+		Id:             id,
+		SourceMac:      l.SourceMAC,
+		SourceNodeId:   int32(l.SourceNodeID),
+		DestNodeId:     int32(l.DestNodeID),
+		PacketLossRate: l.PacketLossRate,
+		BandwidthBps:   l.BandwidthBps,
+		DelayMs:        l.DelayMs,
+		CreatedAt:      l.CreatedAt,
+		ApplyAt:        l.ApplyAt,
+		AdminState:     l.AdminState,
+
+		SourceContainer: l.SourceContainer,
+		DestContainer:   l.DestContainer,
+	}
+}
+
+func linkUpdateMessage(id string, l *model.NetworkLink) *controlpb.MasterMessage {
+	return &controlpb.MasterMessage{Msg: &controlpb.MasterMessage_LinkUpdate{
+		LinkUpdate: &controlpb.LinkUpdate{Link: toPB(id, l)},
+	}}
+}
+
+func linkDeleteMessage(id string) *controlpb.MasterMessage {
+	return &controlpb.MasterMessage{Msg: &controlpb.MasterMessage_LinkDelete{
+		LinkDelete: &controlpb.LinkDelete{Id: id},
+	}}
+}
+
+func generationMessage(gen int64) *controlpb.MasterMessage {
+	return &controlpb.MasterMessage{Msg: &controlpb.MasterMessage_GenerationActivated{
+		GenerationActivated: &controlpb.GenerationActivated{Generation: gen},
+	}}
+}
@@ -0,0 +1,100 @@
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+
+	"netsimlation/distribute/master_server/internal/model"
+	"netsimlation/distribute/master_server/internal/store"
+)
+
+// Watch 订阅Redis键空间事件，将链路与生效代的变更推送给通过gRPC接入的从节点。
+// 与从节点直接订阅Redis相同，要求Redis开启 notify-keyspace-events（至少包含 E$gx）。
+func (s *Server) Watch(ctx context.Context) error {
+	client := s.st.Client()
+	db := client.Options().DB
+	channels := []string{
+		fmt.Sprintf("__keyevent@%d__:set", db),
+		fmt.Sprintf("__keyevent@%d__:del", db),
+		fmt.Sprintf("__keyevent@%d__:expired", db),
+	}
+
+	if cfg, err := client.ConfigGet(ctx, "notify-keyspace-events").Result(); err == nil && len(cfg) == 2 {
+		if v, _ := cfg[1].(string); v == "" {
+			log.Printf("警告: Redis未开启 notify-keyspace-events，gRPC从节点将收不到链路变更")
+		}
+	}
+
+	pubsub := client.Subscribe(ctx, channels...)
+	defer pubsub.Close()
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return err
+	}
+	log.Printf("开始转发Redis键空间事件到gRPC从节点: %v", channels)
+
+	// 逐条顺序处理，保证同一链路的变更按发生顺序推送
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			event := msg.Channel[strings.LastIndex(msg.Channel, ":")+1:]
+			if err := s.forward(ctx, event, msg.Payload); err != nil && ctx.Err() == nil {
+				log.Printf("转发事件失败 - 键: %s: %v", msg.Payload, err)
+			}
+		}
+	}
+}
+
+// forward 将一条键事件转换为推送
+func (s *Server) forward(ctx context.Context, event, key string) error {
+	if key == store.GenActiveKey {
+		if event != "set" {
+			return nil
+		}
+		val, err := s.st.Client().Get(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		gen, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return err
+		}
+		s.broadcast(generationMessage(gen))
+		return nil
+	}
+
+	if !strings.HasPrefix(key, model.LinkKeyPrefix) {
+		return nil
+	}
+	id := strings.TrimPrefix(key, model.LinkKeyPrefix)
+	switch event {
+	case "set":
+		val, err := s.st.Client().Get(ctx, key).Result()
+		if err == redis.Nil {
+			// 读取前已被删除，随后的 del 事件会处理
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var link model.NetworkLink
+		if err := json.Unmarshal([]byte(val), &link); err != nil {
+			return fmt.Errorf("decode link %s: %w", id, err)
+		}
+		s.broadcast(linkUpdateMessage(id, &link))
+	case "del", "expired":
+		s.broadcast(linkDeleteMessage(id))
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+// 主控端与从节点之间的gRPC控制面，用于无法部署Redis或从节点无法直连Redis的环境。
+// 消息字段与Redis中 network_link:<id>、netsim:slave:<node> 等键的内容一一对应，
+// 两种传输方式可以在同一实验中混用。
+//
+// 修改后重新生成两侧代码：
+//   master_server/internal/controlpb 与 slave_server/redis_listener/internal/controlpb
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: control.proto
+
+package controlpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Control_Connect_FullMethodName       = "/netsim.control.v1.Control/Connect"
+	Control_GetGeneration_FullMethodName = "/netsim.control.v1.Control/GetGeneration"
+	Control_SyncClock_FullMethodName     = "/netsim.control.v1.Control/SyncClock"
+)
+
+// ControlClient is the client API for Control service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ControlClient interface {
+	// Connect 从节点建立的双向流：首条消息必须是 Register，
+	// 之后主控端推送链路变更，从节点上报状态与定时变更的生效偏差
+	Connect(ctx context.Context, opts ...grpc.CallOption) (Control_ConnectClient, error)
+	// GetGeneration 读取某一代的完整链路集合，generation 为0时读取当前生效代
+	GetGeneration(ctx context.Context, in *GenerationRequest, opts ...grpc.CallOption) (*Generation, error)
+	// SyncClock 一次NTP式时间戳交换，与Redis频道 netsim:clock:req 的语义相同
+	SyncClock(ctx context.Context, in *ClockRequest, opts ...grpc.CallOption) (*ClockResponse, error)
+}
+
+type controlClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewControlClient(cc grpc.ClientConnInterface) ControlClient {
+	return &controlClient{cc}
+}
+
+func (c *controlClient) Connect(ctx context.Context, opts ...grpc.CallOption) (Control_ConnectClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Control_ServiceDesc.Streams[0], Control_Connect_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &controlConnectClient{stream}
+	return x, nil
+}
+
+type Control_ConnectClient interface {
+	Send(*SlaveMessage) error
+	Recv() (*MasterMessage, error)
+	grpc.ClientStream
+}
+
+type controlConnectClient struct {
+	grpc.ClientStream
+}
+
+func (x *controlConnectClient) Send(m *SlaveMessage) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *controlConnectClient) Recv() (*MasterMessage, error) {
+	m := new(MasterMessage)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *controlClient) GetGeneration(ctx context.Context, in *GenerationRequest, opts ...grpc.CallOption) (*Generation, error) {
+	out := new(Generation)
+	err := c.cc.Invoke(ctx, Control_GetGeneration_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *controlClient) SyncClock(ctx context.Context, in *ClockRequest, opts ...grpc.CallOption) (*ClockResponse, error) {
+	out := new(ClockResponse)
+	err := c.cc.Invoke(ctx, Control_SyncClock_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ControlServer is the server API for Control service.
+// All implementations must embed UnimplementedControlServer
+// for forward compatibility
+type ControlServer interface {
+	// Connect 从节点建立的双向流：首条消息必须是 Register，
+	// 之后主控端推送链路变更，从节点上报状态与定时变更的生效偏差
+	Connect(Control_ConnectServer) error
+	// GetGeneration 读取某一代的完整链路集合，generation 为0时读取当前生效代
+	GetGeneration(context.Context, *GenerationRequest) (*Generation, error)
+	// SyncClock 一次NTP式时间戳交换，与Redis频道 netsim:clock:req 的语义相同
+	SyncClock(context.Context, *ClockRequest) (*ClockResponse, error)
+	mustEmbedUnimplementedControlServer()
+}
+
+// UnimplementedControlServer must be embedded to have forward compatible implementations.
+type UnimplementedControlServer struct {
+}
+
+func (UnimplementedControlServer) Connect(Control_ConnectServer) error {
+	return status.Errorf(codes.Unimplemented, "method Connect not implemented")
+}
+func (UnimplementedControlServer) GetGeneration(context.Context, *GenerationRequest) (*Generation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetGeneration not implemented")
+}
+func (UnimplementedControlServer) SyncClock(context.Context, *ClockRequest) (*ClockResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SyncClock not implemented")
+}
+func (UnimplementedControlServer) mustEmbedUnimplementedControlServer() {}
+
+// UnsafeControlServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ControlServer will
+// result in compilation errors.
+type UnsafeControlServer interface {
+	mustEmbedUnimplementedControlServer()
+}
+
+func RegisterControlServer(s grpc.ServiceRegistrar, srv ControlServer) {
+	s.RegisterService(&Control_ServiceDesc, srv)
+}
+
+func _Control_Connect_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ControlServer).Connect(&controlConnectServer{stream})
+}
+
+type Control_ConnectServer interface {
+	Send(*MasterMessage) error
+	Recv() (*SlaveMessage, error)
+	grpc.ServerStream
+}
+
+type controlConnectServer struct {
+	grpc.ServerStream
+}
+
+func (x *controlConnectServer) Send(m *MasterMessage) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *controlConnectServer) Recv() (*SlaveMessage, error) {
+	m := new(SlaveMessage)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Control_GetGeneration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GenerationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).GetGeneration(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Control_GetGeneration_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServer).GetGeneration(ctx, req.(*GenerationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Control_SyncClock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ControlServer).SyncClock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Control_SyncClock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ControlServer).SyncClock(ctx, req.(*ClockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Control_ServiceDesc is the grpc.ServiceDesc for Control service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Control_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "netsim.control.v1.Control",
+	HandlerType: (*ControlServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetGeneration",
+			Handler:    _Control_GetGeneration_Handler,
+		},
+		{
+			MethodName: "SyncClock",
+			Handler:    _Control_SyncClock_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Connect",
+			Handler:       _Control_Connect_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "control.proto",
+}
@@ -0,0 +1,286 @@
+package ctl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"netsimlation/distribute/master_server/internal/model"
+	"netsimlation/distribute/master_server/internal/scenario"
+	"netsimlation/distribute/master_server/internal/store"
+)
+
+// apiBackend 通过 master-server 的HTTP接口操作
+type apiBackend struct {
+	base   string
+	client *http.Client
+}
+
+func newAPIBackend(server string, timeout time.Duration) *apiBackend {
+	return &apiBackend{
+		base:   strings.TrimRight(server, "/") + "/api/v1",
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// httpError 接口返回的错误
+type httpError struct {
+	Status  int
+	Message string
+}
+
+func (e *httpError) Error() string {
+	return fmt.Sprintf("%s (HTTP %d)", e.Message, e.Status)
+}
+
+// do 发送请求并解码响应，in 为 nil 时不带请求体，out 为 nil 时丢弃响应体
+func (b *apiBackend) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, b.base+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var e struct {
+			Error string `json:"error"`
+		}
+		data, _ := io.ReadAll(resp.Body)
+		if json.Unmarshal(data, &e) != nil || e.Error == "" {
+			e.Error = strings.TrimSpace(string(data))
+		}
+		return &httpError{Status: resp.StatusCode, Message: e.Error}
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// notFound 将404转换为与直连Redis相同的哨兵错误，便于命令统一判断
+func notFound(err error, sentinel error, id string) error {
+	var he *httpError
+	if errors.As(err, &he) && he.Status == http.StatusNotFound {
+		return fmt.Errorf("%w: %s", sentinel, id)
+	}
+	return err
+}
+
+func (b *apiBackend) ListLinks(ctx context.Context) ([]model.LinkRecord, error) {
+	var links []model.LinkRecord
+	return links, b.do(ctx, http.MethodGet, "/links", nil, &links)
+}
+
+func (b *apiBackend) GetLink(ctx context.Context, id string) (*model.NetworkLink, error) {
+	var rec model.LinkRecord
+	if err := b.do(ctx, http.MethodGet, "/links/"+url.PathEscape(id), nil, &rec); err != nil {
+		return nil, notFound(err, store.ErrLinkNotFound, id)
+	}
+	return &rec.NetworkLink, nil
+}
+
+func (b *apiBackend) PutLink(ctx context.Context, id string, link *model.NetworkLink) error {
+	return b.do(ctx, http.MethodPut, "/links/"+url.PathEscape(id), link, nil)
+}
+
+func (b *apiBackend) PatchLink(ctx context.Context, id string, patch *model.LinkPatch) (*model.NetworkLink, error) {
+	var rec model.LinkRecord
+	if err := b.do(ctx, http.MethodPatch, "/links/"+url.PathEscape(id), patch, &rec); err != nil {
+		return nil, notFound(err, store.ErrLinkNotFound, id)
+	}
+	return &rec.NetworkLink, nil
+}
+
+func (b *apiBackend) DeleteLink(ctx context.Context, id string) error {
+	return notFound(b.do(ctx, http.MethodDelete, "/links/"+url.PathEscape(id), nil, nil), store.ErrLinkNotFound, id)
+}
+
+func (b *apiBackend) SetAdminState(ctx context.Context, id, state string, applyAt int64) (*model.NetworkLink, error) {
+	var rec model.LinkRecord
+	in := map[string]int64{"apply_at": applyAt}
+	if err := b.do(ctx, http.MethodPost, "/links/"+url.PathEscape(id)+"/"+state, in, &rec); err != nil {
+		return nil, notFound(err, store.ErrLinkNotFound, id)
+	}
+	return &rec.NetworkLink, nil
+}
+
+func (b *apiBackend) ListNodes(ctx context.Context) ([]model.Node, error) {
+	var nodes []model.Node
+	return nodes, b.do(ctx, http.MethodGet, "/nodes", nil, &nodes)
+}
+
+func (b *apiBackend) SetNode(ctx context.Context, n *model.Node) error {
+	return b.do(ctx, http.MethodPut, "/nodes/"+strconv.Itoa(n.ID), n, nil)
+}
+
+func (b *apiBackend) WriteGeneration(ctx context.Context, links map[string]model.NetworkLink) (int64, error) {
+	var resp struct {
+		Generation int64 `json:"generation"`
+	}
+	in := map[string]interface{}{"links": links, "activate": false}
+	return resp.Generation, b.do(ctx, http.MethodPost, "/generations", in, &resp)
+}
+
+func (b *apiBackend) Activate(ctx context.Context, gen int64) error {
+	return b.do(ctx, http.MethodPost, fmt.Sprintf("/generations/%d/activate", gen), nil, nil)
+}
+
+func (b *apiBackend) Rollback(ctx context.Context) (int64, error) {
+	var resp struct {
+		Generation int64 `json:"generation"`
+	}
+	return resp.Generation, b.do(ctx, http.MethodPost, "/generations/rollback", nil, &resp)
+}
+
+func (b *apiBackend) generations(ctx context.Context) (int64, []int64, error) {
+	var resp struct {
+		Active  int64   `json:"active"`
+		History []int64 `json:"history"`
+	}
+	err := b.do(ctx, http.MethodGet, "/generations", nil, &resp)
+	return resp.Active, resp.History, err
+}
+
+func (b *apiBackend) ActiveGeneration(ctx context.Context) (int64, error) {
+	active, _, err := b.generations(ctx)
+	return active, err
+}
+
+func (b *apiBackend) History(ctx context.Context) ([]int64, error) {
+	_, history, err := b.generations(ctx)
+	return history, err
+}
+
+type partitionResponse struct {
+	Groups []store.Group `json:"groups"`
+	Links  []string      `json:"links"`
+}
+
+func (b *apiBackend) Partition(ctx context.Context, groups []store.Group, applyAt int64) ([]string, error) {
+	var resp partitionResponse
+	in := map[string]interface{}{"groups": groups, "apply_at": applyAt}
+	return resp.Links, b.do(ctx, http.MethodPost, "/partition", in, &resp)
+}
+
+func (b *apiBackend) Heal(ctx context.Context, applyAt int64) ([]string, error) {
+	var resp partitionResponse
+	path := "/partition"
+	if applyAt > 0 {
+		path += "?apply_at=" + strconv.FormatInt(applyAt, 10)
+	}
+	return resp.Links, b.do(ctx, http.MethodDelete, path, nil, &resp)
+}
+
+func (b *apiBackend) ActivePartition(ctx context.Context) ([]store.Group, error) {
+	var resp partitionResponse
+	return resp.Groups, b.do(ctx, http.MethodGet, "/partition", nil, &resp)
+}
+
+func (b *apiBackend) ListSlaves(ctx context.Context) ([]store.SlaveStatus, error) {
+	var slaves []store.SlaveStatus
+	return slaves, b.do(ctx, http.MethodGet, "/slaves", nil, &slaves)
+}
+
+func (b *apiBackend) GetSlave(ctx context.Context, node string) (*store.SlaveStatus, error) {
+	var st store.SlaveStatus
+	err := b.do(ctx, http.MethodGet, "/slaves/"+url.PathEscape(node), nil, &st)
+	var he *httpError
+	if errors.As(err, &he) && he.Status == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// experimentStatus 与 master-server 的 /experiment 响应一致
+type experimentStatus struct {
+	Name      string `json:"name"`
+	State     string `json:"state"`
+	Next      int    `json:"next"`
+	Total     int    `json:"total"`
+	LastError string `json:"last_error"`
+}
+
+// RunScenario 将时间线提交给 master-server 执行并轮询进度，ctx 取消时中止实验
+func (b *apiBackend) RunScenario(ctx context.Context, sc *scenario.Scenario, start time.Time, lead time.Duration) error {
+	in := map[string]interface{}{
+		"scenario": sc,
+		"start":    start,
+		"lead_ms":  lead.Milliseconds(),
+	}
+	var st experimentStatus
+	if err := b.do(ctx, http.MethodPost, "/experiment", in, &st); err != nil {
+		return err
+	}
+	log.Printf("场景 %q 已提交到主控服务，共 %d 个事件", sc.Name, st.Total)
+
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	last := -1
+	for {
+		select {
+		case <-ctx.Done():
+			// 使用独立的上下文发送中止请求，原上下文已取消
+			abortCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := b.do(abortCtx, http.MethodDelete, "/experiment", nil, nil); err != nil {
+				return fmt.Errorf("abort experiment: %w", err)
+			}
+			return scenario.ErrAborted
+		case <-ticker.C:
+		}
+
+		if err := b.do(ctx, http.MethodGet, "/experiment", nil, &st); err != nil {
+			if ctx.Err() != nil {
+				continue
+			}
+			return err
+		}
+		if st.Next != last {
+			log.Printf("场景进度 %d/%d (%s)", st.Next, st.Total, st.State)
+			last = st.Next
+		}
+		switch scenario.State(st.State) {
+		case scenario.StateFinished:
+			return nil
+		case scenario.StateAborted:
+			return scenario.ErrAborted
+		}
+		// 执行器因错误退出时状态停留在 running，以 last_error 判断
+		if st.LastError != "" {
+			return errors.New(st.LastError)
+		}
+	}
+}
+
+func (b *apiBackend) Close() error {
+	b.client.CloseIdleConnections()
+	return nil
+}
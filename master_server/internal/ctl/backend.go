@@ -0,0 +1,92 @@
+package ctl
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"netsimlation/distribute/master_server/internal/clocksync"
+	"netsimlation/distribute/master_server/internal/model"
+	"netsimlation/distribute/master_server/internal/scenario"
+	"netsimlation/distribute/master_server/internal/store"
+)
+
+// backend 子命令对主控端状态的操作，分别由直连Redis与调用 master-server 接口实现
+type backend interface {
+	ListLinks(ctx context.Context) ([]model.LinkRecord, error)
+	GetLink(ctx context.Context, id string) (*model.NetworkLink, error)
+	PutLink(ctx context.Context, id string, link *model.NetworkLink) error
+	PatchLink(ctx context.Context, id string, patch *model.LinkPatch) (*model.NetworkLink, error)
+	DeleteLink(ctx context.Context, id string) error
+	SetAdminState(ctx context.Context, id, state string, applyAt int64) (*model.NetworkLink, error)
+
+	ListNodes(ctx context.Context) ([]model.Node, error)
+	SetNode(ctx context.Context, n *model.Node) error
+
+	WriteGeneration(ctx context.Context, links map[string]model.NetworkLink) (int64, error)
+	Activate(ctx context.Context, gen int64) error
+	Rollback(ctx context.Context) (int64, error)
+	ActiveGeneration(ctx context.Context) (int64, error)
+	History(ctx context.Context) ([]int64, error)
+
+	Partition(ctx context.Context, groups []store.Group, applyAt int64) ([]string, error)
+	Heal(ctx context.Context, applyAt int64) ([]string, error)
+	ActivePartition(ctx context.Context) ([]store.Group, error)
+
+	ListSlaves(ctx context.Context) ([]store.SlaveStatus, error)
+	GetSlave(ctx context.Context, node string) (*store.SlaveStatus, error)
+
+	// RunScenario 执行时间线并阻塞到结束，ctx 取消时中止实验
+	RunScenario(ctx context.Context, sc *scenario.Scenario, start time.Time, lead time.Duration) error
+
+	Close() error
+}
+
+func newBackend(ctx context.Context, opts *options) (backend, error) {
+	if opts.server != "" {
+		return newAPIBackend(opts.server, opts.timeout), nil
+	}
+
+	st := store.New(store.Options{
+		Addr:     opts.redisAddr,
+		Password: opts.redisPassword,
+		DB:       opts.redisDB,
+	})
+	pingCtx, cancel := context.WithTimeout(ctx, opts.timeout)
+	defer cancel()
+	if err := st.Ping(pingCtx); err != nil {
+		st.Close()
+		return nil, fmt.Errorf("connect to redis %s: %w", opts.redisAddr, err)
+	}
+	return &redisBackend{Store: st}, nil
+}
+
+// redisBackend 直接读写Redis
+type redisBackend struct {
+	*store.Store
+}
+
+func (b *redisBackend) PutLink(ctx context.Context, id string, link *model.NetworkLink) error {
+	return b.Store.SetLink(ctx, id, link, 0)
+}
+
+// RunScenario 在本进程中执行时间线，与 scenario-runner 相同；启用 lead 时同时作为参考时钟
+func (b *redisBackend) RunScenario(ctx context.Context, sc *scenario.Scenario, start time.Time, lead time.Duration) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	runner := scenario.NewRunner(b.Store, sc, start)
+	go runner.ListenControl(ctx)
+
+	if lead > 0 {
+		runner.SetLead(lead)
+		go func() {
+			if err := clocksync.Serve(ctx, b.Client()); err != nil {
+				log.Printf("时钟同步服务退出: %v", err)
+			}
+		}()
+		go clocksync.WatchReports(ctx, b.Client(), clocksync.LogReport)
+	}
+	return runner.Run(ctx)
+}
@@ -0,0 +1,129 @@
+// Package ctl 实现 netsimctl 的子命令。命令既可以直接读写Redis，
+// 也可以通过 -server 指向 master-server 的HTTP接口，两种方式的输出一致。
+package ctl
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+)
+
+// command 一个子命令，run 负责定义并解析自己的参数
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(c *cmdContext) error
+}
+
+// group 一组子命令，如 link、node
+type group struct {
+	name    string
+	summary string
+	cmds    []*command
+}
+
+var groups []*group
+
+func register(g *group) {
+	groups = append(groups, g)
+	sort.Slice(groups, func(i, j int) bool { return groups[i].name < groups[j].name })
+}
+
+// errUsage 参数错误，已打印用法
+var errUsage = errors.New("usage error")
+
+// Main 执行命令行并返回退出码
+func Main(args []string) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(os.Stdout)
+		return 0
+	}
+
+	g := findGroup(args[0])
+	if g == nil {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+		printUsage(os.Stderr)
+		return 2
+	}
+	if len(args) < 2 || args[1] == "help" || args[1] == "-h" || args[1] == "--help" {
+		printGroupUsage(os.Stdout, g)
+		return 0
+	}
+	cmd := findCommand(g, args[1])
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", g.name+" "+args[1])
+		printGroupUsage(os.Stderr, g)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	c := &cmdContext{
+		ctx:  ctx,
+		name: g.name + " " + cmd.name,
+		cmd:  cmd,
+		args: args[2:],
+		out:  os.Stdout,
+	}
+	defer c.close()
+
+	if err := cmd.run(c); err != nil {
+		if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+			return 2
+		}
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func findGroup(name string) *group {
+	for _, g := range groups {
+		if g.name == name {
+			return g
+		}
+	}
+	return nil
+}
+
+func findCommand(g *group, name string) *command {
+	for _, cmd := range g.cmds {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "netsimctl controls the network simulation master.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Usage:")
+	fmt.Fprintln(w, "  netsimctl <command> <subcommand> [flags] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, g := range groups {
+		fmt.Fprintf(w, "  %-12s %s\n", g.name, g.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Connection settings are taken from flags, then NETSIM_* environment variables,")
+	fmt.Fprintln(w, "then the config file (-config, NETSIM_CONFIG or ~/.netsimctl.yaml).")
+	fmt.Fprintln(w, "Run 'netsimctl <command> help' for the subcommands of a command.")
+}
+
+func printGroupUsage(w io.Writer, g *group) {
+	fmt.Fprintf(w, "netsimctl %s - %s\n\n", g.name, g.summary)
+	fmt.Fprintln(w, "Subcommands:")
+	for _, cmd := range g.cmds {
+		fmt.Fprintf(w, "  %-60s %s\n", strings.TrimSpace(g.name+" "+cmd.name+" "+cmd.usage), cmd.summary)
+	}
+}
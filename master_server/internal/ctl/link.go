@@ -0,0 +1,358 @@
+package ctl
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"strconv"
+	"time"
+
+	"netsimlation/distribute/master_server/internal/model"
+	"netsimlation/distribute/master_server/internal/store"
+	"netsimlation/distribute/master_server/internal/units"
+)
+
+func init() {
+	register(&group{
+		name:    "link",
+		summary: "Create, change, delete and list links",
+		cmds: []*command{
+			{name: "list", usage: "[-node N] [-state up|down]", summary: "List links", run: runLinkList},
+			{name: "show", usage: "<id>", summary: "Show one link", run: runLinkShow},
+			{name: "add", usage: "<id> -mac MAC|-src-container NAME [-dst-container NAME] [-bw 10mbit] [-delay 50ms] [-loss 1%]", summary: "Create a link", run: runLinkAdd},
+			{name: "set", usage: "<id> [-bw ..] [-delay ..] [-loss ..] [-apply-in 5s]", summary: "Change fields of a link", run: runLinkSet},
+			{name: "del", usage: "<id>...", summary: "Delete links", run: runLinkDel},
+			{name: "down", usage: "<id> [-apply-in 5s]", summary: "Administratively disable a link", run: runLinkAdmin(model.AdminDown)},
+			{name: "up", usage: "<id> [-apply-in 5s]", summary: "Re-enable a disabled link", run: runLinkAdmin(model.AdminUp)},
+			{name: "random", usage: "[-count 1000] [-generation]", summary: "Write random links for load testing", run: runLinkRandom},
+		},
+	})
+}
+
+// linkFlags link add/set 共用的链路字段参数，均以字符串接收以支持带单位的写法
+type linkFlags struct {
+	mac, bw, delay, loss, state string
+	srcContainer, dstContainer  string
+	srcNode, dstNode            int
+	applyIn                     time.Duration
+}
+
+func (f *linkFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.mac, "mac", "", "Source MAC address")
+	fs.StringVar(&f.bw, "bw", "", "Bandwidth, e.g. 10mbit, 1gbit, 500kbps")
+	fs.StringVar(&f.delay, "delay", "", "Delay, e.g. 50ms, 1.5s (0 for none)")
+	fs.StringVar(&f.loss, "loss", "", "Packet loss, e.g. 1% or 0.01")
+	fs.StringVar(&f.state, "state", "", "Admin state: up or down")
+	fs.IntVar(&f.srcNode, "src-node", 0, "Source node id")
+	fs.IntVar(&f.dstNode, "dst-node", 0, "Destination node id")
+	fs.StringVar(&f.srcContainer, "src-container", "", "Source container id or name, resolved to its MAC by the slaves")
+	fs.StringVar(&f.dstContainer, "dst-container", "", "Destination container id or name; the link is shaped on its host-side veth")
+	fs.DurationVar(&f.applyIn, "apply-in", 0, "Schedule the change this far in the future so all slaves switch together")
+}
+
+// patch 将命令行上出现过的字段转换为 LinkPatch，未出现的字段保持原值
+func (f *linkFlags) patch(fs *flag.FlagSet) (*model.LinkPatch, error) {
+	p := &model.LinkPatch{}
+	var err error
+	fs.Visit(func(fl *flag.Flag) {
+		if err != nil {
+			return
+		}
+		switch fl.Name {
+		case "mac":
+			if _, e := net.ParseMAC(f.mac); e != nil {
+				err = fmt.Errorf("invalid MAC %q", f.mac)
+				return
+			}
+			p.SourceMAC = &f.mac
+		case "bw":
+			var v uint64
+			if v, err = units.ParseRate(f.bw); err == nil {
+				p.BandwidthBps = &v
+			}
+		case "delay":
+			var v uint32
+			if v, err = units.ParseDelay(f.delay); err == nil {
+				p.DelayMs = &v
+			}
+		case "loss":
+			var v float64
+			if v, err = units.ParseLoss(f.loss); err == nil {
+				p.PacketLossRate = &v
+			}
+		case "state":
+			if f.state != model.AdminUp && f.state != model.AdminDown {
+				err = fmt.Errorf("invalid state %q, expected up or down", f.state)
+				return
+			}
+			p.AdminState = &f.state
+		case "dst-node":
+			p.DestNodeID = &f.dstNode
+		case "src-container":
+			p.SourceContainer = &f.srcContainer
+		case "dst-container":
+			p.DestContainer = &f.dstContainer
+		case "apply-in":
+			at := applyAt(f.applyIn)
+			p.ApplyAt = &at
+		}
+	})
+	return p, err
+}
+
+// applyAt 将相对延迟换算为主控时钟下的计划生效时刻，0表示立即生效
+func applyAt(in time.Duration) int64 {
+	if in <= 0 {
+		return 0
+	}
+	return time.Now().Add(in).UnixMilli()
+}
+
+func runLinkList(c *cmdContext) error {
+	fs := c.flags()
+	node := fs.Int("node", 0, "Only links whose source or destination is this node")
+	state := fs.String("state", "", "Only links in this admin state (up or down)")
+	if _, err := c.parse(fs, 0, 0); err != nil {
+		return err
+	}
+	b, err := c.backend()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := c.requestContext()
+	defer cancel()
+
+	links, err := b.ListLinks(ctx)
+	if err != nil {
+		return err
+	}
+	filtered := links[:0]
+	for _, l := range links {
+		if *node != 0 && l.SourceNodeID != *node && l.DestNodeID != *node {
+			continue
+		}
+		if *state != "" && adminString(l.AdminState) != *state {
+			continue
+		}
+		filtered = append(filtered, l)
+	}
+	return c.print(filtered, func(w io.Writer) { linkTable(w, filtered) })
+}
+
+func runLinkShow(c *cmdContext) error {
+	fs := c.flags()
+	args, err := c.parse(fs, 1, 1)
+	if err != nil {
+		return err
+	}
+	b, err := c.backend()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := c.requestContext()
+	defer cancel()
+
+	link, err := b.GetLink(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	rec := model.LinkRecord{ID: args[0], NetworkLink: *link}
+	return c.print(rec, func(w io.Writer) { linkTable(w, []model.LinkRecord{rec}) })
+}
+
+func runLinkAdd(c *cmdContext) error {
+	fs := c.flags()
+	var f linkFlags
+	f.register(fs)
+	args, err := c.parse(fs, 1, 1)
+	if err != nil {
+		return err
+	}
+	if f.mac == "" && f.srcContainer == "" {
+		return errors.New("-mac or -src-container is required")
+	}
+	patch, err := f.patch(fs)
+	if err != nil {
+		return err
+	}
+	link := &model.NetworkLink{
+		SourceNodeID: f.srcNode,
+		CreatedAt:    time.Now().Format(time.RFC3339),
+	}
+	patch.Apply(link)
+
+	b, err := c.backend()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := c.requestContext()
+	defer cancel()
+
+	if _, err := b.GetLink(ctx, args[0]); err == nil {
+		return fmt.Errorf("link %s already exists, use 'link set' to change it", args[0])
+	} else if !errors.Is(err, store.ErrLinkNotFound) {
+		return err
+	}
+	if err := b.PutLink(ctx, args[0], link); err != nil {
+		return err
+	}
+	rec := model.LinkRecord{ID: args[0], NetworkLink: *link}
+	return c.print(rec, func(w io.Writer) { linkTable(w, []model.LinkRecord{rec}) })
+}
+
+func runLinkSet(c *cmdContext) error {
+	fs := c.flags()
+	var f linkFlags
+	f.register(fs)
+	args, err := c.parse(fs, 1, 1)
+	if err != nil {
+		return err
+	}
+	patch, err := f.patch(fs)
+	if err != nil {
+		return err
+	}
+	b, err := c.backend()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := c.requestContext()
+	defer cancel()
+
+	// 源节点不在 LinkPatch 中，单独读改写
+	srcNodeSet := false
+	fs.Visit(func(fl *flag.Flag) { srcNodeSet = srcNodeSet || fl.Name == "src-node" })
+	if srcNodeSet {
+		link, err := b.GetLink(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		link.SourceNodeID = f.srcNode
+		patch.Apply(link)
+		if err := b.PutLink(ctx, args[0], link); err != nil {
+			return err
+		}
+		rec := model.LinkRecord{ID: args[0], NetworkLink: *link}
+		return c.print(rec, func(w io.Writer) { linkTable(w, []model.LinkRecord{rec}) })
+	}
+
+	if patch.String() == "" {
+		return errors.New("nothing to change, give at least one of -mac -bw -delay -loss -state -src-node -dst-node -src-container -dst-container")
+	}
+	link, err := b.PatchLink(ctx, args[0], patch)
+	if err != nil {
+		return err
+	}
+	rec := model.LinkRecord{ID: args[0], NetworkLink: *link}
+	return c.print(rec, func(w io.Writer) { linkTable(w, []model.LinkRecord{rec}) })
+}
+
+func runLinkDel(c *cmdContext) error {
+	fs := c.flags()
+	args, err := c.parse(fs, 1, -1)
+	if err != nil {
+		return err
+	}
+	b, err := c.backend()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := c.requestContext()
+	defer cancel()
+
+	for _, id := range args {
+		if err := b.DeleteLink(ctx, id); err != nil {
+			return fmt.Errorf("delete link %s: %w", id, err)
+		}
+		c.printf("deleted link %s\n", id)
+	}
+	return nil
+}
+
+func runLinkAdmin(state string) func(c *cmdContext) error {
+	return func(c *cmdContext) error {
+		fs := c.flags()
+		applyIn := fs.Duration("apply-in", 0, "Schedule the change this far in the future so all slaves switch together")
+		args, err := c.parse(fs, 1, 1)
+		if err != nil {
+			return err
+		}
+		b, err := c.backend()
+		if err != nil {
+			return err
+		}
+		ctx, cancel := c.requestContext()
+		defer cancel()
+
+		link, err := b.SetAdminState(ctx, args[0], state, applyAt(*applyIn))
+		if err != nil {
+			return err
+		}
+		rec := model.LinkRecord{ID: args[0], NetworkLink: *link}
+		return c.print(rec, func(w io.Writer) { linkTable(w, []model.LinkRecord{rec}) })
+	}
+}
+
+// runLinkRandom 写入随机链路，用于压测从节点的事件处理能力
+func runLinkRandom(c *cmdContext) error {
+	fs := c.flags()
+	count := fs.Int("count", 1000, "Number of random links")
+	asGeneration := fs.Bool("generation", false, "Write the links as a new generation and activate it instead of individual keys")
+	if _, err := c.parse(fs, 0, 0); err != nil {
+		return err
+	}
+	b, err := c.backend()
+	if err != nil {
+		return err
+	}
+
+	rand.Seed(time.Now().UnixNano())
+	links := make(map[string]model.NetworkLink, *count)
+	for i := 0; i < *count; i++ {
+		links[strconv.Itoa(i)] = randomLink()
+	}
+
+	start := time.Now()
+	if *asGeneration {
+		ctx, cancel := c.requestContext()
+		defer cancel()
+		gen, err := activateGeneration(ctx, b, links)
+		if err != nil {
+			return err
+		}
+		c.printf("wrote and activated generation %d with %d links in %v\n", gen, *count, time.Since(start))
+		return nil
+	}
+
+	for i := 0; i < *count; i++ {
+		id := strconv.Itoa(i)
+		link := links[id]
+		ctx, cancel := c.requestContext()
+		err := b.PutLink(ctx, id, &link)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("write link %s: %w", id, err)
+		}
+	}
+	c.printf("wrote %d links in %v\n", *count, time.Since(start))
+	return nil
+}
+
+// randomLink 生成一条随机网络链路
+func randomLink() model.NetworkLink {
+	mac := make(net.HardwareAddr, 6)
+	rand.Read(mac)
+	// 单播、全局唯一地址
+	mac[0] &= 0xFC
+	return model.NetworkLink{
+		SourceMAC:      mac.String(),
+		DestNodeID:     rand.Intn(1000) + 1,                // 随机节点ID 1-1000
+		PacketLossRate: rand.Float64() * 0.1,               // 随机丢包率 0-10%
+		BandwidthBps:   uint64(rand.Intn(100)+1) * 1000000, // 1-100 MB/s
+		DelayMs:        uint32(rand.Intn(100) + 1),         // 1-100 ms延迟
+		CreatedAt:      time.Now().Format(time.RFC3339),
+	}
+}
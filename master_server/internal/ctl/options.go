@@ -0,0 +1,203 @@
+package ctl
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// 环境变量名
+const (
+	envConfig        = "NETSIM_CONFIG"
+	envServer        = "NETSIM_SERVER"
+	envRedisAddr     = "NETSIM_REDIS_ADDR"
+	envRedisPassword = "NETSIM_REDIS_PASSWORD"
+	envRedisDB       = "NETSIM_REDIS_DB"
+	envOutput        = "NETSIM_OUTPUT"
+)
+
+const defaultConfigName = ".netsimctl.yaml"
+
+// fileConfig 配置文件内容
+type fileConfig struct {
+	Server string `yaml:"server"`
+	Redis  struct {
+		Addr     string `yaml:"addr"`
+		Password string `yaml:"password"`
+		DB       *int   `yaml:"db"`
+	} `yaml:"redis"`
+	Output string `yaml:"output"`
+}
+
+// options 所有子命令共用的连接与输出参数
+type options struct {
+	config        string
+	server        string
+	redisAddr     string
+	redisPassword string
+	redisDB       int
+	output        string
+	timeout       time.Duration
+}
+
+func (o *options) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.config, "config", "", "Config file (env "+envConfig+", default ~/"+defaultConfigName+")")
+	fs.StringVar(&o.server, "server", "", "master-server API base URL, e.g. http://master:8080; talks to Redis directly when empty (env "+envServer+")")
+	fs.StringVar(&o.redisAddr, "addr", "localhost:6379", "Redis server address (env "+envRedisAddr+")")
+	fs.StringVar(&o.redisPassword, "password", "", "Redis password (env "+envRedisPassword+")")
+	fs.IntVar(&o.redisDB, "db", 0, "Redis database (env "+envRedisDB+")")
+	fs.StringVar(&o.output, "o", "table", "Output format: table or json (env "+envOutput+")")
+	fs.StringVar(&o.output, "output", "table", "Same as -o")
+	fs.DurationVar(&o.timeout, "timeout", 30*time.Second, "Timeout of each request")
+}
+
+// resolve 为命令行未指定的参数依次取环境变量与配置文件中的值
+func (o *options) resolve(fs *flag.FlagSet) error {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	set["o"] = set["o"] || set["output"]
+
+	if !set["config"] {
+		o.config = os.Getenv(envConfig)
+	}
+	var cfg fileConfig
+	if err := loadFileConfig(o.config, &cfg); err != nil {
+		return err
+	}
+
+	pick := func(name string, dst *string, env, file string) {
+		if set[name] {
+			return
+		}
+		if v := os.Getenv(env); v != "" {
+			*dst = v
+		} else if file != "" {
+			*dst = file
+		}
+	}
+	pick("server", &o.server, envServer, cfg.Server)
+	pick("addr", &o.redisAddr, envRedisAddr, cfg.Redis.Addr)
+	pick("password", &o.redisPassword, envRedisPassword, cfg.Redis.Password)
+	pick("o", &o.output, envOutput, cfg.Output)
+
+	if !set["db"] {
+		if v := os.Getenv(envRedisDB); v != "" {
+			db, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s %q", envRedisDB, v)
+			}
+			o.redisDB = db
+		} else if cfg.Redis.DB != nil {
+			o.redisDB = *cfg.Redis.DB
+		}
+	}
+
+	if o.output != "table" && o.output != "json" {
+		return fmt.Errorf("unknown output format %q, expected table or json", o.output)
+	}
+	return nil
+}
+
+// loadFileConfig 读取配置文件。未显式指定时使用 ~/.netsimctl.yaml，文件不存在不视为错误。
+func loadFileConfig(path string, cfg *fileConfig) error {
+	explicit := path != ""
+	if !explicit {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil
+		}
+		path = filepath.Join(home, defaultConfigName)
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) && !explicit {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return nil
+}
+
+// cmdContext 一次子命令执行的上下文
+type cmdContext struct {
+	ctx  context.Context
+	name string
+	cmd  *command
+	args []string
+	out  io.Writer
+
+	opts options
+	fs   *flag.FlagSet
+	b    backend
+}
+
+// flags 创建已注册公共参数的 FlagSet，子命令在其上定义自己的参数
+func (c *cmdContext) flags() *flag.FlagSet {
+	fs := flag.NewFlagSet("netsimctl "+c.name, flag.ContinueOnError)
+	c.opts.register(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: netsimctl %s [flags] %s\n\n%s\n\nFlags:\n", c.name, c.cmd.usage, c.cmd.summary)
+		fs.PrintDefaults()
+	}
+	c.fs = fs
+	return fs
+}
+
+// parse 解析参数，允许参数与位置参数交错（如 link set 3 -bw 10mbit），并检查位置参数个数
+func (c *cmdContext) parse(fs *flag.FlagSet, minArgs, maxArgs int) ([]string, error) {
+	var positional []string
+	args := c.args
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	if len(positional) < minArgs || (maxArgs >= 0 && len(positional) > maxArgs) {
+		fs.Usage()
+		return nil, errUsage
+	}
+	if err := c.opts.resolve(fs); err != nil {
+		return nil, err
+	}
+	return positional, nil
+}
+
+// backend 按参数连接主控端，首次调用时建立连接
+func (c *cmdContext) backend() (backend, error) {
+	if c.b != nil {
+		return c.b, nil
+	}
+	b, err := newBackend(c.ctx, &c.opts)
+	if err != nil {
+		return nil, err
+	}
+	c.b = b
+	return b, nil
+}
+
+// requestContext 单次请求的超时上下文
+func (c *cmdContext) requestContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(c.ctx, c.opts.timeout)
+}
+
+func (c *cmdContext) close() {
+	if c.b != nil {
+		c.b.Close()
+	}
+}
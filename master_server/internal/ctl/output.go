@@ -0,0 +1,63 @@
+package ctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"netsimlation/distribute/master_server/internal/model"
+	"netsimlation/distribute/master_server/internal/units"
+)
+
+// print 按 -o 输出：json 时直接编码 v，table 时调用 table 逐行写入对齐的表格
+func (c *cmdContext) print(v interface{}, table func(w io.Writer)) error {
+	if c.opts.output == "json" {
+		enc := json.NewEncoder(c.out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	tw := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	table(tw)
+	return tw.Flush()
+}
+
+// printf 只在表格模式下输出的提示信息，json 模式保持输出可解析
+func (c *cmdContext) printf(format string, args ...interface{}) {
+	if c.opts.output == "json" {
+		return
+	}
+	fmt.Fprintf(c.out, format, args...)
+}
+
+func linkTable(w io.Writer, links []model.LinkRecord) {
+	fmt.Fprintln(w, "ID\tSOURCE MAC\tSRC NODE\tDST NODE\tBANDWIDTH\tDELAY\tLOSS\tSTATE\tAPPLY AT")
+	for _, l := range links {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			l.ID, l.SourceMAC, nodeString(l.SourceNodeID), l.DestNodeID,
+			units.FormatRate(l.BandwidthBps), units.FormatDelay(l.DelayMs), units.FormatLoss(l.PacketLossRate),
+			adminString(l.AdminState), applyAtString(l.ApplyAt))
+	}
+}
+
+func nodeString(id int) string {
+	if id == 0 {
+		return "-"
+	}
+	return fmt.Sprint(id)
+}
+
+func adminString(state string) string {
+	if state == "" {
+		return model.AdminUp
+	}
+	return state
+}
+
+func applyAtString(ms int64) string {
+	if ms == 0 {
+		return "-"
+	}
+	return time.UnixMilli(ms).Format("15:04:05.000")
+}
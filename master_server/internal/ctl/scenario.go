@@ -0,0 +1,132 @@
+package ctl
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"netsimlation/distribute/master_server/internal/scenario"
+	"netsimlation/distribute/master_server/internal/store"
+)
+
+func init() {
+	register(&group{
+		name:    "scenario",
+		summary: "Run timed link change scenarios",
+		cmds: []*command{
+			{name: "run", usage: "-f scenario.yaml [-start RFC3339 | -start-in 3s] [-lead 200ms]", summary: "Run a scenario timeline until it finishes", run: runScenarioRun},
+			{name: "plan", usage: "-f scenario.yaml [-start RFC3339 | -start-in 3s]", summary: "Print the schedule of a scenario without running it", run: runScenarioPlan},
+		},
+	})
+	register(&group{
+		name:    "slave",
+		summary: "Query slave daemons",
+		cmds: []*command{
+			{name: "status", usage: "[node]", summary: "Show the status reported by online slaves", run: runSlaveStatus},
+		},
+	})
+}
+
+// scenarioArgs 解析时间线文件与开始时间
+func scenarioArgs(c *cmdContext, withLead bool) (*scenario.Scenario, time.Time, time.Duration, error) {
+	fs := c.flags()
+	file := fs.String("f", "", "Timeline file (.json/.yaml)")
+	startAt := fs.String("start", "", "Experiment start time in RFC3339 format (default: now + -start-in)")
+	startIn := fs.Duration("start-in", 3*time.Second, "Delay before the experiment starts when -start is not given")
+	var lead *time.Duration
+	if withLead {
+		lead = fs.Duration("lead", 0, "Publish changes this long before they are due with apply_at so slaves apply them on synchronized clocks")
+	}
+	if _, err := c.parse(fs, 0, 0); err != nil {
+		return nil, time.Time{}, 0, err
+	}
+	if *file == "" {
+		return nil, time.Time{}, 0, errors.New("-f is required")
+	}
+
+	sc, err := scenario.Load(*file)
+	if err != nil {
+		return nil, time.Time{}, 0, err
+	}
+	start := time.Now().Add(*startIn)
+	if *startAt != "" {
+		if start, err = time.Parse(time.RFC3339Nano, *startAt); err != nil {
+			return nil, time.Time{}, 0, fmt.Errorf("invalid start time %q: %w", *startAt, err)
+		}
+	}
+	var l time.Duration
+	if lead != nil {
+		l = *lead
+	}
+	return sc, start, l, nil
+}
+
+func runScenarioPlan(c *cmdContext) error {
+	sc, start, _, err := scenarioArgs(c, false)
+	if err != nil {
+		return err
+	}
+	if c.opts.output == "json" {
+		return c.print(sc, nil)
+	}
+	sc.PrintSchedule(c.out, start)
+	return nil
+}
+
+func runScenarioRun(c *cmdContext) error {
+	sc, start, lead, err := scenarioArgs(c, true)
+	if err != nil {
+		return err
+	}
+	b, err := c.backend()
+	if err != nil {
+		return err
+	}
+	// 时间线可能持续很久，不使用单次请求超时；Ctrl-C 中止实验
+	return b.RunScenario(c.ctx, sc, start, lead)
+}
+
+func runSlaveStatus(c *cmdContext) error {
+	fs := c.flags()
+	args, err := c.parse(fs, 0, 1)
+	if err != nil {
+		return err
+	}
+	b, err := c.backend()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := c.requestContext()
+	defer cancel()
+
+	var slaves []store.SlaveStatus
+	if len(args) == 1 {
+		st, err := b.GetSlave(ctx, args[0])
+		if err != nil {
+			return err
+		}
+		if st == nil {
+			return fmt.Errorf("slave %s is offline", args[0])
+		}
+		slaves = append(slaves, *st)
+	} else if slaves, err = b.ListSlaves(ctx); err != nil {
+		return err
+	}
+
+	return c.print(slaves, func(w io.Writer) {
+		fmt.Fprintln(w, "NODE\tVERSION\tIFACE\tGEN\tLINKS\tCLOCK OFFSET\tLAST SKEW\tLAST SEEN")
+		for _, s := range slaves {
+			offset := "unsynced"
+			if s.ClockSync {
+				offset = fmt.Sprintf("%.3fms", s.OffsetMs)
+			}
+			skew := "-"
+			if s.LastSkewMs != nil {
+				skew = fmt.Sprintf("%.3fms", *s.LastSkewMs)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\t%s\t%s ago\n", s.Node, dash(s.Version), dash(s.Iface),
+				s.AppliedGen, s.Links, offset, skew, time.Since(s.LastSeen).Round(time.Second))
+		}
+	})
+}
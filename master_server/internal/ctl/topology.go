@@ -0,0 +1,466 @@
+package ctl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"netsimlation/distribute/master_server/internal/model"
+	"netsimlation/distribute/master_server/internal/store"
+	"netsimlation/distribute/master_server/internal/units"
+)
+
+func init() {
+	register(&group{
+		name:    "node",
+		summary: "List experiment nodes",
+		cmds: []*command{
+			{name: "list", summary: "List nodes", run: runNodeList},
+		},
+	})
+	register(&group{
+		name:    "topology",
+		summary: "Apply a topology file of nodes and links",
+		cmds: []*command{
+			{name: "apply", usage: "-f topology.yaml [-generation] [-prune] [-dry-run]", summary: "Create or update the nodes and links of a topology file", run: runTopologyApply},
+		},
+	})
+	register(&group{
+		name:    "generation",
+		summary: "Inspect, activate and roll back link configuration generations",
+		cmds: []*command{
+			{name: "list", summary: "Show the active generation and activation history", run: runGenerationList},
+			{name: "activate", usage: "<generation>", summary: "Activate a generation", run: runGenerationActivate},
+			{name: "rollback", summary: "Roll back to the previously active generation", run: runGenerationRollback},
+		},
+	})
+	register(&group{
+		name:    "partition",
+		summary: "Partition the network into isolated node groups and heal it",
+		cmds: []*command{
+			{name: "show", summary: "Show the active partition", run: runPartitionShow},
+			{name: "apply", usage: "-groups \"a=1,2;b=3\" [-apply-in 5s]", summary: "Disable all links between different groups", run: runPartitionApply},
+			{name: "heal", usage: "[-apply-in 5s]", summary: "Re-enable the links cut by the active partition", run: runPartitionHeal},
+		},
+	})
+}
+
+func runNodeList(c *cmdContext) error {
+	fs := c.flags()
+	if _, err := c.parse(fs, 0, 0); err != nil {
+		return err
+	}
+	b, err := c.backend()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := c.requestContext()
+	defer cancel()
+
+	nodes, err := b.ListNodes(ctx)
+	if err != nil {
+		return err
+	}
+	return c.print(nodes, func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tNAME\tHOST\tMACS\tLABELS")
+		for _, n := range nodes {
+			labels := make([]string, 0, len(n.Labels))
+			for k, v := range n.Labels {
+				labels = append(labels, k+"="+v)
+			}
+			sort.Strings(labels)
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", n.ID, n.Name, dash(n.Host),
+				dash(strings.Join(n.MACs, ",")), dash(strings.Join(labels, ",")))
+		}
+	})
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// topologyFile 拓扑文件，链路参数使用带单位的写法：
+//
+//	nodes:
+//	  - {id: 1, name: a, host: host-a, macs: ["02:00:00:00:00:01"]}
+//	links:
+//	  - {id: "1", source_mac: "02:00:00:00:00:01", source_node: 1, dest_node: 2, bandwidth: 10mbit, delay: 20ms, loss: 0.1%}
+type topologyFile struct {
+	Nodes []model.Node `yaml:"nodes" json:"nodes"`
+	Links []linkSpec   `yaml:"links" json:"links"`
+}
+
+type linkSpec struct {
+	ID         string `yaml:"id" json:"id"`
+	SourceMAC  string `yaml:"source_mac" json:"source_mac"`
+	SourceNode int    `yaml:"source_node" json:"source_node"`
+	DestNode   int    `yaml:"dest_node" json:"dest_node"`
+	Bandwidth  string `yaml:"bandwidth" json:"bandwidth"`
+	Delay      string `yaml:"delay" json:"delay"`
+	Loss       string `yaml:"loss" json:"loss"`
+	State      string `yaml:"state" json:"state"`
+	// 以容器代替MAC/网卡描述端点，见 model.NetworkLink
+	SourceContainer string `yaml:"source_container" json:"source_container"`
+	DestContainer   string `yaml:"dest_container" json:"dest_container"`
+}
+
+func (s *linkSpec) link() (model.NetworkLink, error) {
+	l := model.NetworkLink{
+		SourceMAC:       s.SourceMAC,
+		SourceNodeID:    s.SourceNode,
+		DestNodeID:      s.DestNode,
+		SourceContainer: s.SourceContainer,
+		DestContainer:   s.DestContainer,
+		CreatedAt:       time.Now().Format(time.RFC3339),
+	}
+	// 指定了源容器时MAC由从节点解析，source_mac 可以省略
+	if s.SourceMAC != "" || s.SourceContainer == "" {
+		if _, err := net.ParseMAC(s.SourceMAC); err != nil {
+			return l, fmt.Errorf("invalid source_mac %q", s.SourceMAC)
+		}
+	}
+	var err error
+	if s.Bandwidth != "" {
+		if l.BandwidthBps, err = units.ParseRate(s.Bandwidth); err != nil {
+			return l, err
+		}
+	}
+	if s.Delay != "" {
+		if l.DelayMs, err = units.ParseDelay(s.Delay); err != nil {
+			return l, err
+		}
+	}
+	if s.Loss != "" {
+		if l.PacketLossRate, err = units.ParseLoss(s.Loss); err != nil {
+			return l, err
+		}
+	}
+	switch s.State {
+	case "", model.AdminUp:
+	case model.AdminDown:
+		l.AdminState = model.AdminDown
+	default:
+		return l, fmt.Errorf("invalid state %q, expected up or down", s.State)
+	}
+	return l, nil
+}
+
+// loadTopology 读取拓扑文件，YAML 是 JSON 的超集，统一按 YAML 解析
+func loadTopology(path string) (*topologyFile, map[string]model.NetworkLink, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	var topo topologyFile
+	if err := yaml.Unmarshal(data, &topo); err != nil {
+		return nil, nil, fmt.Errorf("parse %s: %w", filepath.Base(path), err)
+	}
+
+	nodes := make(map[int]bool, len(topo.Nodes))
+	for _, n := range topo.Nodes {
+		if n.ID <= 0 {
+			return nil, nil, fmt.Errorf("node %q: id must be positive", n.Name)
+		}
+		if nodes[n.ID] {
+			return nil, nil, fmt.Errorf("duplicate node id %d", n.ID)
+		}
+		nodes[n.ID] = true
+	}
+
+	links := make(map[string]model.NetworkLink, len(topo.Links))
+	for i, spec := range topo.Links {
+		if spec.ID == "" {
+			return nil, nil, fmt.Errorf("link #%d: id is required", i)
+		}
+		if _, dup := links[spec.ID]; dup {
+			return nil, nil, fmt.Errorf("duplicate link id %s", spec.ID)
+		}
+		for _, id := range []int{spec.SourceNode, spec.DestNode} {
+			if id != 0 && len(nodes) > 0 && !nodes[id] {
+				return nil, nil, fmt.Errorf("link %s: unknown node %d", spec.ID, id)
+			}
+		}
+		l, err := spec.link()
+		if err != nil {
+			return nil, nil, fmt.Errorf("link %s: %w", spec.ID, err)
+		}
+		links[spec.ID] = l
+	}
+	return &topo, links, nil
+}
+
+func runTopologyApply(c *cmdContext) error {
+	fs := c.flags()
+	file := fs.String("f", "", "Topology file (.yaml or .json)")
+	asGeneration := fs.Bool("generation", false, "Write the links as a new generation and activate it atomically instead of individual keys")
+	prune := fs.Bool("prune", false, "Delete links that are not in the file (individual keys only)")
+	dryRun := fs.Bool("dry-run", false, "Validate the file and print the links without writing")
+	if _, err := c.parse(fs, 0, 0); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errors.New("-f is required")
+	}
+	topo, links, err := loadTopology(*file)
+	if err != nil {
+		return err
+	}
+
+	records := make([]model.LinkRecord, 0, len(links))
+	for id, l := range links {
+		records = append(records, model.LinkRecord{ID: id, NetworkLink: l})
+	}
+	sort.Slice(records, func(i, j int) bool { return lessID(records[i].ID, records[j].ID) })
+	if *dryRun {
+		return c.print(records, func(w io.Writer) { linkTable(w, records) })
+	}
+
+	b, err := c.backend()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := c.requestContext()
+	defer cancel()
+
+	for i := range topo.Nodes {
+		if err := b.SetNode(ctx, &topo.Nodes[i]); err != nil {
+			return fmt.Errorf("write node %d: %w", topo.Nodes[i].ID, err)
+		}
+	}
+
+	if *asGeneration {
+		gen, err := activateGeneration(ctx, b, links)
+		if err != nil {
+			return err
+		}
+		c.printf("applied %d nodes and %d links as generation %d\n", len(topo.Nodes), len(links), gen)
+		return nil
+	}
+
+	for _, rec := range records {
+		l := rec.NetworkLink
+		if err := b.PutLink(ctx, rec.ID, &l); err != nil {
+			return fmt.Errorf("write link %s: %w", rec.ID, err)
+		}
+	}
+	pruned := 0
+	if *prune {
+		existing, err := b.ListLinks(ctx)
+		if err != nil {
+			return err
+		}
+		for _, l := range existing {
+			if _, ok := links[l.ID]; ok {
+				continue
+			}
+			if err := b.DeleteLink(ctx, l.ID); err != nil && !errors.Is(err, store.ErrLinkNotFound) {
+				return fmt.Errorf("delete link %s: %w", l.ID, err)
+			}
+			pruned++
+		}
+	}
+	c.printf("applied %d nodes and %d links, pruned %d links\n", len(topo.Nodes), len(links), pruned)
+	return nil
+}
+
+// lessID 数字ID按数值排序，其余按字典序
+func lessID(a, b string) bool {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	if errA == nil && errB == nil {
+		return na < nb
+	}
+	return a < b
+}
+
+// activateGeneration 写入新的一代并激活，写入完成后才切换生效指针
+func activateGeneration(ctx context.Context, b backend, links map[string]model.NetworkLink) (int64, error) {
+	gen, err := b.WriteGeneration(ctx, links)
+	if err != nil {
+		return 0, fmt.Errorf("write generation: %w", err)
+	}
+	if err := b.Activate(ctx, gen); err != nil {
+		return 0, fmt.Errorf("activate generation %d: %w", gen, err)
+	}
+	return gen, nil
+}
+
+func runGenerationList(c *cmdContext) error {
+	fs := c.flags()
+	if _, err := c.parse(fs, 0, 0); err != nil {
+		return err
+	}
+	b, err := c.backend()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := c.requestContext()
+	defer cancel()
+
+	active, err := b.ActiveGeneration(ctx)
+	if err != nil {
+		return err
+	}
+	history, err := b.History(ctx)
+	if err != nil {
+		return err
+	}
+	out := struct {
+		Active  int64   `json:"active"`
+		History []int64 `json:"history"`
+	}{active, history}
+	return c.print(out, func(w io.Writer) {
+		fmt.Fprintln(w, "#\tGENERATION\tACTIVE")
+		for i, gen := range history {
+			mark := ""
+			if gen == active {
+				mark = "*"
+			}
+			fmt.Fprintf(w, "%d\t%d\t%s\n", i, gen, mark)
+		}
+	})
+}
+
+func runGenerationActivate(c *cmdContext) error {
+	fs := c.flags()
+	args, err := c.parse(fs, 1, 1)
+	if err != nil {
+		return err
+	}
+	gen, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || gen <= 0 {
+		return fmt.Errorf("invalid generation %q", args[0])
+	}
+	b, err := c.backend()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := c.requestContext()
+	defer cancel()
+
+	if err := b.Activate(ctx, gen); err != nil {
+		return err
+	}
+	c.printf("activated generation %d\n", gen)
+	return nil
+}
+
+func runGenerationRollback(c *cmdContext) error {
+	fs := c.flags()
+	if _, err := c.parse(fs, 0, 0); err != nil {
+		return err
+	}
+	b, err := c.backend()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := c.requestContext()
+	defer cancel()
+
+	gen, err := b.Rollback(ctx)
+	if err != nil {
+		return err
+	}
+	c.printf("rolled back to generation %d\n", gen)
+	return nil
+}
+
+func groupTable(w io.Writer, groups []store.Group) {
+	fmt.Fprintln(w, "GROUP\tNODES")
+	for _, g := range groups {
+		ids := make([]string, len(g.Nodes))
+		for i, n := range g.Nodes {
+			ids[i] = strconv.Itoa(n)
+		}
+		fmt.Fprintf(w, "%s\t%s\n", g.Name, strings.Join(ids, ","))
+	}
+}
+
+func runPartitionShow(c *cmdContext) error {
+	fs := c.flags()
+	if _, err := c.parse(fs, 0, 0); err != nil {
+		return err
+	}
+	b, err := c.backend()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := c.requestContext()
+	defer cancel()
+
+	groups, err := b.ActivePartition(ctx)
+	if err != nil {
+		return err
+	}
+	return c.print(groups, func(w io.Writer) { groupTable(w, groups) })
+}
+
+func runPartitionApply(c *cmdContext) error {
+	fs := c.flags()
+	spec := fs.String("groups", "", "Node groups, e.g. \"a=1,2,3;b=4,5\"")
+	applyIn := fs.Duration("apply-in", 0, "Schedule the cut this far in the future so all slaves switch together")
+	if _, err := c.parse(fs, 0, 0); err != nil {
+		return err
+	}
+	groups, err := store.ParseGroups(*spec)
+	if err != nil {
+		return err
+	}
+	b, err := c.backend()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := c.requestContext()
+	defer cancel()
+
+	cut, err := b.Partition(ctx, groups, applyAt(*applyIn))
+	if err != nil {
+		return err
+	}
+	out := struct {
+		Groups []store.Group `json:"groups"`
+		Links  []string      `json:"links"`
+	}{groups, cut}
+	return c.print(out, func(w io.Writer) {
+		groupTable(w, groups)
+		fmt.Fprintf(w, "\ncut %d links: %s\n", len(cut), strings.Join(cut, " "))
+	})
+}
+
+func runPartitionHeal(c *cmdContext) error {
+	fs := c.flags()
+	applyIn := fs.Duration("apply-in", 0, "Schedule the heal this far in the future so all slaves switch together")
+	if _, err := c.parse(fs, 0, 0); err != nil {
+		return err
+	}
+	b, err := c.backend()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := c.requestContext()
+	defer cancel()
+
+	healed, err := b.Heal(ctx, applyAt(*applyIn))
+	if err != nil {
+		return err
+	}
+	out := struct {
+		Links []string `json:"links"`
+	}{healed}
+	return c.print(out, func(w io.Writer) {
+		fmt.Fprintf(w, "healed %d links: %s\n", len(healed), strings.Join(healed, " "))
+	})
+}
@@ -0,0 +1,203 @@
+package mobility
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Config 移动模型驱动的无线链路仿真配置
+type Config struct {
+	Seed           int64       `yaml:"seed"`            // 随机种子，0表示使用当前时间
+	Area           Area        `yaml:"area"`            // 节点活动区域（米）
+	UpdateInterval string      `yaml:"update_interval"` // 链路更新周期，默认1s
+	Duration       string      `yaml:"duration"`        // 仿真时长，为空时运行到手动停止
+	Propagation    Propagation `yaml:"propagation"`
+	Thresholds     Thresholds  `yaml:"thresholds"`
+	Nodes          []NodeSpec  `yaml:"nodes"`
+	Links          []LinkSpec  `yaml:"links"`
+
+	interval time.Duration
+	duration time.Duration
+	dir      string
+}
+
+// Thresholds 链路参数变化超过阈值才写入Redis，避免无意义的更新
+type Thresholds struct {
+	DelayMs        float64 `yaml:"delay_ms"`        // 默认0.5ms
+	BandwidthRatio float64 `yaml:"bandwidth_ratio"` // 相对变化，默认0.05
+	LossRate       float64 `yaml:"loss_rate"`       // 默认0.005
+}
+
+// NodeSpec 一个移动节点
+type NodeSpec struct {
+	Name  string  `yaml:"name"`
+	Model string  `yaml:"model"` // static、random_waypoint、gauss_markov 或 trace
+	X     float64 `yaml:"x"`     // 初始位置
+	Y     float64 `yaml:"y"`
+
+	// random_waypoint
+	MinSpeed float64 `yaml:"min_speed"` // m/s
+	MaxSpeed float64 `yaml:"max_speed"` // m/s
+	Pause    string  `yaml:"pause"`
+
+	// gauss_markov
+	Alpha float64 `yaml:"alpha"` // 记忆程度 0-1，默认0.75
+	Speed float64 `yaml:"speed"` // 平均速度 m/s
+	Sigma float64 `yaml:"sigma"` // 随机扰动标准差
+
+	// trace：time_ms,x,y 格式的CSV，相对路径相对于配置文件
+	TraceFile string `yaml:"trace_file"`
+}
+
+// LinkSpec 两个节点之间的一条无线链路
+type LinkSpec struct {
+	ID         string `yaml:"id"`
+	From       string `yaml:"from"`
+	To         string `yaml:"to"`
+	SourceMAC  string `yaml:"source_mac"`
+	DestNodeID int    `yaml:"dest_node_id"`
+}
+
+// LoadConfig 读取YAML配置并填充默认值
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	cfg.dir = filepath.Dir(path)
+
+	if cfg.Area.Width <= 0 || cfg.Area.Height <= 0 {
+		return nil, fmt.Errorf("area width and height must be positive")
+	}
+	if cfg.UpdateInterval == "" {
+		cfg.UpdateInterval = "1s"
+	}
+	if cfg.interval, err = time.ParseDuration(cfg.UpdateInterval); err != nil || cfg.interval <= 0 {
+		return nil, fmt.Errorf("invalid update_interval %q", cfg.UpdateInterval)
+	}
+	if cfg.Duration != "" {
+		if cfg.duration, err = time.ParseDuration(cfg.Duration); err != nil {
+			return nil, fmt.Errorf("invalid duration %q", cfg.Duration)
+		}
+	}
+	if cfg.Thresholds.DelayMs == 0 {
+		cfg.Thresholds.DelayMs = 0.5
+	}
+	if cfg.Thresholds.BandwidthRatio == 0 {
+		cfg.Thresholds.BandwidthRatio = 0.05
+	}
+	if cfg.Thresholds.LossRate == 0 {
+		cfg.Thresholds.LossRate = 0.005
+	}
+	if err := cfg.Propagation.setDefaults(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Interval 链路更新周期
+func (c *Config) Interval() time.Duration { return c.interval }
+
+// SimDuration 仿真时长，0表示不限
+func (c *Config) SimDuration() time.Duration { return c.duration }
+
+// buildModel 根据节点配置创建移动模型
+func (c *Config) buildModel(n *NodeSpec, rng *rand.Rand) (Model, error) {
+	start := Vec2{n.X, n.Y}
+	switch n.Model {
+	case "", "static":
+		return &Static{Pos: start}, nil
+	case "random_waypoint":
+		var pause time.Duration
+		if n.Pause != "" {
+			var err error
+			if pause, err = time.ParseDuration(n.Pause); err != nil {
+				return nil, fmt.Errorf("node %s: invalid pause %q", n.Name, n.Pause)
+			}
+		}
+		if n.MaxSpeed <= 0 || n.MinSpeed > n.MaxSpeed {
+			return nil, fmt.Errorf("node %s: require 0 <= min_speed <= max_speed, max_speed > 0", n.Name)
+		}
+		return NewRandomWaypoint(start, c.Area, n.MinSpeed, n.MaxSpeed, pause, rng), nil
+	case "gauss_markov":
+		alpha := n.Alpha
+		if alpha == 0 {
+			alpha = 0.75
+		}
+		if alpha < 0 || alpha > 1 {
+			return nil, fmt.Errorf("node %s: alpha must be within [0, 1]", n.Name)
+		}
+		return NewGaussMarkov(start, c.Area, alpha, n.Speed, n.Sigma, c.interval, rng), nil
+	case "trace":
+		path := n.TraceFile
+		if path != "" && !filepath.IsAbs(path) {
+			path = filepath.Join(c.dir, path)
+		}
+		points, err := loadTrace(path)
+		if err != nil {
+			return nil, fmt.Errorf("node %s: %w", n.Name, err)
+		}
+		return &Trace{points: points}, nil
+	}
+	return nil, fmt.Errorf("node %s: unknown mobility model %q", n.Name, n.Model)
+}
+
+// loadTrace 读取 time_ms,x,y 格式的位置轨迹，首行可以是表头
+func loadTrace(path string) ([]waypoint, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.Comment = '#'
+	r.TrimLeadingSpace = true
+
+	var points []waypoint
+	for row := 1; ; row++ {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(rec) != 3 {
+			return nil, fmt.Errorf("%s:%d: expected time_ms,x,y", path, row)
+		}
+		ms, err := strconv.ParseUint(rec[0], 10, 64)
+		if err != nil {
+			if row == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("%s:%d: invalid time %q", path, row, rec[0])
+		}
+		x, errX := strconv.ParseFloat(rec[1], 64)
+		y, errY := strconv.ParseFloat(rec[2], 64)
+		if errX != nil || errY != nil {
+			return nil, fmt.Errorf("%s:%d: invalid coordinates", path, row)
+		}
+		p := waypoint{at: time.Duration(ms) * time.Millisecond, pos: Vec2{x, y}}
+		if n := len(points); n > 0 && p.at <= points[n-1].at {
+			return nil, fmt.Errorf("%s:%d: time must be increasing", path, row)
+		}
+		points = append(points, p)
+	}
+	if len(points) == 0 {
+		return nil, fmt.Errorf("%s: empty trace", path)
+	}
+	return points, nil
+}
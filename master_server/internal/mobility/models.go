@@ -0,0 +1,156 @@
+package mobility
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Vec2 平面坐标（米）
+type Vec2 struct{ X, Y float64 }
+
+// Dist 两点间距离
+func (a Vec2) Dist(b Vec2) float64 { return math.Hypot(a.X-b.X, a.Y-b.Y) }
+
+// Area 节点活动的矩形区域
+type Area struct {
+	Width  float64 `yaml:"width"`
+	Height float64 `yaml:"height"`
+}
+
+// Model 节点移动模型，Position 返回仿真时刻 t 的位置，t 单调递增
+type Model interface {
+	Position(t time.Duration) Vec2
+}
+
+// Static 静止节点
+type Static struct{ Pos Vec2 }
+
+func (s *Static) Position(time.Duration) Vec2 { return s.Pos }
+
+// RandomWaypoint 随机路点模型：在区域内随机选取目标点，以随机速度直线前往，到达后停留一段时间
+type RandomWaypoint struct {
+	area     Area
+	minSpeed float64
+	maxSpeed float64
+	pause    time.Duration
+	rng      *rand.Rand
+
+	from, to   Vec2
+	legStart   time.Duration // 当前航段的出发时刻
+	legEnd     time.Duration // 到达目标点的时刻
+	pauseUntil time.Duration
+}
+
+func NewRandomWaypoint(start Vec2, area Area, minSpeed, maxSpeed float64, pause time.Duration, rng *rand.Rand) *RandomWaypoint {
+	m := &RandomWaypoint{area: area, minSpeed: minSpeed, maxSpeed: maxSpeed, pause: pause, rng: rng, from: start, to: start}
+	m.nextLeg(0)
+	return m
+}
+
+func (m *RandomWaypoint) nextLeg(now time.Duration) {
+	m.from = m.to
+	m.to = Vec2{m.rng.Float64() * m.area.Width, m.rng.Float64() * m.area.Height}
+	speed := m.minSpeed + m.rng.Float64()*(m.maxSpeed-m.minSpeed)
+	if speed <= 0 {
+		speed = 1
+	}
+	m.legStart = now
+	m.legEnd = now + time.Duration(m.from.Dist(m.to)/speed*float64(time.Second))
+	m.pauseUntil = m.legEnd + m.pause
+}
+
+func (m *RandomWaypoint) Position(t time.Duration) Vec2 {
+	for t >= m.pauseUntil {
+		m.nextLeg(m.pauseUntil)
+	}
+	if t >= m.legEnd {
+		return m.to
+	}
+	frac := float64(t-m.legStart) / float64(m.legEnd-m.legStart)
+	return Vec2{m.from.X + (m.to.X-m.from.X)*frac, m.from.Y + (m.to.Y-m.from.Y)*frac}
+}
+
+// GaussMarkov 高斯-马尔可夫模型：速度与方向在均值附近随时间相关地随机变化，
+// alpha 越接近1运动越平滑。碰到区域边界时反向。
+type GaussMarkov struct {
+	area      Area
+	alpha     float64
+	meanSpeed float64
+	meanDir   float64
+	sigma     float64
+	interval  time.Duration
+	rng       *rand.Rand
+
+	pos   Vec2
+	speed float64
+	dir   float64
+	last  time.Duration
+}
+
+func NewGaussMarkov(start Vec2, area Area, alpha, meanSpeed, sigma float64, interval time.Duration, rng *rand.Rand) *GaussMarkov {
+	dir := rng.Float64() * 2 * math.Pi
+	return &GaussMarkov{
+		area: area, alpha: alpha, meanSpeed: meanSpeed, meanDir: dir, sigma: sigma,
+		interval: interval, rng: rng, pos: start, speed: meanSpeed, dir: dir,
+	}
+}
+
+func (m *GaussMarkov) Position(t time.Duration) Vec2 {
+	for m.last+m.interval <= t {
+		m.step(m.interval.Seconds())
+		m.last += m.interval
+	}
+	return m.pos
+}
+
+func (m *GaussMarkov) step(dt float64) {
+	a := m.alpha
+	k := math.Sqrt(1 - a*a)
+	m.speed = a*m.speed + (1-a)*m.meanSpeed + k*m.sigma*m.rng.NormFloat64()
+	if m.speed < 0 {
+		m.speed = 0
+	}
+	m.dir = a*m.dir + (1-a)*m.meanDir + k*m.sigma*m.rng.NormFloat64()*0.1
+
+	m.pos.X += m.speed * math.Cos(m.dir) * dt
+	m.pos.Y += m.speed * math.Sin(m.dir) * dt
+
+	// 边界反射，同时把均值方向转向区域内部
+	if m.pos.X < 0 || m.pos.X > m.area.Width {
+		m.pos.X = math.Max(0, math.Min(m.area.Width, m.pos.X))
+		m.dir = math.Pi - m.dir
+		m.meanDir = math.Pi - m.meanDir
+	}
+	if m.pos.Y < 0 || m.pos.Y > m.area.Height {
+		m.pos.Y = math.Max(0, math.Min(m.area.Height, m.pos.Y))
+		m.dir = -m.dir
+		m.meanDir = -m.meanDir
+	}
+}
+
+// waypoint 轨迹文件中的一个位置点
+type waypoint struct {
+	at  time.Duration
+	pos Vec2
+}
+
+// Trace 按轨迹文件中的时间与坐标线性插值，超出最后一个点后停在终点
+type Trace struct {
+	points []waypoint
+}
+
+func (m *Trace) Position(t time.Duration) Vec2 {
+	pts := m.points
+	if t <= pts[0].at {
+		return pts[0].pos
+	}
+	for i := 1; i < len(pts); i++ {
+		if t <= pts[i].at {
+			a, b := pts[i-1], pts[i]
+			frac := float64(t-a.at) / float64(b.at-a.at)
+			return Vec2{a.pos.X + (b.pos.X-a.pos.X)*frac, a.pos.Y + (b.pos.Y-a.pos.Y)*frac}
+		}
+	}
+	return pts[len(pts)-1].pos
+}
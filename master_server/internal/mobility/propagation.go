@@ -0,0 +1,128 @@
+package mobility
+
+import (
+	"fmt"
+	"math"
+)
+
+const speedOfLight = 299792458.0 // m/s
+
+// Propagation 将节点间距离映射为链路参数的无线传播配置
+type Propagation struct {
+	Model          string  `yaml:"model"`        // free_space、log_distance 或 two_ray
+	FrequencyHz    float64 `yaml:"frequency_hz"` // 载波频率，默认2.4GHz
+	TxPowerDbm     float64 `yaml:"tx_power_dbm"` // 发射功率，默认20dBm
+	AntennaGainDb  float64 `yaml:"antenna_gain_db"`
+	NoiseDbm       float64 `yaml:"noise_dbm"`          // 噪声底，默认-95dBm
+	PathLossExp    float64 `yaml:"path_loss_exponent"` // log_distance 的路径损耗指数，默认3
+	RefDistanceM   float64 `yaml:"ref_distance_m"`     // log_distance 的参考距离，默认1m
+	AntennaHeightM float64 `yaml:"antenna_height_m"`   // two_ray 的天线高度，默认1.5m
+
+	ChannelWidthHz  float64 `yaml:"channel_width_hz"`  // 信道带宽，默认20MHz
+	MaxBandwidthBps uint64  `yaml:"max_bandwidth_bps"` // 链路带宽上限，默认54Mbps
+	MinSnrDb        float64 `yaml:"min_snr_db"`        // 低于该信噪比视为断开，默认3dB
+	LossMidSnrDb    float64 `yaml:"loss_mid_snr_db"`   // 丢包率为50%时的信噪比，默认6dB
+	LossSlope       float64 `yaml:"loss_slope"`        // 丢包率随信噪比下降的陡峭程度，默认1.5
+	BaseDelayMs     float64 `yaml:"base_delay_ms"`     // 叠加在传播时延上的固定时延（MAC排队、处理等）
+}
+
+// LinkQuality 由距离计算出的链路参数
+type LinkQuality struct {
+	Up           bool
+	SnrDb        float64
+	BandwidthBps uint64
+	LossRate     float64
+	DelayMs      float64
+}
+
+func (p *Propagation) setDefaults() error {
+	if p.Model == "" {
+		p.Model = "log_distance"
+	}
+	switch p.Model {
+	case "free_space", "log_distance", "two_ray":
+	default:
+		return fmt.Errorf("unknown propagation model %q", p.Model)
+	}
+	if p.FrequencyHz == 0 {
+		p.FrequencyHz = 2.4e9
+	}
+	if p.TxPowerDbm == 0 {
+		p.TxPowerDbm = 20
+	}
+	if p.NoiseDbm == 0 {
+		p.NoiseDbm = -95
+	}
+	if p.PathLossExp == 0 {
+		p.PathLossExp = 3
+	}
+	if p.RefDistanceM == 0 {
+		p.RefDistanceM = 1
+	}
+	if p.AntennaHeightM == 0 {
+		p.AntennaHeightM = 1.5
+	}
+	if p.ChannelWidthHz == 0 {
+		p.ChannelWidthHz = 20e6
+	}
+	if p.MaxBandwidthBps == 0 {
+		p.MaxBandwidthBps = 54000000
+	}
+	if p.MinSnrDb == 0 {
+		p.MinSnrDb = 3
+	}
+	if p.LossMidSnrDb == 0 {
+		p.LossMidSnrDb = 6
+	}
+	if p.LossSlope == 0 {
+		p.LossSlope = 1.5
+	}
+	return nil
+}
+
+// freeSpaceLoss Friis自由空间路径损耗（dB）
+func (p *Propagation) freeSpaceLoss(d float64) float64 {
+	return 20*math.Log10(d) + 20*math.Log10(p.FrequencyHz) + 20*math.Log10(4*math.Pi/speedOfLight)
+}
+
+// PathLoss 距离 d（米）处的路径损耗（dB）
+func (p *Propagation) PathLoss(d float64) float64 {
+	if d < p.RefDistanceM {
+		d = p.RefDistanceM
+	}
+	switch p.Model {
+	case "free_space":
+		return p.freeSpaceLoss(d)
+	case "two_ray":
+		// 交叉距离以内按自由空间，以外按双射线地面反射模型（与频率无关，随距离四次方衰减）
+		h := p.AntennaHeightM
+		crossover := 4 * math.Pi * h * h * p.FrequencyHz / speedOfLight
+		if d <= crossover {
+			return p.freeSpaceLoss(d)
+		}
+		return 40*math.Log10(d) - 20*math.Log10(h*h)
+	default:
+		return p.freeSpaceLoss(p.RefDistanceM) + 10*p.PathLossExp*math.Log10(d/p.RefDistanceM)
+	}
+}
+
+// Quality 计算距离 d（米）处的链路参数：带宽按香农容量截断到上限，
+// 丢包率为信噪比的逻辑斯蒂函数，时延为传播时延加固定时延
+func (p *Propagation) Quality(d float64) LinkQuality {
+	rx := p.TxPowerDbm + 2*p.AntennaGainDb - p.PathLoss(d)
+	snr := rx - p.NoiseDbm
+
+	q := LinkQuality{
+		SnrDb:   snr,
+		DelayMs: d/speedOfLight*1000 + p.BaseDelayMs,
+	}
+	if snr < p.MinSnrDb {
+		return q
+	}
+	q.Up = true
+
+	capacity := p.ChannelWidthHz * math.Log2(1+math.Pow(10, snr/10))
+	q.BandwidthBps = uint64(math.Min(capacity, float64(p.MaxBandwidthBps)))
+	q.LossRate = 1 / (1 + math.Exp(p.LossSlope*(snr-p.LossMidSnrDb)))
+	return q
+}
@@ -0,0 +1,159 @@
+package mobility
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"time"
+
+	"netsimlation/distribute/master_server/internal/model"
+	"netsimlation/distribute/master_server/internal/store"
+)
+
+// Simulator 推进节点移动并把距离换算成链路参数写入 network_link 记录
+type Simulator struct {
+	cfg    *Config
+	models map[string]Model
+	sent   map[string]LinkQuality // 每条链路最近一次写入的参数
+}
+
+// NewSimulator 根据配置创建各节点的移动模型
+func NewSimulator(cfg *Config) (*Simulator, error) {
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	s := &Simulator{cfg: cfg, models: make(map[string]Model), sent: make(map[string]LinkQuality)}
+	for i := range cfg.Nodes {
+		n := &cfg.Nodes[i]
+		if _, ok := s.models[n.Name]; ok {
+			return nil, fmt.Errorf("duplicate node name %q", n.Name)
+		}
+		m, err := cfg.buildModel(n, rng)
+		if err != nil {
+			return nil, err
+		}
+		s.models[n.Name] = m
+	}
+	for _, l := range cfg.Links {
+		if l.ID == "" {
+			return nil, fmt.Errorf("link %s-%s: missing id", l.From, l.To)
+		}
+		for _, name := range []string{l.From, l.To} {
+			if _, ok := s.models[name]; !ok {
+				return nil, fmt.Errorf("link %s: unknown node %q", l.ID, name)
+			}
+		}
+	}
+	return s, nil
+}
+
+// Update 一条需要写入的链路修改
+type Update struct {
+	Link      string
+	DistanceM float64
+	Quality   LinkQuality
+	Patch     *model.LinkPatch
+}
+
+// Step 计算仿真时刻 t 的节点位置与链路参数，返回相对上次写入变化超过阈值的链路
+func (s *Simulator) Step(t time.Duration) []Update {
+	positions := make(map[string]Vec2, len(s.models))
+	for name, m := range s.models {
+		positions[name] = m.Position(t)
+	}
+
+	var updates []Update
+	for i := range s.cfg.Links {
+		l := &s.cfg.Links[i]
+		dist := positions[l.From].Dist(positions[l.To])
+		q := s.cfg.Propagation.Quality(dist)
+
+		prev, seen := s.sent[l.ID]
+		if seen && !s.changed(prev, q) {
+			continue
+		}
+		s.sent[l.ID] = q
+
+		patch := qualityPatch(q)
+		if !seen {
+			mac, node := l.SourceMAC, l.DestNodeID
+			patch.SourceMAC = &mac
+			patch.DestNodeID = &node
+		}
+		updates = append(updates, Update{Link: l.ID, DistanceM: dist, Quality: q, Patch: patch})
+	}
+	return updates
+}
+
+// changed 判断链路参数变化是否超过阈值
+func (s *Simulator) changed(prev, cur LinkQuality) bool {
+	th := s.cfg.Thresholds
+	if prev.Up != cur.Up {
+		return true
+	}
+	if !cur.Up {
+		return false
+	}
+	if math.Abs(cur.DelayMs-prev.DelayMs) >= th.DelayMs {
+		return true
+	}
+	if math.Abs(cur.LossRate-prev.LossRate) >= th.LossRate {
+		return true
+	}
+	if prev.BandwidthBps == 0 {
+		return cur.BandwidthBps != 0
+	}
+	ratio := math.Abs(float64(cur.BandwidthBps)-float64(prev.BandwidthBps)) / float64(prev.BandwidthBps)
+	return ratio >= th.BandwidthRatio
+}
+
+// qualityPatch 将链路参数转换为 NetworkLink 修改，超出通信范围时关闭链路
+func qualityPatch(q LinkQuality) *model.LinkPatch {
+	if !q.Up {
+		state := model.AdminDown
+		return &model.LinkPatch{AdminState: &state}
+	}
+	state := model.AdminUp
+	// 香农容量为比特每秒，链路带宽与数据面按字节每秒限速
+	bw := q.BandwidthBps / 8
+	loss := q.LossRate
+	delay := uint32(math.Round(q.DelayMs))
+	return &model.LinkPatch{BandwidthBps: &bw, PacketLossRate: &loss, DelayMs: &delay, AdminState: &state}
+}
+
+// Run 以配置的更新周期实时推进仿真并写入Redis，直到时长结束或上下文取消
+func (s *Simulator) Run(ctx context.Context, st *store.Store) error {
+	ticker := time.NewTicker(s.cfg.interval)
+	defer ticker.Stop()
+	start := time.Now()
+
+	for {
+		t := time.Since(start)
+		if s.cfg.duration > 0 && t > s.cfg.duration {
+			log.Printf("仿真时长 %v 已结束", s.cfg.duration)
+			return nil
+		}
+
+		for _, u := range s.Step(t) {
+			if _, err := st.UpsertLink(ctx, u.Link, u.Patch); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				log.Printf("更新链路 %s 失败: %v", u.Link, err)
+				continue
+			}
+			log.Printf("[%v] 链路 %s 距离 %.1fm SNR %.1fdB: %s", t.Truncate(time.Millisecond), u.Link, u.DistanceM, u.Quality.SnrDb, u.Patch.String())
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// LinkKeyPrefix 单条链路在Redis中的键前缀，完整键名为 network_link:<id>
+	LinkKeyPrefix = "network_link:"
+
+	// AdminUp/AdminDown 链路管理状态，关闭的链路在数据面丢弃全部匹配流量
+	AdminUp   = "up"
+	AdminDown = "down"
+)
+
+// NetworkLink 定义网络链路结构体
+type NetworkLink struct {
+	SourceMAC      string  `json:"source_mac"`               // 源MAC地址
+	SourceNodeID   int     `json:"source_node_id,omitempty"` // 源节点ID，用于网络分区判断
+	DestNodeID     int     `json:"dest_node_id"`             // 目的节点ID
+	PacketLossRate float64 `json:"packet_loss_rate"`         // 链路丢包率（0.0-1.0）
+	BandwidthBps   uint64  `json:"bandwidth_bps"`            // 链路带宽（字节每秒，即数据面的 throttle_rate_bps）
+	DelayMs        uint32  `json:"delay_ms"`                 // 链路延迟（毫秒）
+	CreatedAt      string  `json:"created_at"`               // 创建时间
+	// ApplyAt 主控时钟下的生效时刻（Unix毫秒），为0表示立即生效。
+	// 从节点按估计的时钟偏移在本地定时应用，避免Redis与网络延迟造成各主机生效时间不一致。
+	ApplyAt int64 `json:"apply_at,omitempty"`
+	// AdminState 管理状态，空值等同于 up
+	AdminState string `json:"admin_state,omitempty"`
+	// SourceContainer/DestContainer 以容器ID或名称代替MAC和网卡描述链路端点，由从节点通过本机容器运行时解析：
+	// 源容器解析为其网卡MAC（未设置 source_mac 时使用），目的容器解析为宿主机侧的veth，
+	// 链路条目写在该veth上；目的容器不在本机的从节点忽略该链路。
+	SourceContainer string `json:"source_container,omitempty"`
+	DestContainer   string `json:"dest_container,omitempty"`
+}
+
+// LinkKey 根据链路ID生成Redis键名
+func LinkKey(id string) string {
+	return fmt.Sprintf("%s%s", LinkKeyPrefix, id)
+}
+
+// LinkPatch 描述对链路的局部修改，nil 字段保持原值
+type LinkPatch struct {
+	SourceMAC      *string  `json:"source_mac,omitempty" yaml:"source_mac,omitempty"`
+	DestNodeID     *int     `json:"dest_node_id,omitempty" yaml:"dest_node_id,omitempty"`
+	PacketLossRate *float64 `json:"packet_loss_rate,omitempty" yaml:"packet_loss_rate,omitempty"`
+	BandwidthBps   *uint64  `json:"bandwidth_bps,omitempty" yaml:"bandwidth_bps,omitempty"`
+	DelayMs        *uint32  `json:"delay_ms,omitempty" yaml:"delay_ms,omitempty"`
+	ApplyAt        *int64   `json:"apply_at,omitempty" yaml:"apply_at,omitempty"`
+	AdminState     *string  `json:"admin_state,omitempty" yaml:"admin_state,omitempty"`
+
+	SourceContainer *string `json:"source_container,omitempty" yaml:"source_container,omitempty"`
+	DestContainer   *string `json:"dest_container,omitempty" yaml:"dest_container,omitempty"`
+}
+
+// SetAdminState 返回只修改管理状态的 LinkPatch
+func SetAdminState(state string) *LinkPatch {
+	return &LinkPatch{AdminState: &state}
+}
+
+// IsDown 链路是否被管理性关闭
+func (l *NetworkLink) IsDown() bool {
+	return l.AdminState == AdminDown
+}
+
+// Apply 将修改合并到链路上
+func (p *LinkPatch) Apply(link *NetworkLink) {
+	if p.SourceMAC != nil {
+		link.SourceMAC = *p.SourceMAC
+	}
+	if p.DestNodeID != nil {
+		link.DestNodeID = *p.DestNodeID
+	}
+	if p.PacketLossRate != nil {
+		link.PacketLossRate = *p.PacketLossRate
+	}
+	if p.BandwidthBps != nil {
+		link.BandwidthBps = *p.BandwidthBps
+	}
+	if p.DelayMs != nil {
+		link.DelayMs = *p.DelayMs
+	}
+	if p.AdminState != nil {
+		link.AdminState = *p.AdminState
+	}
+	if p.SourceContainer != nil {
+		link.SourceContainer = *p.SourceContainer
+	}
+	if p.DestContainer != nil {
+		link.DestContainer = *p.DestContainer
+	}
+	// apply_at 只对本次修改有效，不继承上一次修改的生效时刻
+	link.ApplyAt = 0
+	if p.ApplyAt != nil {
+		link.ApplyAt = *p.ApplyAt
+	}
+}
+
+// String 以 字段=值 的形式描述修改内容，用于日志与计划打印
+func (p *LinkPatch) String() string {
+	var parts []string
+	if p.SourceMAC != nil {
+		parts = append(parts, fmt.Sprintf("source_mac=%s", *p.SourceMAC))
+	}
+	if p.DestNodeID != nil {
+		parts = append(parts, fmt.Sprintf("dest_node_id=%d", *p.DestNodeID))
+	}
+	if p.PacketLossRate != nil {
+		parts = append(parts, fmt.Sprintf("packet_loss_rate=%g", *p.PacketLossRate))
+	}
+	if p.BandwidthBps != nil {
+		parts = append(parts, fmt.Sprintf("bandwidth_bps=%d", *p.BandwidthBps))
+	}
+	if p.DelayMs != nil {
+		parts = append(parts, fmt.Sprintf("delay_ms=%d", *p.DelayMs))
+	}
+	if p.AdminState != nil {
+		parts = append(parts, fmt.Sprintf("admin_state=%s", *p.AdminState))
+	}
+	if p.SourceContainer != nil {
+		parts = append(parts, fmt.Sprintf("source_container=%s", *p.SourceContainer))
+	}
+	if p.DestContainer != nil {
+		parts = append(parts, fmt.Sprintf("dest_container=%s", *p.DestContainer))
+	}
+	if p.ApplyAt != nil {
+		parts = append(parts, fmt.Sprintf("apply_at=%d", *p.ApplyAt))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Node 参与实验的节点，链路通过 source_node_id/dest_node_id 引用节点ID
+type Node struct {
+	ID     int               `json:"id"`
+	Name   string            `json:"name"`
+	Host   string            `json:"host,omitempty"`   // 所在主机（对应从节点 node_id）
+	MACs   []string          `json:"macs,omitempty"`   // 节点使用的MAC地址
+	Labels map[string]string `json:"labels,omitempty"` // 自定义标签
+}
+
+// LinkRecord 带ID的链路，用于列表与API传输
+type LinkRecord struct {
+	ID string `json:"id"`
+	NetworkLink
+}
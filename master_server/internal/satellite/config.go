@@ -0,0 +1,102 @@
+package satellite
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Config 星座链路生成器配置
+type Config struct {
+	TLEFile        string          `yaml:"tle_file"`        // TLE文件，相对路径相对于配置文件
+	Satellites     []Elements      `yaml:"satellites"`      // 直接给出轨道根数的卫星
+	GroundStations []GroundStation `yaml:"ground_stations"` // 地面站
+
+	MinElevationDeg   float64 `yaml:"min_elevation_deg"`   // 星地链路最小仰角，默认10度
+	ISLMarginKm       float64 `yaml:"isl_margin_km"`       // 星间链路与地表的最小间隙，默认80km
+	MaxRangeKm        float64 `yaml:"max_range_km"`        // 链路最大距离，0表示不限制
+	ProcessingDelayMs float64 `yaml:"processing_delay_ms"` // 叠加在传播时延上的固定时延
+
+	Step             string  `yaml:"step"`               // 计算步长，默认1s
+	Duration         string  `yaml:"duration"`           // 仿真时长，默认10m
+	DelayThresholdMs float64 `yaml:"delay_threshold_ms"` // 时延变化超过该值才生成更新，默认1ms
+
+	Links []LinkSpec `yaml:"links"`
+
+	step     time.Duration
+	duration time.Duration
+}
+
+// LinkSpec 一条由卫星几何驱动的网络链路，From/To 为卫星或地面站名称
+type LinkSpec struct {
+	ID             string  `yaml:"id"`
+	From           string  `yaml:"from"`
+	To             string  `yaml:"to"`
+	SourceMAC      string  `yaml:"source_mac"`
+	DestNodeID     int     `yaml:"dest_node_id"`
+	BandwidthBps   uint64  `yaml:"bandwidth_bps"`
+	PacketLossRate float64 `yaml:"packet_loss_rate"`
+}
+
+// LoadConfig 读取YAML配置并填充默认值
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	if cfg.TLEFile != "" {
+		tlePath := cfg.TLEFile
+		if !filepath.IsAbs(tlePath) {
+			tlePath = filepath.Join(filepath.Dir(path), tlePath)
+		}
+		sats, err := ParseTLEFile(tlePath)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Satellites = append(cfg.Satellites, sats...)
+	}
+
+	for i := range cfg.Satellites {
+		el := &cfg.Satellites[i]
+		if el.EpochStr != "" {
+			el.Epoch, err = time.Parse(time.RFC3339, el.EpochStr)
+			if err != nil {
+				return nil, fmt.Errorf("satellite %s: invalid epoch %q", el.Name, el.EpochStr)
+			}
+		}
+		if el.MeanMotion <= 0 && el.AltitudeKm <= 0 {
+			return nil, fmt.Errorf("satellite %s: mean_motion or altitude_km required", el.Name)
+		}
+	}
+
+	if cfg.MinElevationDeg == 0 {
+		cfg.MinElevationDeg = 10
+	}
+	if cfg.ISLMarginKm == 0 {
+		cfg.ISLMarginKm = 80
+	}
+	if cfg.DelayThresholdMs == 0 {
+		cfg.DelayThresholdMs = 1
+	}
+	if cfg.Step == "" {
+		cfg.Step = "1s"
+	}
+	if cfg.Duration == "" {
+		cfg.Duration = "10m"
+	}
+	if cfg.step, err = time.ParseDuration(cfg.Step); err != nil || cfg.step <= 0 {
+		return nil, fmt.Errorf("invalid step %q", cfg.Step)
+	}
+	if cfg.duration, err = time.ParseDuration(cfg.Duration); err != nil || cfg.duration <= 0 {
+		return nil, fmt.Errorf("invalid duration %q", cfg.Duration)
+	}
+	return &cfg, nil
+}
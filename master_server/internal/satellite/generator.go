@@ -0,0 +1,163 @@
+package satellite
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"netsimlation/distribute/master_server/internal/model"
+	"netsimlation/distribute/master_server/internal/scenario"
+)
+
+// LinkState 某一时刻链路的几何状态
+type LinkState struct {
+	Up         bool
+	DistanceKm float64
+	DelayMs    float64
+}
+
+// endpoint 链路端点：卫星或地面站
+type endpoint struct {
+	orbit   *Orbit
+	station *GroundStation
+}
+
+func (e endpoint) position(t time.Time) Vec3 {
+	if e.orbit != nil {
+		return e.orbit.PositionECEF(t)
+	}
+	return e.station.ECEF()
+}
+
+// Generator 根据卫星轨道与地面站坐标计算随时间变化的链路时延与通断
+type Generator struct {
+	cfg       *Config
+	start     time.Time
+	endpoints map[string]endpoint
+}
+
+// NewGenerator 创建生成器，start 为仿真零时刻，同时作为未给出历元的轨道根数的历元
+func NewGenerator(cfg *Config, start time.Time) (*Generator, error) {
+	g := &Generator{cfg: cfg, start: start, endpoints: make(map[string]endpoint)}
+	for _, el := range cfg.Satellites {
+		if _, ok := g.endpoints[el.Name]; ok {
+			return nil, fmt.Errorf("duplicate node name %q", el.Name)
+		}
+		g.endpoints[el.Name] = endpoint{orbit: NewOrbit(el, start)}
+	}
+	for i := range cfg.GroundStations {
+		gs := &cfg.GroundStations[i]
+		if _, ok := g.endpoints[gs.Name]; ok {
+			return nil, fmt.Errorf("duplicate node name %q", gs.Name)
+		}
+		g.endpoints[gs.Name] = endpoint{station: gs}
+	}
+	for _, l := range cfg.Links {
+		if l.ID == "" {
+			return nil, fmt.Errorf("link %s-%s: missing id", l.From, l.To)
+		}
+		for _, name := range []string{l.From, l.To} {
+			if _, ok := g.endpoints[name]; !ok {
+				return nil, fmt.Errorf("link %s: unknown node %q", l.ID, name)
+			}
+		}
+	}
+	return g, nil
+}
+
+// State 计算 t 时刻链路的距离、时延与可见性
+func (g *Generator) State(l *LinkSpec, t time.Time) LinkState {
+	a, b := g.endpoints[l.From], g.endpoints[l.To]
+	pa, pb := a.position(t), b.position(t)
+	dist := pb.Sub(pa).Norm()
+
+	var up bool
+	switch {
+	case a.station != nil && b.station != nil:
+		// 地面站之间不经由卫星，视为地面专线
+		up = true
+	case a.station != nil:
+		up = a.station.Elevation(pb) >= g.cfg.MinElevationDeg
+	case b.station != nil:
+		up = b.station.Elevation(pa) >= g.cfg.MinElevationDeg
+	default:
+		up = lineOfSight(pa, pb, g.cfg.ISLMarginKm)
+	}
+	if g.cfg.MaxRangeKm > 0 && dist > g.cfg.MaxRangeKm {
+		up = false
+	}
+
+	return LinkState{
+		Up:         up,
+		DistanceKm: dist,
+		DelayMs:    propagationDelayMs(dist) + g.cfg.ProcessingDelayMs,
+	}
+}
+
+// Timeline 按步长采样整个仿真时长，生成链路变更时间线。
+// 第0秒的事件包含链路的完整参数；此后仅在通断变化或时延变化超过阈值时生成事件。
+func (g *Generator) Timeline() (*scenario.Scenario, error) {
+	// 第0秒的事件创建全部链路
+	sc := &scenario.Scenario{Name: "satellite-constellation", Upsert: true}
+	last := make(map[string]LinkState, len(g.cfg.Links))
+
+	for offset := time.Duration(0); offset <= g.cfg.duration; offset += g.cfg.step {
+		t := g.start.Add(offset)
+		for i := range g.cfg.Links {
+			l := &g.cfg.Links[i]
+			st := g.State(l, t)
+			prev, seen := last[l.ID]
+
+			var patch *model.LinkPatch
+			switch {
+			case !seen:
+				patch = g.fullPatch(l, st)
+			case st.Up != prev.Up:
+				patch = g.statePatch(l, st)
+			case st.Up && math.Abs(st.DelayMs-prev.DelayMs) >= g.cfg.DelayThresholdMs:
+				delay := roundDelay(st.DelayMs)
+				patch = &model.LinkPatch{DelayMs: &delay}
+			default:
+				continue
+			}
+
+			// 只在生成事件时记录状态，避免缓慢漂移因逐步比较而被忽略
+			last[l.ID] = st
+			sc.Events = append(sc.Events, scenario.Event{
+				At:   offset.String(),
+				Link: l.ID,
+				Set:  patch,
+			})
+		}
+	}
+
+	if err := sc.Prepare(); err != nil {
+		return nil, err
+	}
+	return sc, nil
+}
+
+// fullPatch 链路初始状态的完整参数
+func (g *Generator) fullPatch(l *LinkSpec, st LinkState) *model.LinkPatch {
+	patch := g.statePatch(l, st)
+	mac, node, loss, bw := l.SourceMAC, l.DestNodeID, l.PacketLossRate, l.BandwidthBps
+	patch.SourceMAC = &mac
+	patch.DestNodeID = &node
+	patch.PacketLossRate = &loss
+	patch.BandwidthBps = &bw
+	return patch
+}
+
+// statePatch 通断变化时的管理状态与时延
+func (g *Generator) statePatch(l *LinkSpec, st LinkState) *model.LinkPatch {
+	state := model.AdminUp
+	if !st.Up {
+		state = model.AdminDown
+	}
+	delay := roundDelay(st.DelayMs)
+	return &model.LinkPatch{AdminState: &state, DelayMs: &delay}
+}
+
+func roundDelay(ms float64) uint32 {
+	return uint32(math.Round(ms))
+}
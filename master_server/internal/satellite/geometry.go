@@ -0,0 +1,55 @@
+package satellite
+
+import "math"
+
+// GroundStation 地面站，坐标为WGS84大地坐标
+type GroundStation struct {
+	Name string  `yaml:"name"`
+	Lat  float64 `yaml:"lat"`   // 纬度（度）
+	Lon  float64 `yaml:"lon"`   // 经度（度）
+	AltM float64 `yaml:"alt_m"` // 海拔（米）
+}
+
+// ECEF 地面站在地固系中的位置
+func (g *GroundStation) ECEF() Vec3 {
+	lat, lon := g.Lat*deg, g.Lon*deg
+	alt := g.AltM / 1000
+	e2 := earthFlat * (2 - earthFlat)
+	n := earthRadius / math.Sqrt(1-e2*math.Sin(lat)*math.Sin(lat))
+	return Vec3{
+		X: (n + alt) * math.Cos(lat) * math.Cos(lon),
+		Y: (n + alt) * math.Cos(lat) * math.Sin(lon),
+		Z: (n*(1-e2) + alt) * math.Sin(lat),
+	}
+}
+
+// Elevation 从地面站看目标的仰角（度）
+func (g *GroundStation) Elevation(target Vec3) float64 {
+	lat, lon := g.Lat*deg, g.Lon*deg
+	up := Vec3{math.Cos(lat) * math.Cos(lon), math.Cos(lat) * math.Sin(lon), math.Sin(lat)}
+	d := target.Sub(g.ECEF())
+	return math.Asin(d.Dot(up)/d.Norm()) / deg
+}
+
+// lineOfSight 判断两点连线是否高于地球表面 marginKm 以上（星间链路不被地球与大气层遮挡）
+func lineOfSight(a, b Vec3, marginKm float64) bool {
+	d := b.Sub(a)
+	l2 := d.Dot(d)
+	if l2 == 0 {
+		return true
+	}
+	// 连线上距地心最近点的参数
+	t := -a.Dot(d) / l2
+	if t < 0 {
+		t = 0
+	} else if t > 1 {
+		t = 1
+	}
+	closest := Vec3{a.X + d.X*t, a.Y + d.Y*t, a.Z + d.Z*t}
+	return closest.Norm() > earthRadius+marginKm
+}
+
+// propagationDelayMs 光速传播时延（毫秒）
+func propagationDelayMs(distKm float64) float64 {
+	return distKm / speedOfLight * 1000
+}
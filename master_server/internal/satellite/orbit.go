@@ -0,0 +1,115 @@
+package satellite
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	earthMu      = 398600.4418 // 地球引力常数 km^3/s^2
+	earthRadius  = 6378.137    // WGS84赤道半径 km
+	earthFlat    = 1 / 298.257223563
+	earthJ2      = 1.08262668e-3
+	speedOfLight = 299792.458 // km/s
+	deg          = math.Pi / 180
+)
+
+// Vec3 三维坐标（km）
+type Vec3 struct{ X, Y, Z float64 }
+
+func (a Vec3) Sub(b Vec3) Vec3      { return Vec3{a.X - b.X, a.Y - b.Y, a.Z - b.Z} }
+func (a Vec3) Dot(b Vec3) float64   { return a.X*b.X + a.Y*b.Y + a.Z*b.Z }
+func (a Vec3) Norm() float64        { return math.Sqrt(a.Dot(a)) }
+func (a Vec3) Scale(k float64) Vec3 { return Vec3{a.X * k, a.Y * k, a.Z * k} }
+
+// Orbit 由轨道根数预先计算的传播参数。
+// 采用二体模型加J2长期摄动（升交点与近地点的进动），对分钟到小时级的LEO链路时延足够精确。
+type Orbit struct {
+	el       Elements
+	a        float64 // 半长轴 km
+	n        float64 // 平均角速度 rad/s
+	raanRate float64 // rad/s
+	argpRate float64 // rad/s
+	epoch    time.Time
+}
+
+// NewOrbit 根据轨道根数创建轨道，epoch 为零值时使用 defaultEpoch
+func NewOrbit(el Elements, defaultEpoch time.Time) *Orbit {
+	o := &Orbit{el: el, epoch: el.Epoch}
+	if o.epoch.IsZero() {
+		o.epoch = defaultEpoch
+	}
+
+	if el.MeanMotion > 0 {
+		o.n = el.MeanMotion * 2 * math.Pi / 86400
+		o.a = math.Cbrt(earthMu / (o.n * o.n))
+	} else {
+		o.a = earthRadius + el.AltitudeKm
+		o.n = math.Sqrt(earthMu / (o.a * o.a * o.a))
+	}
+
+	p := o.a * (1 - el.Eccentricity*el.Eccentricity)
+	k := 1.5 * earthJ2 * (earthRadius / p) * (earthRadius / p) * o.n
+	cosI := math.Cos(el.Inclination * deg)
+	o.raanRate = -k * cosI
+	o.argpRate = k * (2 - 2.5*(1-cosI*cosI))
+	return o
+}
+
+// Name 卫星名称
+func (o *Orbit) Name() string { return o.el.Name }
+
+// PositionECI 计算 t 时刻在地心惯性系中的位置
+func (o *Orbit) PositionECI(t time.Time) Vec3 {
+	dt := t.Sub(o.epoch).Seconds()
+	e := o.el.Eccentricity
+
+	M := math.Mod(o.el.MeanAnomaly*deg+o.n*dt, 2*math.Pi)
+	// 牛顿迭代求解开普勒方程 E - e*sinE = M
+	E := M
+	for i := 0; i < 10; i++ {
+		dE := (E - e*math.Sin(E) - M) / (1 - e*math.Cos(E))
+		E -= dE
+		if math.Abs(dE) < 1e-12 {
+			break
+		}
+	}
+	nu := 2 * math.Atan2(math.Sqrt(1+e)*math.Sin(E/2), math.Sqrt(1-e)*math.Cos(E/2))
+	r := o.a * (1 - e*math.Cos(E))
+
+	raan := o.el.RAAN*deg + o.raanRate*dt
+	argp := o.el.ArgPerigee*deg + o.argpRate*dt
+	inc := o.el.Inclination * deg
+	u := argp + nu
+
+	cosR, sinR := math.Cos(raan), math.Sin(raan)
+	cosU, sinU := math.Cos(u), math.Sin(u)
+	cosI, sinI := math.Cos(inc), math.Sin(inc)
+	return Vec3{
+		X: r * (cosR*cosU - sinR*sinU*cosI),
+		Y: r * (sinR*cosU + cosR*sinU*cosI),
+		Z: r * (sinU * sinI),
+	}
+}
+
+// PositionECEF 计算 t 时刻在地固系中的位置
+func (o *Orbit) PositionECEF(t time.Time) Vec3 {
+	return eciToECEF(o.PositionECI(t), t)
+}
+
+// gmst 格林尼治平恒星时（弧度）
+func gmst(t time.Time) float64 {
+	jd := float64(t.UnixNano())/float64(24*time.Hour) + 2440587.5
+	theta := 280.46061837 + 360.98564736629*(jd-2451545.0)
+	return math.Mod(theta, 360) * deg
+}
+
+func eciToECEF(p Vec3, t time.Time) Vec3 {
+	theta := gmst(t)
+	c, s := math.Cos(theta), math.Sin(theta)
+	return Vec3{
+		X: c*p.X + s*p.Y,
+		Y: -s*p.X + c*p.Y,
+		Z: p.Z,
+	}
+}
@@ -0,0 +1,134 @@
+package satellite
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Elements 经典轨道根数，角度单位为度
+type Elements struct {
+	Name         string    `yaml:"name"`
+	Epoch        time.Time `yaml:"-"`
+	EpochStr     string    `yaml:"epoch"`           // RFC3339，为空时取仿真开始时间
+	Inclination  float64   `yaml:"inclination_deg"` // 轨道倾角
+	RAAN         float64   `yaml:"raan_deg"`        // 升交点赤经
+	Eccentricity float64   `yaml:"eccentricity"`    // 偏心率
+	ArgPerigee   float64   `yaml:"arg_perigee_deg"` // 近地点幅角
+	MeanAnomaly  float64   `yaml:"mean_anomaly_deg"`
+	MeanMotion   float64   `yaml:"mean_motion"` // 平均运动（圈/天），与 altitude_km 二选一
+	AltitudeKm   float64   `yaml:"altitude_km"` // 圆轨道高度
+}
+
+// ParseTLEFile 读取两行或三行格式的TLE文件
+func ParseTLEFile(path string) ([]Elements, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	var sats []Elements
+	for i := 0; i < len(lines); {
+		name := ""
+		if !strings.HasPrefix(lines[i], "1 ") {
+			name = strings.TrimSpace(strings.TrimPrefix(lines[i], "0 "))
+			i++
+		}
+		if i+1 >= len(lines) {
+			return nil, fmt.Errorf("%s: truncated TLE near line %d", path, i+1)
+		}
+		el, err := ParseTLE(name, lines[i], lines[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		sats = append(sats, el)
+		i += 2
+	}
+	return sats, nil
+}
+
+// ParseTLE 解析一组TLE，字段按NORAD定长列格式读取
+func ParseTLE(name, line1, line2 string) (Elements, error) {
+	var el Elements
+	if len(line1) < 32 || !strings.HasPrefix(line1, "1 ") {
+		return el, fmt.Errorf("invalid TLE line 1: %q", line1)
+	}
+	if len(line2) < 63 || !strings.HasPrefix(line2, "2 ") {
+		return el, fmt.Errorf("invalid TLE line 2: %q", line2)
+	}
+	if name == "" {
+		name = strings.TrimSpace(line1[2:7])
+	}
+	el.Name = name
+
+	epoch, err := parseTLEEpoch(line1[18:32])
+	if err != nil {
+		return el, fmt.Errorf("%s: %w", name, err)
+	}
+	el.Epoch = epoch
+
+	fields := []struct {
+		dst *float64
+		raw string
+	}{
+		{&el.Inclination, line2[8:16]},
+		{&el.RAAN, line2[17:25]},
+		{&el.ArgPerigee, line2[34:42]},
+		{&el.MeanAnomaly, line2[43:51]},
+		{&el.MeanMotion, line2[52:63]},
+	}
+	for _, f := range fields {
+		v, err := strconv.ParseFloat(strings.TrimSpace(f.raw), 64)
+		if err != nil {
+			return el, fmt.Errorf("%s: invalid TLE field %q", name, f.raw)
+		}
+		*f.dst = v
+	}
+	// 偏心率省略了前导小数点
+	ecc, err := strconv.ParseFloat("0."+strings.TrimSpace(line2[26:33]), 64)
+	if err != nil {
+		return el, fmt.Errorf("%s: invalid eccentricity %q", name, line2[26:33])
+	}
+	el.Eccentricity = ecc
+	return el, nil
+}
+
+// parseTLEEpoch 解析 YYDDD.DDDDDDDD 格式的历元
+func parseTLEEpoch(raw string) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if len(raw) < 5 {
+		return time.Time{}, fmt.Errorf("invalid TLE epoch %q", raw)
+	}
+	yy, err := strconv.Atoi(raw[:2])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid TLE epoch %q", raw)
+	}
+	day, err := strconv.ParseFloat(raw[2:], 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid TLE epoch %q", raw)
+	}
+	year := 2000 + yy
+	if yy >= 57 {
+		year = 1900 + yy
+	}
+	whole, frac := math.Modf(day)
+	start := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+	return start.AddDate(0, 0, int(whole)-1).Add(time.Duration(frac * float64(24*time.Hour))), nil
+}
@@ -0,0 +1,286 @@
+package scenario
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"netsimlation/distribute/master_server/internal/store"
+)
+
+// State 场景执行状态
+type State string
+
+const (
+	StateIdle     State = "idle"
+	StateRunning  State = "running"
+	StatePaused   State = "paused"
+	StateFinished State = "finished"
+	StateAborted  State = "aborted"
+)
+
+// 控制频道与状态键，其他进程可通过 PUBLISH netsim:scenario:control pause|resume|abort 控制执行
+const (
+	ControlChannel = "netsim:scenario:control"
+	StatusKey      = "netsim:scenario:status"
+)
+
+// ErrAborted 场景被中止
+var ErrAborted = errors.New("scenario aborted")
+
+type command int
+
+const (
+	cmdPause command = iota
+	cmdResume
+	cmdAbort
+)
+
+// Runner 以实验开始时间为基准按毫秒精度执行时间线。
+// 暂停期间时间线整体顺延，恢复后剩余事件保持原有间隔。
+type Runner struct {
+	st    *store.Store
+	sc    *Scenario
+	start time.Time
+	lead  time.Duration
+
+	ctrl chan command
+
+	mu    sync.Mutex
+	state State
+	next  int
+	shift time.Duration // 累计暂停时长
+}
+
+// NewRunner 创建场景执行器，start 为偏移量的零点
+func NewRunner(st *store.Store, sc *Scenario, start time.Time) *Runner {
+	return &Runner{
+		st:    st,
+		sc:    sc,
+		start: start,
+		ctrl:  make(chan command, 4),
+		state: StateIdle,
+	}
+}
+
+// SetLead 启用从节点定时模式：set/up/down 事件提前 lead 写入Redis并携带 apply_at，
+// 由从节点按同步后的时钟在本地生效。delete 事件无法携带时间戳，仍在到期时执行。
+func (r *Runner) SetLead(lead time.Duration) {
+	r.lead = lead
+}
+
+// Pause 暂停执行
+func (r *Runner) Pause() { r.send(cmdPause) }
+
+// Resume 恢复执行
+func (r *Runner) Resume() { r.send(cmdResume) }
+
+// Abort 中止执行，未执行的事件将被丢弃
+func (r *Runner) Abort() { r.send(cmdAbort) }
+
+// send 投递控制命令，执行器已结束或队列已满时丢弃，避免调用方阻塞
+func (r *Runner) send(cmd command) {
+	select {
+	case r.ctrl <- cmd:
+	default:
+		log.Printf("场景控制命令队列已满，忽略命令 %d", cmd)
+	}
+}
+
+// State 返回当前状态与下一个待执行事件的序号
+func (r *Runner) State() (State, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.state, r.next
+}
+
+// Run 阻塞执行整个时间线，直到完成、中止或上下文取消
+func (r *Runner) Run(ctx context.Context) error {
+	r.setState(StateRunning)
+	log.Printf("场景 %q 开始，起点 %s，共 %d 个事件", r.sc.Name, r.start.Format(time.RFC3339Nano), len(r.sc.Events))
+
+	for n, i := range r.dispatchOrder() {
+		ev := &r.sc.Events[i]
+		if err := r.waitUntil(ctx, ev); err != nil {
+			if errors.Is(err, ErrAborted) {
+				r.setState(StateAborted)
+			}
+			return err
+		}
+
+		due := r.due(ev)
+		if err := r.execute(ctx, ev, due); err != nil {
+			log.Printf("事件 #%d 执行失败 (%s): %v", i, ev.Describe(), err)
+		} else {
+			log.Printf("事件 #%d @%v 已执行，偏差 %.3fms: %s", i, ev.Offset,
+				float64(time.Since(r.dispatchTime(ev)).Microseconds())/1000.0, ev.Describe())
+		}
+
+		r.mu.Lock()
+		r.next = n + 1
+		r.mu.Unlock()
+		r.publishStatus(ctx)
+	}
+
+	r.setState(StateFinished)
+	log.Printf("场景 %q 执行完成", r.sc.Name)
+	return nil
+}
+
+// due 计算事件的实际执行时刻（含暂停顺延）
+func (r *Runner) due(ev *Event) time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.start.Add(ev.Offset + r.shift)
+}
+
+// dispatchLead 返回事件需要提前写入Redis的时长
+func (r *Runner) dispatchLead(ev *Event) time.Duration {
+	if ev.Action == ActionDelete {
+		return 0
+	}
+	return r.lead
+}
+
+// dispatchTime 计算事件写入Redis的时刻
+func (r *Runner) dispatchTime(ev *Event) time.Time {
+	return r.due(ev).Add(-r.dispatchLead(ev))
+}
+
+// dispatchOrder 按写入Redis的先后返回事件序号
+func (r *Runner) dispatchOrder() []int {
+	order := make([]int, len(r.sc.Events))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ea, eb := &r.sc.Events[order[a]], &r.sc.Events[order[b]]
+		return ea.Offset-r.dispatchLead(ea) < eb.Offset-r.dispatchLead(eb)
+	})
+	return order
+}
+
+// waitUntil 等待事件到期，期间处理暂停、恢复与中止命令
+func (r *Runner) waitUntil(ctx context.Context, ev *Event) error {
+	for {
+		timer := time.NewTimer(time.Until(r.dispatchTime(ev)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+			return nil
+		case cmd := <-r.ctrl:
+			timer.Stop()
+			switch cmd {
+			case cmdAbort:
+				return ErrAborted
+			case cmdPause:
+				if err := r.paused(ctx); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// paused 阻塞直到恢复或中止，并把暂停时长计入顺延量
+func (r *Runner) paused(ctx context.Context) error {
+	pausedAt := time.Now()
+	r.setState(StatePaused)
+	log.Printf("场景 %q 已暂停", r.sc.Name)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case cmd := <-r.ctrl:
+			switch cmd {
+			case cmdAbort:
+				return ErrAborted
+			case cmdResume:
+				r.mu.Lock()
+				r.shift += time.Since(pausedAt)
+				shift := r.shift
+				r.mu.Unlock()
+				r.setState(StateRunning)
+				log.Printf("场景 %q 已恢复，时间线累计顺延 %v", r.sc.Name, shift)
+				return nil
+			}
+		}
+	}
+}
+
+// execute 将事件写入Redis，定时模式下为修改附加计划生效时刻 due
+func (r *Runner) execute(ctx context.Context, ev *Event, due time.Time) error {
+	switch ev.Action {
+	case ActionSet, ActionDown, ActionUp:
+		patch := ev.patch()
+		if r.lead > 0 {
+			applyAt := due.UnixMilli()
+			patch.ApplyAt = &applyAt
+		}
+		if ev.Action == ActionSet && r.sc.Upsert {
+			_, err := r.st.UpsertLink(ctx, ev.Link, patch)
+			return err
+		}
+		_, err := r.st.PatchLink(ctx, ev.Link, patch)
+		return err
+	case ActionDelete:
+		return r.st.DeleteLink(ctx, ev.Link)
+	}
+	return fmt.Errorf("unknown action %q", ev.Action)
+}
+
+func (r *Runner) setState(state State) {
+	r.mu.Lock()
+	r.state = state
+	r.mu.Unlock()
+	r.publishStatus(context.Background())
+}
+
+// publishStatus 将执行进度写入状态键，供其他工具查询
+func (r *Runner) publishStatus(ctx context.Context) {
+	state, next := r.State()
+	err := r.st.Client().HSet(ctx, StatusKey, map[string]interface{}{
+		"name":  r.sc.Name,
+		"state": string(state),
+		"next":  next,
+		"total": len(r.sc.Events),
+		"start": r.start.Format(time.RFC3339Nano),
+	}).Err()
+	if err != nil {
+		log.Printf("更新场景状态失败: %v", err)
+	}
+}
+
+// ListenControl 订阅控制频道，将收到的 pause/resume/abort 转发给执行器
+func (r *Runner) ListenControl(ctx context.Context) {
+	pubsub := r.st.Client().Subscribe(ctx, ControlChannel)
+	defer pubsub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-pubsub.Channel():
+			if !ok {
+				return
+			}
+			switch msg.Payload {
+			case "pause":
+				r.Pause()
+			case "resume":
+				r.Resume()
+			case "abort":
+				r.Abort()
+			default:
+				log.Printf("忽略未知的场景控制命令: %s", msg.Payload)
+			}
+		}
+	}
+}
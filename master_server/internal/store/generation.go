@@ -0,0 +1,249 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"netsimlation/distribute/master_server/internal/model"
+)
+
+// 配置代（generation）相关的键名。
+// 一代配置是一份完整的链路拓扑：先完整写入 netsim:gen:<n>，再通过一次 SET
+// 把 netsim:gen:active 指向它，从节点只在指针变化时整体应用，避免看到半更新的拓扑。
+const (
+	GenSeqKey     = "netsim:gen:seq"     // 代号自增计数器
+	GenActiveKey  = "netsim:gen:active"  // 当前生效的代号
+	GenHistoryKey = "netsim:gen:history" // 激活历史（表头为当前代），用于回滚
+
+	genKeyPrefix  = "netsim:gen:"
+	historyLength = 32
+	// generationRetention 写入新的一代时只保留代号最新的若干代，激活历史中的代始终保留以便回滚
+	generationRetention = 64
+)
+
+var (
+	// ErrNoGeneration 指定的配置代不存在
+	ErrNoGeneration = errors.New("generation does not exist")
+	// ErrNoPrevious 激活历史中没有可回滚的上一代
+	ErrNoPrevious = errors.New("no previous generation to roll back to")
+)
+
+// GenerationMeta 配置代的元信息，从节点据此校验链路条目是否完整
+type GenerationMeta struct {
+	ID        int64  `json:"id"`
+	LinkCount int    `json:"link_count"`
+	CreatedAt string `json:"created_at"`
+}
+
+// GenerationKey 返回保存某一代链路的哈希键名，字段为链路ID，值为 NetworkLink 的JSON
+func GenerationKey(gen int64) string {
+	return fmt.Sprintf("%s%d", genKeyPrefix, gen)
+}
+
+// GenerationMetaKey 返回某一代元信息的键名
+func GenerationMetaKey(gen int64) string {
+	return fmt.Sprintf("%s%d:meta", genKeyPrefix, gen)
+}
+
+// WriteGeneration 将完整的链路集合写为新的一代配置，但不激活它。
+// 超出 generationRetention 且不在激活历史中的旧代在同一事务中删除
+func (s *Store) WriteGeneration(ctx context.Context, links map[string]model.NetworkLink) (int64, error) {
+	gen, err := s.client.Incr(ctx, GenSeqKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("allocate generation id: %w", err)
+	}
+
+	fields := make(map[string]interface{}, len(links))
+	for id, link := range links {
+		data, err := json.Marshal(link)
+		if err != nil {
+			return 0, fmt.Errorf("marshal link %s: %w", id, err)
+		}
+		fields[id] = data
+	}
+
+	meta, err := json.Marshal(GenerationMeta{
+		ID:        gen,
+		LinkCount: len(links),
+		CreatedAt: time.Now().Format(time.RFC3339),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// 链路条目与元信息在同一事务中写入，元信息存在即表示该代已完整。
+	// 监视激活历史，避免删除读取历史之后才被激活的旧代
+	txf := func(tx *redis.Tx) error {
+		stale, err := s.staleGenerations(ctx, tx, gen)
+		if err != nil {
+			return err
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, GenerationKey(gen))
+			if len(fields) > 0 {
+				pipe.HSet(ctx, GenerationKey(gen), fields)
+			}
+			pipe.Set(ctx, GenerationMetaKey(gen), meta, 0)
+			for _, old := range stale {
+				pipe.Del(ctx, GenerationKey(old), GenerationMetaKey(old))
+			}
+			return nil
+		})
+		return err
+	}
+	for i := 0; i < 3; i++ {
+		err = s.client.Watch(ctx, txf, GenHistoryKey)
+		if err != redis.TxFailedErr {
+			break
+		}
+	}
+	if err != nil {
+		return 0, fmt.Errorf("write generation %d: %w", gen, err)
+	}
+	return gen, nil
+}
+
+// staleGenerations 返回代号不大于 newest-generationRetention 且不在激活历史中的已有代
+func (s *Store) staleGenerations(ctx context.Context, tx *redis.Tx, newest int64) ([]int64, error) {
+	history, err := tx.LRange(ctx, GenHistoryKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	keep := make(map[string]bool, len(history))
+	for _, h := range history {
+		keep[h] = true
+	}
+
+	var stale []int64
+	iter := tx.Scan(ctx, 0, genKeyPrefix+"*:meta", 1000).Iterator()
+	for iter.Next(ctx) {
+		id := strings.TrimSuffix(strings.TrimPrefix(iter.Val(), genKeyPrefix), ":meta")
+		gen, err := strconv.ParseInt(id, 10, 64)
+		if err != nil || keep[id] || gen > newest-generationRetention {
+			continue
+		}
+		stale = append(stale, gen)
+	}
+	return stale, iter.Err()
+}
+
+// Activate 将生效指针切换到指定代，并记录到激活历史
+func (s *Store) Activate(ctx context.Context, gen int64) error {
+	n, err := s.client.Exists(ctx, GenerationMetaKey(gen)).Result()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %d", ErrNoGeneration, gen)
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LPush(ctx, GenHistoryKey, gen)
+		pipe.LTrim(ctx, GenHistoryKey, 0, historyLength-1)
+		pipe.Set(ctx, GenActiveKey, gen, 0)
+		return nil
+	})
+	return err
+}
+
+// Rollback 将生效指针切回激活历史中的上一代，返回回滚后的代号。
+// 读取历史与修改在同一个 WATCH 事务中，并发的激活或回滚不会让指针与历史表头不一致
+func (s *Store) Rollback(ctx context.Context) (int64, error) {
+	var prev int64
+	txf := func(tx *redis.Tx) error {
+		history, err := tx.LRange(ctx, GenHistoryKey, 0, 1).Result()
+		if err != nil {
+			return err
+		}
+		if len(history) < 2 {
+			return ErrNoPrevious
+		}
+		prev, err = strconv.ParseInt(history[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid generation in history: %q", history[1])
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.LPop(ctx, GenHistoryKey)
+			pipe.Set(ctx, GenActiveKey, prev, 0)
+			return nil
+		})
+		return err
+	}
+
+	// 并发修改导致事务失败时重试
+	for i := 0; i < 3; i++ {
+		err := s.client.Watch(ctx, txf, GenHistoryKey, GenActiveKey)
+		if err == redis.TxFailedErr {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		return prev, nil
+	}
+	return 0, errors.New("rollback: too many concurrent modifications")
+}
+
+// ActiveGeneration 返回当前生效的代号，尚未激活任何代时返回0
+func (s *Store) ActiveGeneration(ctx context.Context) (int64, error) {
+	val, err := s.client.Get(ctx, GenActiveKey).Result()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(val, 10, 64)
+}
+
+// History 返回激活历史，第一个元素为当前代
+func (s *Store) History(ctx context.Context) ([]int64, error) {
+	vals, err := s.client.LRange(ctx, GenHistoryKey, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	gens := make([]int64, 0, len(vals))
+	for _, v := range vals {
+		gen, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid generation in history: %q", v)
+		}
+		gens = append(gens, gen)
+	}
+	return gens, nil
+}
+
+// LoadGeneration 读取某一代的全部链路，该代不存在时返回 ErrNoGeneration
+func (s *Store) LoadGeneration(ctx context.Context, gen int64) (map[string]model.NetworkLink, error) {
+	var metaCmd *redis.IntCmd
+	var rawCmd *redis.StringStringMapCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		metaCmd = pipe.Exists(ctx, GenerationMetaKey(gen))
+		rawCmd = pipe.HGetAll(ctx, GenerationKey(gen))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if metaCmd.Val() == 0 {
+		return nil, fmt.Errorf("%w: %d", ErrNoGeneration, gen)
+	}
+	raw := rawCmd.Val()
+	links := make(map[string]model.NetworkLink, len(raw))
+	for id, val := range raw {
+		var link model.NetworkLink
+		if err := json.Unmarshal([]byte(val), &link); err != nil {
+			return nil, fmt.Errorf("decode link %s of generation %d: %w", id, gen, err)
+		}
+		links[id] = link
+	}
+	return links, nil
+}
@@ -0,0 +1,162 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"netsimlation/distribute/master_server/internal/model"
+)
+
+// ErrLinkNotFound 链路键不存在
+var ErrLinkNotFound = errors.New("link not found")
+
+// GetLink 读取单条链路
+func (s *Store) GetLink(ctx context.Context, id string) (*model.NetworkLink, error) {
+	val, err := s.client.Get(ctx, model.LinkKey(id)).Result()
+	if err == redis.Nil {
+		return nil, fmt.Errorf("%w: %s", ErrLinkNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var link model.NetworkLink
+	if err := json.Unmarshal([]byte(val), &link); err != nil {
+		return nil, fmt.Errorf("decode link %s: %w", id, err)
+	}
+	return &link, nil
+}
+
+// SetLink 写入单条链路，ttl 为0表示不过期
+func (s *Store) SetLink(ctx context.Context, id string, link *model.NetworkLink, ttl time.Duration) error {
+	data, err := json.Marshal(link)
+	if err != nil {
+		return fmt.Errorf("marshal link %s: %w", id, err)
+	}
+	return s.client.Set(ctx, model.LinkKey(id), data, ttl).Err()
+}
+
+// PatchLink 在事务中读取链路、合并修改并写回，保留键原有的过期时间
+func (s *Store) PatchLink(ctx context.Context, id string, patch *model.LinkPatch) (*model.NetworkLink, error) {
+	return s.patchLink(ctx, id, patch, false)
+}
+
+// UpsertLink 与 PatchLink 相同，但链路不存在时以空链路为基础创建
+func (s *Store) UpsertLink(ctx context.Context, id string, patch *model.LinkPatch) (*model.NetworkLink, error) {
+	return s.patchLink(ctx, id, patch, true)
+}
+
+func (s *Store) patchLink(ctx context.Context, id string, patch *model.LinkPatch, create bool) (*model.NetworkLink, error) {
+	key := model.LinkKey(id)
+	var result model.NetworkLink
+
+	txf := func(tx *redis.Tx) error {
+		var link model.NetworkLink
+		val, err := tx.Get(ctx, key).Result()
+		switch {
+		case err == redis.Nil && create:
+			link.CreatedAt = time.Now().Format(time.RFC3339)
+		case err == redis.Nil:
+			return fmt.Errorf("%w: %s", ErrLinkNotFound, id)
+		case err != nil:
+			return err
+		default:
+			if err := json.Unmarshal([]byte(val), &link); err != nil {
+				return fmt.Errorf("decode link %s: %w", id, err)
+			}
+		}
+		ttl, err := tx.PTTL(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if ttl < 0 {
+			ttl = 0
+		}
+
+		patch.Apply(&link)
+		data, err := json.Marshal(link)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, data, ttl)
+			return nil
+		})
+		result = link
+		return err
+	}
+
+	// 并发修改导致事务失败时重试
+	for i := 0; i < 3; i++ {
+		err := s.client.Watch(ctx, txf, key)
+		if err == redis.TxFailedErr {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &result, nil
+	}
+	return nil, fmt.Errorf("patch link %s: too many concurrent modifications", id)
+}
+
+// DeleteLink 删除单条链路
+func (s *Store) DeleteLink(ctx context.Context, id string) error {
+	return s.client.Del(ctx, model.LinkKey(id)).Err()
+}
+
+// ListLinks 按ID顺序返回全部链路
+func (s *Store) ListLinks(ctx context.Context) ([]model.LinkRecord, error) {
+	ids, err := s.ListLinkIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(ids, func(i, j int) bool { return lessID(ids[i], ids[j]) })
+
+	records := make([]model.LinkRecord, 0, len(ids))
+	const chunk = 500
+	for start := 0; start < len(ids); start += chunk {
+		end := start + chunk
+		if end > len(ids) {
+			end = len(ids)
+		}
+		keys := make([]string, 0, end-start)
+		for _, id := range ids[start:end] {
+			keys = append(keys, model.LinkKey(id))
+		}
+		vals, err := s.client.MGet(ctx, keys...).Result()
+		if err != nil {
+			return nil, err
+		}
+		for i, v := range vals {
+			str, ok := v.(string)
+			if !ok {
+				// 扫描与读取之间被删除
+				continue
+			}
+			rec := model.LinkRecord{ID: ids[start+i]}
+			if err := json.Unmarshal([]byte(str), &rec.NetworkLink); err != nil {
+				return nil, fmt.Errorf("decode link %s: %w", rec.ID, err)
+			}
+			records = append(records, rec)
+		}
+	}
+	return records, nil
+}
+
+// lessID 数字ID按数值排序，其余按字符串排序
+func lessID(a, b string) bool {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	if errA == nil && errB == nil {
+		return na < nb
+	}
+	return a < b
+}
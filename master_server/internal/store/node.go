@@ -0,0 +1,75 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/go-redis/redis/v8"
+
+	"netsimlation/distribute/master_server/internal/model"
+)
+
+// NodesKey 哈希：节点ID -> Node JSON
+const NodesKey = "netsim:nodes"
+
+// ErrNodeNotFound 节点不存在
+var ErrNodeNotFound = errors.New("node not found")
+
+// ListNodes 按ID顺序返回全部节点
+func (s *Store) ListNodes(ctx context.Context) ([]model.Node, error) {
+	raw, err := s.client.HGetAll(ctx, NodesKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]model.Node, 0, len(raw))
+	for id, val := range raw {
+		var n model.Node
+		if err := json.Unmarshal([]byte(val), &n); err != nil {
+			return nil, fmt.Errorf("decode node %s: %w", id, err)
+		}
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+	return nodes, nil
+}
+
+// GetNode 读取单个节点
+func (s *Store) GetNode(ctx context.Context, id int) (*model.Node, error) {
+	val, err := s.client.HGet(ctx, NodesKey, strconv.Itoa(id)).Result()
+	if err == redis.Nil {
+		return nil, fmt.Errorf("%w: %d", ErrNodeNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var n model.Node
+	if err := json.Unmarshal([]byte(val), &n); err != nil {
+		return nil, fmt.Errorf("decode node %d: %w", id, err)
+	}
+	return &n, nil
+}
+
+// SetNode 创建或覆盖节点
+func (s *Store) SetNode(ctx context.Context, n *model.Node) error {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	return s.client.HSet(ctx, NodesKey, strconv.Itoa(n.ID), data).Err()
+}
+
+// DeleteNode 删除节点，不存在时返回 ErrNodeNotFound
+func (s *Store) DeleteNode(ctx context.Context, id int) error {
+	n, err := s.client.HDel(ctx, NodesKey, strconv.Itoa(id)).Result()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %d", ErrNodeNotFound, id)
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+
+	"netsimlation/distribute/master_server/internal/model"
+)
+
+// 网络分区相关的键名
+const (
+	PartitionKey    = "netsim:partition"     // 哈希：分组名 -> 节点ID列表JSON
+	PartitionCutKey = "netsim:partition:cut" // 集合：因分区而关闭的链路ID，恢复时只重新开启这些链路
+)
+
+// ErrPartitionActive 已存在未恢复的分区
+var ErrPartitionActive = errors.New("a partition is already active, heal it first")
+
+// Group 分区中的一个命名节点组
+type Group struct {
+	Name  string `json:"name"`
+	Nodes []int  `json:"nodes"`
+}
+
+// ParseGroups 解析 "a=1,2,3;b=4,5" 形式的分组描述
+func ParseGroups(spec string) ([]Group, error) {
+	var groups []Group
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, members, ok := strings.Cut(part, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid group %q, expected name=id,id,...", part)
+		}
+		g := Group{Name: strings.TrimSpace(name)}
+		for _, m := range strings.Split(members, ",") {
+			var id int
+			if _, err := fmt.Sscanf(strings.TrimSpace(m), "%d", &id); err != nil {
+				return nil, fmt.Errorf("group %s: invalid node id %q", g.Name, m)
+			}
+			g.Nodes = append(g.Nodes, id)
+		}
+		groups = append(groups, g)
+	}
+	if len(groups) < 2 {
+		return nil, fmt.Errorf("a partition needs at least two groups")
+	}
+	return groups, nil
+}
+
+// ListLinkIDs 扫描全部 network_link:<id> 键并返回链路ID
+func (s *Store) ListLinkIDs(ctx context.Context) ([]string, error) {
+	var ids []string
+	iter := s.client.Scan(ctx, 0, model.LinkKeyPrefix+"*", 1000).Iterator()
+	for iter.Next(ctx) {
+		ids = append(ids, strings.TrimPrefix(iter.Val(), model.LinkKeyPrefix))
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Partition 将节点划分为互不连通的分组：源节点与目的节点分属不同组的链路被关闭。
+// 未出现在任何组中的节点不受影响，已处于关闭状态的链路保持原样且恢复时不会被开启。
+// applyAt 非0时作为各链路修改的计划生效时刻，使所有从节点同时进入分区。
+// 分区只修改 network_link:* 键中的链路，仅存在于配置代（netsim:gen:<n>）中的链路不会被关闭，恢复时也不会被开启。
+func (s *Store) Partition(ctx context.Context, groups []Group, applyAt int64) ([]string, error) {
+	groupOf := make(map[int]string)
+	fields := make(map[string]interface{}, len(groups))
+	for _, g := range groups {
+		for _, node := range g.Nodes {
+			if other, ok := groupOf[node]; ok {
+				return nil, fmt.Errorf("node %d is in both group %s and %s", node, other, g.Name)
+			}
+			groupOf[node] = g.Name
+		}
+		data, err := json.Marshal(g)
+		if err != nil {
+			return nil, err
+		}
+		fields[g.Name] = data
+	}
+	if err := s.createPartition(ctx, fields); err != nil {
+		return nil, err
+	}
+
+	ids, err := s.ListLinkIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var cut []string
+	for _, id := range ids {
+		link, err := s.GetLink(ctx, id)
+		if errors.Is(err, ErrLinkNotFound) {
+			continue
+		}
+		if err != nil {
+			return cut, err
+		}
+		src, okSrc := groupOf[link.SourceNodeID]
+		dst, okDst := groupOf[link.DestNodeID]
+		if !okSrc || !okDst || src == dst || link.IsDown() {
+			continue
+		}
+
+		if _, err := s.PatchLink(ctx, id, adminPatch(model.AdminDown, applyAt)); err != nil {
+			return cut, fmt.Errorf("cut link %s: %w", id, err)
+		}
+		if err := s.client.SAdd(ctx, PartitionCutKey, id).Err(); err != nil {
+			return cut, err
+		}
+		cut = append(cut, id)
+	}
+	return cut, nil
+}
+
+// createPartition 在没有未恢复的分区时记录分组。
+// 检查与写入在同一个 WATCH 事务中，并发的两次分区只有一次成功
+func (s *Store) createPartition(ctx context.Context, fields map[string]interface{}) error {
+	txf := func(tx *redis.Tx) error {
+		n, err := tx.Exists(ctx, PartitionKey).Result()
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			return ErrPartitionActive
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HSet(ctx, PartitionKey, fields)
+			return nil
+		})
+		return err
+	}
+
+	// 并发修改导致事务失败时重试，重试时会看到对方写入的分区
+	for i := 0; i < 3; i++ {
+		err := s.client.Watch(ctx, txf, PartitionKey)
+		if err == redis.TxFailedErr {
+			continue
+		}
+		return err
+	}
+	return errors.New("partition: too many concurrent modifications")
+}
+
+// Heal 重新开启因分区关闭的链路并清除分区记录
+func (s *Store) Heal(ctx context.Context, applyAt int64) ([]string, error) {
+	ids, err := s.client.SMembers(ctx, PartitionCutKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	var healed []string
+	for _, id := range ids {
+		_, err := s.PatchLink(ctx, id, adminPatch(model.AdminUp, applyAt))
+		if err != nil && !errors.Is(err, ErrLinkNotFound) {
+			return healed, fmt.Errorf("heal link %s: %w", id, err)
+		}
+		if err := s.client.SRem(ctx, PartitionCutKey, id).Err(); err != nil {
+			return healed, err
+		}
+		if err == nil {
+			healed = append(healed, id)
+		}
+	}
+	return healed, s.client.Del(ctx, PartitionKey, PartitionCutKey).Err()
+}
+
+// ActivePartition 返回当前分区的分组，没有分区时返回空
+func (s *Store) ActivePartition(ctx context.Context) ([]Group, error) {
+	raw, err := s.client.HGetAll(ctx, PartitionKey).Result()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+	groups := make([]Group, 0, len(raw))
+	for name, val := range raw {
+		var g Group
+		if err := json.Unmarshal([]byte(val), &g); err != nil {
+			return nil, fmt.Errorf("decode partition group %s: %w", name, err)
+		}
+		groups = append(groups, g)
+	}
+	return groups, nil
+}
+
+// SetAdminState 修改单条链路的管理状态
+func (s *Store) SetAdminState(ctx context.Context, id, state string, applyAt int64) (*model.NetworkLink, error) {
+	return s.PatchLink(ctx, id, adminPatch(state, applyAt))
+}
+
+func adminPatch(state string, applyAt int64) *model.LinkPatch {
+	patch := model.SetAdminState(state)
+	if applyAt > 0 {
+		patch.ApplyAt = &applyAt
+	}
+	return patch
+}
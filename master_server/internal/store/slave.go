@@ -0,0 +1,100 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 从节点定期写入的状态键，键带过期时间，从节点停止后自动消失
+const (
+	SlaveKeyPrefix = "netsim:slave:"
+	skewKeyPrefix  = "netsim:apply:skew:"
+)
+
+// SlaveStatus 从节点上报的运行状态
+type SlaveStatus struct {
+	Node       string    `json:"node"`
+	Version    string    `json:"version,omitempty"`
+	Iface      string    `json:"iface,omitempty"`
+	AppliedGen int64     `json:"applied_gen"`
+	Links      int       `json:"links"`
+	OffsetMs   float64   `json:"offset_ms"`
+	ClockSync  bool      `json:"clock_synced"`
+	LastSeen   time.Time `json:"last_seen"`
+	LastSkewMs *float64  `json:"last_skew_ms,omitempty"`
+}
+
+// ListSlaves 返回当前在线的从节点状态
+func (s *Store) ListSlaves(ctx context.Context) ([]SlaveStatus, error) {
+	var slaves []SlaveStatus
+	iter := s.client.Scan(ctx, 0, SlaveKeyPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		node := strings.TrimPrefix(iter.Val(), SlaveKeyPrefix)
+		st, err := s.GetSlave(ctx, node)
+		if err != nil {
+			return nil, err
+		}
+		if st != nil {
+			slaves = append(slaves, *st)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(slaves, func(i, j int) bool { return slaves[i].Node < slaves[j].Node })
+	return slaves, nil
+}
+
+// GetSlave 读取单个从节点状态，从节点不在线时返回 nil
+func (s *Store) GetSlave(ctx context.Context, node string) (*SlaveStatus, error) {
+	raw, err := s.client.HGetAll(ctx, SlaveKeyPrefix+node).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	st := &SlaveStatus{
+		Node:      node,
+		Version:   raw["version"],
+		Iface:     raw["iface"],
+		ClockSync: raw["clock_synced"] == "1",
+	}
+	st.AppliedGen, _ = strconv.ParseInt(raw["applied_gen"], 10, 64)
+	st.Links, _ = strconv.Atoi(raw["links"])
+	st.OffsetMs, _ = strconv.ParseFloat(raw["offset_ms"], 64)
+	if ms, err := strconv.ParseInt(raw["last_seen"], 10, 64); err == nil {
+		st.LastSeen = time.UnixMilli(ms)
+	}
+
+	if skew, err := s.client.HGet(ctx, skewKeyPrefix+node, "skew_ms").Float64(); err == nil {
+		st.LastSkewMs = &skew
+	}
+	return st, nil
+}
+
+// SetSlave 代替通过gRPC接入的从节点写入状态，字段与从节点直接写Redis时一致
+func (s *Store) SetSlave(ctx context.Context, st *SlaveStatus, ttl time.Duration) error {
+	clockSynced := 0
+	if st.ClockSync {
+		clockSynced = 1
+	}
+	key := SlaveKeyPrefix + st.Node
+	pipe := s.client.Pipeline()
+	pipe.HSet(ctx, key, map[string]interface{}{
+		"version":      st.Version,
+		"iface":        st.Iface,
+		"applied_gen":  st.AppliedGen,
+		"links":        st.Links,
+		"offset_ms":    st.OffsetMs,
+		"clock_synced": clockSynced,
+		"last_seen":    st.LastSeen.UnixMilli(),
+	})
+	pipe.Expire(ctx, key, ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
@@ -0,0 +1,53 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Options Redis连接参数
+type Options struct {
+	Addr     string
+	Password string
+	DB       int
+}
+
+// Store 封装主控端对Redis的读写操作
+type Store struct {
+	client *redis.Client
+}
+
+// New 创建Redis客户端，连接池与超时配置沿用原 db-operation 的默认值
+func New(opts Options) *Store {
+	client := redis.NewClient(&redis.Options{
+		Addr:     opts.Addr,
+		Password: opts.Password,
+		DB:       opts.DB,
+		// 添加连接池配置
+		PoolSize:     10,
+		MinIdleConns: 5,
+		// 添加超时设置
+		DialTimeout:  5 * time.Second,
+		ReadTimeout:  3 * time.Second,
+		WriteTimeout: 3 * time.Second,
+		PoolTimeout:  4 * time.Second,
+	})
+	return &Store{client: client}
+}
+
+// Client 返回底层Redis客户端
+func (s *Store) Client() *redis.Client {
+	return s.client
+}
+
+// Ping 验证Redis连接
+func (s *Store) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx).Err()
+}
+
+// Close 关闭Redis连接
+func (s *Store) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,110 @@
+// Package units 解析与格式化链路参数的常用写法。带宽后缀沿用 tc 的写法：
+// bit/kbit/mbit/gbit/tbit 为比特每秒，bps/kbps/mbps/gbps/tbps 为字节每秒；
+// 解析结果统一换算为字节每秒，即 NetworkLink.BandwidthBps 与数据面 throttle_rate_bps 的单位。
+package units
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rateUnits 各后缀对应的字节每秒倍数
+var rateUnits = []struct {
+	suffix string
+	factor float64
+}{
+	// 较长的后缀在前，避免 "kbit" 被 "bit" 提前匹配
+	{"tbit", 1e12 / 8}, {"gbit", 1e9 / 8}, {"mbit", 1e6 / 8}, {"kbit", 1e3 / 8},
+	{"tbps", 1e12}, {"gbps", 1e9}, {"mbps", 1e6}, {"kbps", 1e3},
+	{"bit", 1.0 / 8}, {"bps", 1},
+}
+
+// ParseRate 解析带宽，返回字节每秒，如 "10mbit" -> 1250000。不带单位的数字按字节每秒处理。
+func ParseRate(s string) (uint64, error) {
+	v := strings.ToLower(strings.TrimSpace(s))
+	factor := 1.0
+	for _, u := range rateUnits {
+		if strings.HasSuffix(v, u.suffix) {
+			v = strings.TrimSuffix(v, u.suffix)
+			factor = u.factor
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil || math.IsNaN(n) || n < 0 || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("invalid rate %q, expected e.g. 10mbit, 1gbit, 500kbps", s)
+	}
+	bytes := math.Round(n * factor)
+	if bytes > math.MaxUint32 {
+		return 0, fmt.Errorf("rate %q out of range, the datapath supports up to 34gbit", s)
+	}
+	return uint64(bytes), nil
+}
+
+// FormatRate 将字节每秒的带宽以最大的整倍比特单位格式化，如 1250000 -> "10mbit"
+func FormatRate(bytesPerSec uint64) string {
+	bps := bytesPerSec * 8
+	for _, u := range []struct {
+		suffix string
+		factor uint64
+	}{{"tbit", 1e12}, {"gbit", 1e9}, {"mbit", 1e6}, {"kbit", 1e3}} {
+		if bps >= u.factor && bps%u.factor == 0 {
+			return fmt.Sprintf("%d%s", bps/u.factor, u.suffix)
+		}
+	}
+	if bps >= 1e6 {
+		return strconv.FormatFloat(float64(bps)/1e6, 'f', 2, 64) + "mbit"
+	}
+	return fmt.Sprintf("%dbit", bps)
+}
+
+// ParseDelay 解析时延，返回毫秒。支持 Go 时长写法（50ms、1.5s、200us），不带单位的数字按毫秒处理。
+// 不足1毫秒的部分四舍五入。
+func ParseDelay(s string) (uint32, error) {
+	v := strings.TrimSpace(s)
+	if n, err := strconv.ParseFloat(v, 64); err == nil {
+		if math.IsNaN(n) || n < 0 || n > math.MaxUint32 {
+			return 0, fmt.Errorf("delay %q out of range", s)
+		}
+		return uint32(math.Round(n)), nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid delay %q, expected e.g. 50ms, 1.5s", s)
+	}
+	ms := math.Round(float64(d) / float64(time.Millisecond))
+	if ms > math.MaxUint32 {
+		return 0, fmt.Errorf("delay %q out of range", s)
+	}
+	return uint32(ms), nil
+}
+
+// FormatDelay 格式化毫秒时延
+func FormatDelay(ms uint32) string {
+	return (time.Duration(ms) * time.Millisecond).String()
+}
+
+// ParseLoss 解析丢包率，返回 0.0-1.0 的比例。"1%" 表示 0.01，不带百分号的数字按比例处理。
+func ParseLoss(s string) (float64, error) {
+	v := strings.TrimSpace(s)
+	percent := strings.HasSuffix(v, "%")
+	n, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(v, "%")), 64)
+	if err != nil || math.IsNaN(n) {
+		return 0, fmt.Errorf("invalid loss %q, expected e.g. 1%% or 0.01", s)
+	}
+	if percent {
+		n /= 100
+	}
+	if n < 0 || n > 1 {
+		return 0, fmt.Errorf("loss %q out of range 0-100%%", s)
+	}
+	return n, nil
+}
+
+// FormatLoss 以百分比格式化丢包率
+func FormatLoss(rate float64) string {
+	return strconv.FormatFloat(rate*100, 'g', 6, 64) + "%"
+}
@@ -0,0 +1,161 @@
+package units
+
+import "testing"
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    uint64
+		wantErr bool
+	}{
+		{in: "10mbit", want: 1250000},
+		{in: "10MBit", want: 1250000},
+		{in: "1gbit", want: 125000000},
+		{in: "1.5mbit", want: 187500},
+		{in: "64kbit", want: 8000},
+		{in: "800bit", want: 100},
+		{in: "10mbps", want: 10000000},
+		{in: "500kbps", want: 500000},
+		{in: "100bps", want: 100},
+		{in: " 2 mbit ", want: 250000},
+		{in: "1250000", want: 1250000},
+		{in: "0", want: 0},
+		{in: "34gbit", want: 4250000000},
+		{in: "35gbit", wantErr: true},
+		{in: "5gbps", wantErr: true},
+		{in: "", wantErr: true},
+		{in: "mbit", wantErr: true},
+		{in: "-1mbit", wantErr: true},
+		{in: "fast", wantErr: true},
+		{in: "nan", wantErr: true},
+		{in: "NaNmbit", wantErr: true},
+		{in: "inf", wantErr: true},
+		{in: "-Infkbit", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRate(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseRate(%q) = %d, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRate(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRate(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatRate(t *testing.T) {
+	tests := []struct {
+		in   uint64
+		want string
+	}{
+		{0, "0bit"},
+		{100, "800bit"},
+		{8000, "64kbit"},
+		{1250000, "10mbit"},
+		{125000000, "1gbit"},
+		{187500, "1500kbit"},
+		{1000001, "8.00mbit"},
+	}
+	for _, tt := range tests {
+		if got := FormatRate(tt.in); got != tt.want {
+			t.Errorf("FormatRate(%d) = %q, want %q", tt.in, got, tt.want)
+		}
+		// 格式化结果可以原样解析回来（非整倍单位的两位小数除外）
+		if tt.in != 1000001 {
+			if back, err := ParseRate(FormatRate(tt.in)); err != nil || back != tt.in {
+				t.Errorf("ParseRate(FormatRate(%d)) = %d, %v", tt.in, back, err)
+			}
+		}
+	}
+}
+
+func TestParseDelay(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    uint32
+		wantErr bool
+	}{
+		{in: "50ms", want: 50},
+		{in: "1.5s", want: 1500},
+		{in: "200us", want: 0},
+		{in: "600us", want: 1},
+		{in: "1m", want: 60000},
+		{in: "50", want: 50},
+		{in: "2.6", want: 3},
+		{in: "0", want: 0},
+		{in: " 10ms ", want: 10},
+		{in: "4294967295", want: 4294967295},
+		{in: "4294967296", wantErr: true},
+		{in: "-5ms", wantErr: true},
+		{in: "-1", wantErr: true},
+		{in: "", wantErr: true},
+		{in: "soon", wantErr: true},
+		{in: "nan", wantErr: true},
+		{in: "NaN", wantErr: true},
+		{in: "inf", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDelay(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseDelay(%q) = %d, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseDelay(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDelay(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseLoss(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{in: "1%", want: 0.01},
+		{in: "0.5%", want: 0.005},
+		{in: "100%", want: 1},
+		{in: "0.01", want: 0.01},
+		{in: "0", want: 0},
+		{in: "1", want: 1},
+		{in: " 25 % ", want: 0.25},
+		{in: "101%", wantErr: true},
+		{in: "1.5", wantErr: true},
+		{in: "-1%", wantErr: true},
+		{in: "", wantErr: true},
+		{in: "%", wantErr: true},
+		{in: "lots", wantErr: true},
+		{in: "nan", wantErr: true},
+		{in: "NaN%", wantErr: true},
+		{in: "inf", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLoss(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseLoss(%q) = %v, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseLoss(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLoss(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
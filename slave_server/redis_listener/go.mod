@@ -3,11 +3,25 @@ module netsimlation/distribute/slave_server/redis_listener
 go 1.18
 
 require (
+	github.com/cilium/ebpf v0.10.0
 	github.com/go-redis/redis/v8 v8.11.5
+	github.com/vishvananda/netlink v1.1.0
+	golang.org/x/sys v0.8.0
+	google.golang.org/grpc v1.56.3
+	google.golang.org/protobuf v1.30.0
 	gopkg.in/yaml.v2 v2.4.0
+	netsimlation/distribute/ebpf v0.0.0
 )
 
 require (
-	github.com/cespare/xxhash/v2 v2.1.2 // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
 	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	github.com/vishvananda/netns v0.0.5 // indirect
+	golang.org/x/net v0.9.0 // indirect
+	golang.org/x/text v0.9.0 // indirect
+	google.golang.org/genproto v0.0.0-20230410155749-daa745c078e1 // indirect
 )
+
+// 与 ebpf_emu 在同一仓库中，直接使用其 pkg/tc 挂载程序
+replace netsimlation/distribute/ebpf => ../../ebpf_emu
@@ -0,0 +1,152 @@
+package bpfmap
+
+import (
+    "errors"
+    "fmt"
+    "path/filepath"
+
+    "github.com/cilium/ebpf"
+)
+
+// MapName ebpf-network-emulation 按名称固定的链路映射
+const MapName = "MAC_HANDLE_BPS_DELAY"
+
+// 链路管理状态，对应C代码中的 LINK_ADMIN_UP/LINK_ADMIN_DOWN
+const (
+    AdminUp   uint32 = 0
+    AdminDown uint32 = 1
+)
+
+// FlowKey 对应C代码中的 struct flow_key（网卡index + 源MAC地址）
+type FlowKey struct {
+    Ifindex uint32
+    SrcMac  [6]byte
+}
+
+// HandleBpsDelay 对应C代码中的 struct handle_bps_delay
+type HandleBpsDelay struct {
+    TcHandle        uint32
+    ThrottleRateBps uint32
+    DelayMs         uint32
+    AdminState      uint32
+}
+
+// Map 封装固定在bpffs上的 MAC_HANDLE_BPS_DELAY 映射
+type Map struct {
+    m *ebpf.Map
+}
+
+// Open 加载 pinPath 目录下固定的链路映射
+func Open(pinPath string) (*Map, error) {
+    m, err := ebpf.LoadPinnedMap(filepath.Join(pinPath, MapName), &ebpf.LoadPinOptions{})
+    if err != nil {
+        return nil, fmt.Errorf("load pinned map %s: %w", MapName, err)
+    }
+    return &Map{m: m}, nil
+}
+
+// Put 写入单个条目
+func (m *Map) Put(key FlowKey, value HandleBpsDelay) error {
+    return m.m.Put(key, value)
+}
+
+// Delete 删除单个条目，条目不存在时不视为错误
+func (m *Map) Delete(key FlowKey) error {
+    if err := m.m.Delete(key); err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+        return err
+    }
+    return nil
+}
+
+// Snapshot 读取映射中的全部条目
+func (m *Map) Snapshot() (map[FlowKey]HandleBpsDelay, error) {
+    entries := make(map[FlowKey]HandleBpsDelay)
+    var key FlowKey
+    var value HandleBpsDelay
+    iter := m.m.Iterate()
+    for iter.Next(&key, &value) {
+        entries[key] = value
+    }
+    if err := iter.Err(); err != nil {
+        return nil, fmt.Errorf("iterate map: %w", err)
+    }
+    return entries, nil
+}
+
+// Replace 用 desired 整体替换映射内容：先批量写入全部条目，再批量删除多余条目。
+// 任何一步失败都会把映射恢复为调用前的快照，因此数据面不会停留在半应用状态。
+func (m *Map) Replace(desired map[FlowKey]HandleBpsDelay) error {
+    previous, err := m.Snapshot()
+    if err != nil {
+        return err
+    }
+
+    if err := m.sync(previous, desired); err != nil {
+        if rerr := m.sync(desired, previous); rerr != nil {
+            return fmt.Errorf("%v; restore previous entries: %v", err, rerr)
+        }
+        return err
+    }
+    return nil
+}
+
+// sync 将当前内容为 current 的映射修改为 desired
+func (m *Map) sync(current, desired map[FlowKey]HandleBpsDelay) error {
+    keys := make([]FlowKey, 0, len(desired))
+    values := make([]HandleBpsDelay, 0, len(desired))
+    for k, v := range desired {
+        keys = append(keys, k)
+        values = append(values, v)
+    }
+
+    var stale []FlowKey
+    for k := range current {
+        if _, ok := desired[k]; !ok {
+            stale = append(stale, k)
+        }
+    }
+
+    if err := m.batchUpdate(keys, values); err != nil {
+        return err
+    }
+    return m.batchDelete(stale)
+}
+
+// batchUpdate 一次系统调用写入所有条目，内核不支持批量操作时逐条写入
+func (m *Map) batchUpdate(keys []FlowKey, values []HandleBpsDelay) error {
+    if len(keys) == 0 {
+        return nil
+    }
+    _, err := m.m.BatchUpdate(keys, values, &ebpf.BatchOptions{})
+    if errors.Is(err, ebpf.ErrNotSupported) {
+        for i := range keys {
+            if err := m.m.Put(keys[i], values[i]); err != nil {
+                return fmt.Errorf("update entry %d: %w", i, err)
+            }
+        }
+        return nil
+    }
+    return err
+}
+
+// batchDelete 批量删除条目，内核不支持批量操作时逐条删除
+func (m *Map) batchDelete(keys []FlowKey) error {
+    if len(keys) == 0 {
+        return nil
+    }
+    _, err := m.m.BatchDelete(keys, &ebpf.BatchOptions{})
+    if errors.Is(err, ebpf.ErrNotSupported) || errors.Is(err, ebpf.ErrKeyNotExist) {
+        for _, k := range keys {
+            if err := m.Delete(k); err != nil {
+                return err
+            }
+        }
+        return nil
+    }
+    return err
+}
+
+// Close 关闭映射文件描述符
+func (m *Map) Close() error {
+    return m.m.Close()
+}
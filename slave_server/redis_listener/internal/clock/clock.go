@@ -0,0 +1,78 @@
+package clock
+
+import (
+    "sync"
+    "time"
+)
+
+// Sample 一次NTP式的时间戳交换，均为Unix纳秒。
+// T0/T3 为从节点发送请求与收到回复的本地时间，T1/T2 为主控端收到请求与发出回复的时间。
+type Sample struct {
+    T0 int64
+    T1 int64
+    T2 int64
+    T3 int64
+}
+
+// Offset 主控时钟减本地时钟
+func (s Sample) Offset() time.Duration {
+    return time.Duration(((s.T1 - s.T0) + (s.T2 - s.T3)) / 2)
+}
+
+// Delay 往返时延（扣除主控端处理时间）
+func (s Sample) Delay() time.Duration {
+    return time.Duration((s.T3 - s.T0) - (s.T2 - s.T1))
+}
+
+// Clock 维护相对主控端的时钟偏移估计
+type Clock struct {
+    mu       sync.RWMutex
+    offset   time.Duration
+    delay    time.Duration
+    synced   bool
+    lastSync time.Time
+}
+
+func New() *Clock {
+    return &Clock{}
+}
+
+// Update 用一组样本更新偏移估计，取往返时延最小的样本，它受排队抖动影响最小
+func (c *Clock) Update(samples []Sample) bool {
+    if len(samples) == 0 {
+        return false
+    }
+    best := samples[0]
+    for _, s := range samples[1:] {
+        if s.Delay() < best.Delay() {
+            best = s
+        }
+    }
+
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.offset = best.Offset()
+    c.delay = best.Delay()
+    c.synced = true
+    c.lastSync = time.Now()
+    return true
+}
+
+// Offset 返回当前估计的偏移与对应样本的往返时延，未同步时 synced 为 false
+func (c *Clock) Offset() (offset, delay time.Duration, synced bool) {
+    c.mu.RLock()
+    defer c.mu.RUnlock()
+    return c.offset, c.delay, c.synced
+}
+
+// MasterNow 返回换算到主控时钟的当前时间
+func (c *Clock) MasterNow() time.Time {
+    offset, _, _ := c.Offset()
+    return time.Now().Add(offset)
+}
+
+// LocalTime 将主控时钟下的Unix毫秒时刻换算为本地时间
+func (c *Clock) LocalTime(masterMs int64) time.Time {
+    offset, _, _ := c.Offset()
+    return time.Unix(0, masterMs*int64(time.Millisecond)).Add(-offset)
+}
@@ -1,16 +1,25 @@
 package config
 
 import (
+    "fmt"
     "os"
     "time"
 
     "gopkg.in/yaml.v2"
+    "netsimlation/distribute/ebpf/pkg/instance"
+    "netsimlation/distribute/slave_server/redis_listener/internal/transport"
 )
 
 type Config struct {
-    App    AppConfig    `yaml:"app"`
-    Redis  RedisConfig  `yaml:"redis"`
-    Server ServerConfig `yaml:"server"`
+    App       AppConfig    `yaml:"app"`
+    // 与主控端的通信方式：redis（默认）或 grpc
+    Transport string       `yaml:"transport"`
+    Redis     RedisConfig  `yaml:"redis"`
+    Grpc      GrpcConfig   `yaml:"grpc"`
+    Ebpf      EbpfConfig   `yaml:"ebpf"`
+    Containers ContainerConfig `yaml:"containers"`
+    Clock     ClockConfig  `yaml:"clock"`
+    Server    ServerConfig `yaml:"server"`
 }
 
 type AppConfig struct {
@@ -27,10 +36,56 @@ type RedisConfig struct {
     KeyPrefixes []string `yaml:"key_prefixes"`
 }
 
+// GrpcConfig transport 为 grpc 时连接主控端控制服务的参数
+type GrpcConfig struct {
+    // 主控端 master-server -grpc-listen 的地址
+    Addr             string `yaml:"addr"`
+    // 控制流断开后的重连间隔
+    ReconnectSeconds int    `yaml:"reconnect_seconds"`
+}
+
+// EbpfConfig 链路配置落地到本机eBPF映射的参数
+type EbpfConfig struct {
+    // 链路条目所作用的网卡，为空时只记录事件而不修改映射
+    Iface    string `yaml:"iface"`
+    // ebpf-network-emulation 的实例名称（-instance），为空时使用默认实例
+    Instance string `yaml:"instance"`
+    // MAC_HANDLE_BPS_DELAY 的固定路径所在目录，为空时使用实例挂载时记录的目录（instance.json）
+    PinPath  string `yaml:"pin_path"`
+    // iface 所在的网络命名空间：ip netns 名称、命名空间文件路径或容器进程PID，为空表示本机命名空间
+    Netns    string `yaml:"netns"`
+}
+
+// ContainerConfig 链路以容器描述端点（source_container/dest_container）时使用的本机容器运行时
+type ContainerConfig struct {
+    // docker 或 containerd，留空则不解析容器端点
+    Runtime            string `yaml:"runtime"`
+    DockerSocket       string `yaml:"docker_socket"`
+    // containerd shim v2 的任务状态目录
+    ContainerdStateDir string `yaml:"containerd_state_dir"`
+    // 容器内与宿主机veth相连的网卡
+    Iface              string `yaml:"iface"`
+    // 解析结果的缓存时间
+    CacheSeconds       int    `yaml:"cache_seconds"`
+    // 已由 ebpf-network-emulation 挂载了程序的本机网卡。设置后守护进程把同一程序挂载到
+    // dest_container 解析出的宿主机侧veth上，新建的容器无需再运行加载器
+    AttachFrom         string `yaml:"attach_from"`
+}
+
+// ClockConfig 与主控端时钟同步的参数，用于按 apply_at 定时应用链路变更
+type ClockConfig struct {
+    // 本节点标识，用于同步回复频道与偏差上报，默认使用主机名
+    NodeID              string `yaml:"node_id"`
+    SyncIntervalSeconds int    `yaml:"sync_interval_seconds"`
+    Samples             int    `yaml:"samples"`
+}
+
 type ServerConfig struct {
     MaxRetries          int           `yaml:"max_retries"`
     RetryInterval       time.Duration `yaml:"retry_interval_seconds"`
     ShutdownTimeout     time.Duration `yaml:"shutdown_timeout_seconds"`
+    // 向主控端上报运行状态的间隔
+    StatusIntervalSeconds int         `yaml:"status_interval_seconds"`
 }
 
 // Load 从YAML文件加载配置
@@ -49,6 +104,46 @@ func Load(configPath string) (*Config, error) {
     if cfg.Server.ShutdownTimeout == 0 {
         cfg.Server.ShutdownTimeout = 30 * time.Second
     }
+    if cfg.Transport == "" {
+        cfg.Transport = transport.TypeRedis
+    }
+    if cfg.Transport != transport.TypeRedis && cfg.Transport != transport.TypeGRPC {
+        return nil, fmt.Errorf("unknown transport %q, expected redis or grpc", cfg.Transport)
+    }
+    if cfg.Grpc.ReconnectSeconds == 0 {
+        cfg.Grpc.ReconnectSeconds = 5
+    }
+    // 与 map-populator 等工具一样按实例名称查找固定目录
+    inst, err := instance.Lookup(cfg.Ebpf.Instance, cfg.Ebpf.PinPath)
+    if err != nil {
+        return nil, fmt.Errorf("ebpf instance: %w", err)
+    }
+    cfg.Ebpf.PinPath = inst.PinPath
+    switch cfg.Containers.Runtime {
+    case "", "docker", "containerd":
+    default:
+        return nil, fmt.Errorf("unknown container runtime %q, expected docker or containerd", cfg.Containers.Runtime)
+    }
+    if cfg.Containers.Iface == "" {
+        cfg.Containers.Iface = "eth0"
+    }
+    if cfg.Containers.CacheSeconds == 0 {
+        cfg.Containers.CacheSeconds = 30
+    }
+    if cfg.Clock.NodeID == "" {
+        if host, err := os.Hostname(); err == nil {
+            cfg.Clock.NodeID = host
+        }
+    }
+    if cfg.Clock.SyncIntervalSeconds == 0 {
+        cfg.Clock.SyncIntervalSeconds = 60
+    }
+    if cfg.Clock.Samples == 0 {
+        cfg.Clock.Samples = 8
+    }
+    if cfg.Server.StatusIntervalSeconds == 0 {
+        cfg.Server.StatusIntervalSeconds = 5
+    }
     
     return &cfg, nil
 }
\ No newline at end of file
@@ -0,0 +1,136 @@
+package container
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net"
+    "sync"
+    "time"
+
+    "netsimlation/distribute/slave_server/redis_listener/internal/netns"
+)
+
+// 支持的容器运行时
+const (
+    RuntimeDocker     = "docker"
+    RuntimeContainerd = "containerd"
+)
+
+// ErrNotFound 本机运行时中没有该容器，链路的端点在其他主机上
+var ErrNotFound = errors.New("container not found")
+
+// Container 运行时返回的容器信息
+type Container struct {
+    ID   string
+    Name string
+    Pid  int // 容器主进程在宿主机上的PID，用于进入其网络命名空间
+}
+
+// Runtime 按容器ID或名称查询容器，测试中可替换为桩实现
+type Runtime interface {
+    Name() string
+    Inspect(ctx context.Context, ref string) (*Container, error)
+}
+
+// Endpoint 容器在数据面上的端点
+type Endpoint struct {
+    Container
+    MAC         net.HardwareAddr // 容器内网卡的MAC，即其发出流量的源MAC
+    HostIface   string           // 宿主机侧的veth
+    HostIfindex int
+}
+
+// Resolver 将容器解析为端点并缓存一段时间，避免每条链路事件都访问运行时
+type Resolver struct {
+    runtime Runtime
+    iface   string
+    ttl     time.Duration
+
+    mu    sync.Mutex
+    cache map[string]cached
+}
+
+type cached struct {
+    ep      *Endpoint
+    expires time.Time
+}
+
+// NewResolver 创建解析器，iface 为容器内的网卡名
+func NewResolver(runtime Runtime, iface string, ttl time.Duration) *Resolver {
+    return &Resolver{
+        runtime: runtime,
+        iface:   iface,
+        ttl:     ttl,
+        cache:   make(map[string]cached),
+    }
+}
+
+// Resolve 返回容器的端点；容器不在本机时返回 ErrNotFound
+func (r *Resolver) Resolve(ctx context.Context, ref string) (*Endpoint, error) {
+    r.mu.Lock()
+    c, ok := r.cache[ref]
+    r.mu.Unlock()
+    if ok && time.Now().Before(c.expires) {
+        return c.ep, nil
+    }
+
+    ctr, err := r.runtime.Inspect(ctx, ref)
+    if err != nil {
+        return nil, err
+    }
+    if ctr.Pid <= 0 {
+        return nil, fmt.Errorf("container %s is not running", ref)
+    }
+    ep, err := r.endpoint(ctr)
+    if err != nil {
+        return nil, fmt.Errorf("container %s: %w", ref, err)
+    }
+
+    r.mu.Lock()
+    r.cache[ref] = cached{ep: ep, expires: time.Now().Add(r.ttl)}
+    r.mu.Unlock()
+    return ep, nil
+}
+
+// Invalidate 清空缓存，容器重建后veth会变化
+func (r *Resolver) Invalidate() {
+    r.mu.Lock()
+    r.cache = make(map[string]cached)
+    r.mu.Unlock()
+}
+
+// endpoint 在容器的网络命名空间中读取网卡MAC与veth对端索引，再在本机命名空间中找到对端
+func (r *Resolver) endpoint(ctr *Container) (*Endpoint, error) {
+    var inner *linkInfo
+    err := netns.Do(fmt.Sprintf("/proc/%d/ns/net", ctr.Pid), func() error {
+        links, err := listLinks()
+        if err != nil {
+            return err
+        }
+        for i := range links {
+            if links[i].name == r.iface {
+                inner = &links[i]
+                return nil
+            }
+        }
+        return fmt.Errorf("no interface %s in network namespace of pid %d", r.iface, ctr.Pid)
+    })
+    if err != nil {
+        return nil, err
+    }
+    if inner.peer == 0 || inner.peer == inner.index {
+        return nil, fmt.Errorf("interface %s is not a veth (host network or macvlan?)", r.iface)
+    }
+
+    host, err := net.InterfaceByIndex(inner.peer)
+    if err != nil {
+        return nil, fmt.Errorf("host side of %s (ifindex %d): %w", r.iface, inner.peer, err)
+    }
+    return &Endpoint{
+        Container:   *ctr,
+        MAC:         inner.mac,
+        HostIface:   host.Name,
+        HostIfindex: host.Index,
+    }, nil
+}
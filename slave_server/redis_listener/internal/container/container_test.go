@@ -0,0 +1,108 @@
+package container
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "os"
+    "os/exec"
+    "strings"
+    "testing"
+    "time"
+)
+
+// stubRuntime 按 ref 返回预置的容器，并记录查询次数
+type stubRuntime struct {
+    containers map[string]*Container
+    err        error
+    calls      int
+}
+
+func (s *stubRuntime) Name() string { return "stub" }
+
+func (s *stubRuntime) Inspect(ctx context.Context, ref string) (*Container, error) {
+    s.calls++
+    if s.err != nil {
+        return nil, s.err
+    }
+    c, ok := s.containers[ref]
+    if !ok {
+        return nil, fmt.Errorf("%w: %s", ErrNotFound, ref)
+    }
+    return c, nil
+}
+
+func TestResolveErrors(t *testing.T) {
+    unavailable := errors.New("runtime unavailable")
+    tests := []struct {
+        name    string
+        rt      *stubRuntime
+        ref     string
+        wantErr error
+    }{
+        {name: "not found", rt: &stubRuntime{}, ref: "web", wantErr: ErrNotFound},
+        {name: "runtime error", rt: &stubRuntime{err: unavailable}, ref: "web", wantErr: unavailable},
+        {name: "not running", rt: &stubRuntime{containers: map[string]*Container{"web": {ID: "abc", Name: "web"}}}, ref: "web"},
+    }
+    for _, tt := range tests {
+        r := NewResolver(tt.rt, "eth0", time.Minute)
+        ep, err := r.Resolve(context.Background(), tt.ref)
+        if err == nil {
+            t.Errorf("%s: Resolve = %+v, want error", tt.name, ep)
+            continue
+        }
+        if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+            t.Errorf("%s: Resolve error %v, want %v", tt.name, err, tt.wantErr)
+        }
+    }
+}
+
+func TestResolveNotVeth(t *testing.T) {
+    if os.Geteuid() != 0 {
+        t.Skip("entering a network namespace requires root")
+    }
+    rt := &stubRuntime{containers: map[string]*Container{"self": {ID: "self", Pid: os.Getpid()}}}
+    r := NewResolver(rt, "lo", time.Minute)
+    _, err := r.Resolve(context.Background(), "self")
+    if err == nil || !strings.Contains(err.Error(), "not a veth") {
+        t.Fatalf("Resolve(lo) error %v, want not a veth", err)
+    }
+}
+
+// TestResolveVeth 以本进程代替容器，在当前命名空间中创建一对 veth：
+// ctr 端视为容器内网卡，host 端为其宿主机侧对端
+func TestResolveVeth(t *testing.T) {
+    if os.Geteuid() != 0 {
+        t.Skip("creating a veth pair requires root")
+    }
+    const ctrIface, hostIface = "nsctest0", "nsctest1"
+    if out, err := exec.Command("ip", "link", "add", ctrIface, "type", "veth", "peer", "name", hostIface).CombinedOutput(); err != nil {
+        t.Skipf("ip link add: %v: %s", err, out)
+    }
+    defer exec.Command("ip", "link", "del", ctrIface).Run()
+
+    rt := &stubRuntime{containers: map[string]*Container{"web": {ID: "abc", Name: "web", Pid: os.Getpid()}}}
+    r := NewResolver(rt, ctrIface, time.Minute)
+    ep, err := r.Resolve(context.Background(), "web")
+    if err != nil {
+        t.Fatalf("Resolve: %v", err)
+    }
+    if ep.HostIface != hostIface || ep.HostIfindex == 0 || len(ep.MAC) != 6 || ep.ID != "abc" {
+        t.Fatalf("Resolve = %+v", ep)
+    }
+
+    // 缓存期内不再访问运行时，Invalidate 之后重新查询
+    if _, err := r.Resolve(context.Background(), "web"); err != nil {
+        t.Fatalf("cached Resolve: %v", err)
+    }
+    if rt.calls != 1 {
+        t.Fatalf("runtime queried %d times, want 1", rt.calls)
+    }
+    r.Invalidate()
+    if _, err := r.Resolve(context.Background(), "web"); err != nil {
+        t.Fatalf("Resolve after Invalidate: %v", err)
+    }
+    if rt.calls != 2 {
+        t.Fatalf("runtime queried %d times after Invalidate, want 2", rt.calls)
+    }
+}
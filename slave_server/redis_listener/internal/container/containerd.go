@@ -0,0 +1,80 @@
+package container
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+)
+
+// DefaultContainerdStateDir containerd shim v2 保存任务状态的目录，结构为 <namespace>/<id>/init.pid
+const DefaultContainerdStateDir = "/run/containerd/io.containerd.runtime.v2.task"
+
+// Containerd 从 containerd 任务状态目录读取容器主进程PID。
+// containerd 的API是gRPC，这里直接读 shim 写下的 init.pid，不依赖其客户端库；
+// containerd 没有容器名称，ref 为容器ID或其唯一前缀。
+type Containerd struct {
+    stateDir string
+}
+
+// NewContainerd 创建 containerd 运行时，stateDir 为空时使用默认目录
+func NewContainerd(stateDir string) *Containerd {
+    if stateDir == "" {
+        stateDir = DefaultContainerdStateDir
+    }
+    return &Containerd{stateDir: stateDir}
+}
+
+func (c *Containerd) Name() string { return RuntimeContainerd }
+
+// Inspect 在所有命名空间（default、moby、k8s.io 等）中查找任务
+func (c *Containerd) Inspect(ctx context.Context, ref string) (*Container, error) {
+    namespaces, err := os.ReadDir(c.stateDir)
+    if err != nil {
+        return nil, fmt.Errorf("containerd state %s: %w", c.stateDir, err)
+    }
+
+    var matches []string
+    for _, ns := range namespaces {
+        tasks, err := os.ReadDir(filepath.Join(c.stateDir, ns.Name()))
+        if err != nil {
+            continue
+        }
+        for _, t := range tasks {
+            if t.Name() == ref {
+                matches = []string{filepath.Join(c.stateDir, ns.Name(), t.Name())}
+                break
+            }
+            if strings.HasPrefix(t.Name(), ref) {
+                matches = append(matches, filepath.Join(c.stateDir, ns.Name(), t.Name()))
+            }
+        }
+        if len(matches) == 1 && filepath.Base(matches[0]) == ref {
+            break
+        }
+    }
+    switch len(matches) {
+    case 0:
+        return nil, fmt.Errorf("%w: %s", ErrNotFound, ref)
+    case 1:
+    default:
+        return nil, fmt.Errorf("container id prefix %s is ambiguous (%d matches)", ref, len(matches))
+    }
+
+    data, err := os.ReadFile(filepath.Join(matches[0], "init.pid"))
+    if errors.Is(err, os.ErrNotExist) {
+        return nil, fmt.Errorf("container %s is not running", ref)
+    }
+    if err != nil {
+        return nil, err
+    }
+    pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+    if err != nil {
+        return nil, fmt.Errorf("container %s: invalid init.pid: %w", ref, err)
+    }
+    id := filepath.Base(matches[0])
+    return &Container{ID: id, Name: id, Pid: pid}, nil
+}
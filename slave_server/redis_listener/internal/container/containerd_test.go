@@ -0,0 +1,77 @@
+package container
+
+import (
+    "context"
+    "errors"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+// containerdState 构造 <namespace>/<id>/init.pid 结构的状态目录，pid 为空表示任务未运行
+func containerdState(t *testing.T, tasks map[string]string) string {
+    t.Helper()
+    dir := t.TempDir()
+    for path, pid := range tasks {
+        if err := os.MkdirAll(filepath.Join(dir, path), 0755); err != nil {
+            t.Fatal(err)
+        }
+        if pid == "" {
+            continue
+        }
+        if err := os.WriteFile(filepath.Join(dir, path, "init.pid"), []byte(pid), 0644); err != nil {
+            t.Fatal(err)
+        }
+    }
+    return dir
+}
+
+func TestContainerdInspect(t *testing.T) {
+    dir := containerdState(t, map[string]string{
+        "default/abc123":   "100\n",
+        "k8s.io/abc124":    "200",
+        "moby/def456":      "300",
+        "moby/stopped9":    "",
+        "default/badpid00": "x",
+    })
+    c := NewContainerd(dir)
+    ctx := context.Background()
+
+    tests := []struct {
+        ref     string
+        wantID  string
+        wantPid int
+        wantErr string
+    }{
+        {ref: "abc123", wantID: "abc123", wantPid: 100},
+        {ref: "def", wantID: "def456", wantPid: 300},
+        {ref: "abc124", wantID: "abc124", wantPid: 200},
+        {ref: "abc", wantErr: "ambiguous"},
+        {ref: "stopped9", wantErr: "not running"},
+        {ref: "badpid00", wantErr: "invalid init.pid"},
+    }
+    for _, tt := range tests {
+        got, err := c.Inspect(ctx, tt.ref)
+        if tt.wantErr != "" {
+            if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+                t.Errorf("Inspect(%q) error %v, want %q", tt.ref, err, tt.wantErr)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("Inspect(%q): %v", tt.ref, err)
+            continue
+        }
+        if got.ID != tt.wantID || got.Name != tt.wantID || got.Pid != tt.wantPid {
+            t.Errorf("Inspect(%q) = %+v, want id %s pid %d", tt.ref, got, tt.wantID, tt.wantPid)
+        }
+    }
+
+    if _, err := c.Inspect(ctx, "zzz"); !errors.Is(err, ErrNotFound) {
+        t.Errorf("Inspect(zzz) error %v, want ErrNotFound", err)
+    }
+    if _, err := NewContainerd(filepath.Join(dir, "absent")).Inspect(ctx, "abc123"); err == nil || errors.Is(err, ErrNotFound) {
+        t.Errorf("Inspect with missing state dir error %v, want a state dir error", err)
+    }
+}
@@ -0,0 +1,81 @@
+package container
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net"
+    "net/http"
+    "net/url"
+    "strings"
+    "time"
+)
+
+// DefaultDockerSocket dockerd 的本地API套接字
+const DefaultDockerSocket = "/var/run/docker.sock"
+
+// Docker 通过 dockerd 的本地套接字查询容器
+type Docker struct {
+    socket string
+    client *http.Client
+}
+
+// NewDocker 创建 Docker 运行时，socket 为空时使用默认路径
+func NewDocker(socket string) *Docker {
+    if socket == "" {
+        socket = DefaultDockerSocket
+    }
+    return &Docker{
+        socket: socket,
+        client: &http.Client{
+            Timeout: 5 * time.Second,
+            Transport: &http.Transport{
+                DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+                    var d net.Dialer
+                    return d.DialContext(ctx, "unix", socket)
+                },
+            },
+        },
+    }
+}
+
+func (d *Docker) Name() string { return RuntimeDocker }
+
+// Inspect GET /containers/<id|name>/json
+func (d *Docker) Inspect(ctx context.Context, ref string) (*Container, error) {
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+        "http://docker/containers/"+url.PathEscape(ref)+"/json", nil)
+    if err != nil {
+        return nil, err
+    }
+    resp, err := d.client.Do(req)
+    if err != nil {
+        return nil, fmt.Errorf("docker %s: %w", d.socket, err)
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode == http.StatusNotFound {
+        return nil, fmt.Errorf("%w: %s", ErrNotFound, ref)
+    }
+    if resp.StatusCode != http.StatusOK {
+        body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+        return nil, fmt.Errorf("docker inspect %s: %s: %s", ref, resp.Status, strings.TrimSpace(string(body)))
+    }
+
+    var info struct {
+        ID    string `json:"Id"`
+        Name  string `json:"Name"`
+        State struct {
+            Pid int `json:"Pid"`
+        } `json:"State"`
+    }
+    if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+        return nil, fmt.Errorf("decode docker inspect %s: %w", ref, err)
+    }
+    return &Container{
+        ID:   info.ID,
+        Name: strings.TrimPrefix(info.Name, "/"),
+        Pid:  info.State.Pid,
+    }, nil
+}
@@ -0,0 +1,67 @@
+package container
+
+import (
+    "context"
+    "errors"
+    "net"
+    "net/http"
+    "path/filepath"
+    "strings"
+    "testing"
+)
+
+// fakeDocker 在临时 unix 套接字上模拟 dockerd 的 inspect 接口
+func fakeDocker(t *testing.T, handler http.HandlerFunc) *Docker {
+    t.Helper()
+    socket := filepath.Join(t.TempDir(), "docker.sock")
+    l, err := net.Listen("unix", socket)
+    if err != nil {
+        t.Fatal(err)
+    }
+    srv := &http.Server{Handler: handler}
+    go srv.Serve(l)
+    t.Cleanup(func() { srv.Close() })
+    return NewDocker(socket)
+}
+
+func TestDockerInspect(t *testing.T) {
+    d := fakeDocker(t, func(w http.ResponseWriter, r *http.Request) {
+        switch r.URL.Path {
+        case "/containers/web/json":
+            w.Write([]byte(`{"Id":"0123abcd","Name":"/web","State":{"Running":true,"Pid":4242}}`))
+        case "/containers/broken/json":
+            w.Write([]byte(`{"Id":`))
+        case "/containers/busy/json":
+            http.Error(w, `{"message":"daemon busy"}`, http.StatusInternalServerError)
+        default:
+            http.Error(w, `{"message":"No such container"}`, http.StatusNotFound)
+        }
+    })
+    ctx := context.Background()
+
+    c, err := d.Inspect(ctx, "web")
+    if err != nil {
+        t.Fatalf("Inspect(web): %v", err)
+    }
+    if c.ID != "0123abcd" || c.Name != "web" || c.Pid != 4242 {
+        t.Fatalf("Inspect(web) = %+v", c)
+    }
+
+    if _, err := d.Inspect(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+        t.Errorf("Inspect(missing) error %v, want ErrNotFound", err)
+    }
+    if _, err := d.Inspect(ctx, "busy"); err == nil || errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), "daemon busy") {
+        t.Errorf("Inspect(busy) error %v, want the daemon message", err)
+    }
+    if _, err := d.Inspect(ctx, "broken"); err == nil || !strings.Contains(err.Error(), "decode") {
+        t.Errorf("Inspect(broken) error %v, want decode error", err)
+    }
+}
+
+func TestDockerUnavailable(t *testing.T) {
+    d := NewDocker(filepath.Join(t.TempDir(), "absent.sock"))
+    _, err := d.Inspect(context.Background(), "web")
+    if err == nil || errors.Is(err, ErrNotFound) {
+        t.Fatalf("Inspect without dockerd error %v, want a connection error", err)
+    }
+}
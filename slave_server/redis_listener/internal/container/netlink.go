@@ -0,0 +1,61 @@
+package container
+
+import (
+    "net"
+    "syscall"
+    "unsafe"
+)
+
+// linkInfo 通过 RTM_GETLINK 读取的网卡信息
+type linkInfo struct {
+    index int
+    name  string
+    mac   net.HardwareAddr
+    peer  int // IFLA_LINK：veth 对端在其命名空间中的网卡索引
+}
+
+// listLinks 列出当前线程所在网络命名空间中的网卡。
+// net.Interfaces 不提供 IFLA_LINK，这里直接解析 netlink 应答。
+func listLinks() ([]linkInfo, error) {
+    tab, err := syscall.NetlinkRIB(syscall.RTM_GETLINK, syscall.AF_UNSPEC)
+    if err != nil {
+        return nil, err
+    }
+    msgs, err := syscall.ParseNetlinkMessage(tab)
+    if err != nil {
+        return nil, err
+    }
+
+    var links []linkInfo
+    for i := range msgs {
+        m := &msgs[i]
+        if m.Header.Type == syscall.NLMSG_DONE {
+            break
+        }
+        if m.Header.Type != syscall.RTM_NEWLINK || len(m.Data) < syscall.SizeofIfInfomsg {
+            continue
+        }
+        ifim := (*syscall.IfInfomsg)(unsafe.Pointer(&m.Data[0]))
+        attrs, err := syscall.ParseNetlinkRouteAttr(m)
+        if err != nil {
+            return nil, err
+        }
+        l := linkInfo{index: int(ifim.Index)}
+        for _, a := range attrs {
+            switch a.Attr.Type {
+            case syscall.IFLA_IFNAME:
+                if n := len(a.Value); n > 0 {
+                    l.name = string(a.Value[:n-1])
+                }
+            case syscall.IFLA_ADDRESS:
+                l.mac = append(net.HardwareAddr(nil), a.Value...)
+            case syscall.IFLA_LINK:
+                if len(a.Value) >= 4 {
+                    l.peer = int(*(*uint32)(unsafe.Pointer(&a.Value[0])))
+                }
+            }
+        }
+        links = append(links, l)
+    }
+    return links, nil
+}
@@ -0,0 +1,88 @@
+package daemon
+
+import (
+    "fmt"
+    "log"
+    "path/filepath"
+    "sync"
+
+    "github.com/cilium/ebpf"
+    "github.com/vishvananda/netlink"
+
+    "netsimlation/distribute/ebpf/pkg/instance"
+    "netsimlation/distribute/ebpf/pkg/tc"
+)
+
+// filterName 与 ebpf-network-emulation 的过滤器名称一致，其 -status/-clear 据此识别
+const filterName = "edt_bandwidth"
+
+// attacher 把 containers.attach_from 上正在运行的程序挂载到容器的宿主机侧veth。
+// 各veth共用同一程序及其映射，条目按 ifindex 区分
+type attacher struct {
+    prog *ebpf.Program
+    inst *instance.Instance
+
+    mu   sync.Mutex
+    done map[int]string // 已挂载的 ifindex -> 网卡名，veth重建后索引可能被复用
+}
+
+// newAttacher 取出 iface 上由实例 inst 的 ebpf-network-emulation 挂载的程序
+func newAttacher(iface string, inst *instance.Instance) (*attacher, error) {
+    link, err := netlink.LinkByName(iface)
+    if err != nil {
+        return nil, fmt.Errorf("attach_from interface %s: %w", iface, err)
+    }
+    id, mode, err := tc.AttachedProgram(link, tc.AttachOptions{
+        Name:        filterName,
+        LinkPinPath: filepath.Join(inst.LinkDir(""), iface),
+    })
+    if err != nil {
+        return nil, fmt.Errorf("attach_from interface %s: %w", iface, err)
+    }
+    prog, err := ebpf.NewProgramFromID(ebpf.ProgramID(id))
+    if err != nil {
+        return nil, fmt.Errorf("open program %d on %s: %w", id, iface, err)
+    }
+    log.Printf("将 %s 上的程序 (prog id %d, %s) 挂载到目的容器的宿主机侧veth", iface, id, mode)
+    return &attacher{prog: prog, inst: inst, done: make(map[int]string)}, nil
+}
+
+// ensure 在 ifindex 对应的网卡上挂载程序，已挂载过时直接返回。
+// 与 ebpf-network-emulation 一样先检查其他实例是否已挂载该网卡，并把原 qdisc 快照记录在实例的状态目录，
+// 使 -clear -instance 能卸载并恢复这些veth
+func (a *attacher) ensure(ifindex int) error {
+    link, err := netlink.LinkByIndex(ifindex)
+    if err != nil {
+        return fmt.Errorf("host veth ifindex %d: %w", ifindex, err)
+    }
+    name := link.Attrs().Name
+
+    a.mu.Lock()
+    defer a.mu.Unlock()
+    if a.done[ifindex] == name {
+        return nil
+    }
+    other, err := a.inst.Owner("", name)
+    if err != nil {
+        return fmt.Errorf("check instances attached to %s: %w", name, err)
+    }
+    if other != nil {
+        return fmt.Errorf("%s is already attached by instance %s", name, other.Name)
+    }
+    mode, err := tc.Attach(link, tc.AttachOptions{
+        ProgFD:      a.prog.FD(),
+        Name:        filterName,
+        LinkPinPath: filepath.Join(a.inst.LinkDir(""), name),
+        StateDir:    a.inst.SnapshotDir(""),
+    })
+    if err != nil {
+        return err
+    }
+    a.done[ifindex] = name
+    log.Printf("已挂载到容器veth %s (%s)", name, mode)
+    return nil
+}
+
+func (a *attacher) Close() error {
+    return a.prog.Close()
+}
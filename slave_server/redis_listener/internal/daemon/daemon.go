@@ -2,34 +2,110 @@ package daemon
 
 import (
     "context"
+    "encoding/json"
+    "errors"
+    "fmt"
     "log"
+    "net"
+    "sync"
     "time"
 
+    "netsimlation/distribute/slave_server/redis_listener/internal/bpfmap"
+    "netsimlation/distribute/slave_server/redis_listener/internal/clock"
     "netsimlation/distribute/slave_server/redis_listener/internal/config"
+    "netsimlation/distribute/slave_server/redis_listener/internal/container"
+    "netsimlation/distribute/slave_server/redis_listener/internal/link"
+    "netsimlation/distribute/slave_server/redis_listener/internal/netns"
     "netsimlation/distribute/slave_server/redis_listener/internal/redis"
+    "netsimlation/distribute/slave_server/redis_listener/internal/rpc"
+    "netsimlation/distribute/slave_server/redis_listener/internal/transport"
+
+    "netsimlation/distribute/ebpf/pkg/instance"
 )
 
 type Daemon struct {
     config *config.Config
+
+    ctx       context.Context
+    transport transport.Transport
+
+    // 以下字段仅在配置了 ebpf.iface 或 containers.runtime 时使用。
+    // 解析容器需要访问运行时，不在持有 mu 时进行
+    mu         sync.Mutex
+    syncMu     sync.Mutex // 串行化 syncGeneration
+    bpf        *bpfmap.Map
+    ifindex    uint32
+    containers *container.Resolver
+    attacher   *attacher // 仅在配置了 containers.attach_from 时使用
+    appliedGen int64
+    linkKeys   map[string]bpfmap.FlowKey // 链路ID -> 映射键，用于处理DEL事件
+    genLinks   map[string]bool           // 当前配置代中的链路ID，不随单条链路的快照清理删除
+
+    // 携带 apply_at 的变更按同步后的主控时钟在本地定时生效
+    clock   *clock.Clock
+    pending map[string][]*pendingChange
 }
 
 func NewDaemon(cfg *config.Config) *Daemon {
     return &Daemon{
-        config: cfg,
+        config:   cfg,
+        linkKeys: make(map[string]bpfmap.FlowKey),
+        clock:    clock.New(),
+        pending:  make(map[string][]*pendingChange),
     }
 }
 
 func (d *Daemon) Run(ctx context.Context) error {
     log.Printf("启动 %s v%s", d.config.App.Name, d.config.App.Version)
-    
-    subscriber := redis.NewSubscriber(&d.config.Redis)
-    defer subscriber.Close()
+    d.ctx = ctx
+
+    if d.config.Containers.Runtime != "" {
+        d.containers = newResolver(&d.config.Containers)
+        log.Printf("容器端点通过 %s 解析", d.config.Containers.Runtime)
+    }
+    if d.config.Ebpf.Iface != "" || d.containers != nil {
+        if err := d.openMap(); err != nil {
+            return err
+        }
+        defer d.bpf.Close()
+        if d.containers != nil && d.config.Containers.AttachFrom != "" {
+            inst, err := instance.Lookup(d.config.Ebpf.Instance, d.config.Ebpf.PinPath)
+            if err != nil {
+                return fmt.Errorf("ebpf instance: %w", err)
+            }
+            a, err := newAttacher(d.config.Containers.AttachFrom, inst)
+            if err != nil {
+                return err
+            }
+            d.attacher = a
+            defer a.Close()
+        }
+    } else {
+        log.Println("未配置 ebpf.iface 与 containers.runtime，仅记录链路事件")
+    }
+
+    t, err := newTransport(d.config)
+    if err != nil {
+        return err
+    }
+    d.transport = t
+    defer d.transport.Close()
+
+    // 启动时先对齐当前生效的配置代，并开始与主控端同步时钟
+    if d.bpf != nil {
+        if err := d.syncGeneration(); err != nil {
+            log.Printf("同步生效配置代失败: %v", err)
+        }
+        go d.syncClockLoop(ctx)
+    }
 
-    // 启动Redis订阅
+    go d.statusLoop(ctx)
+
+    // 开始接收配置变更事件
     errCh := make(chan error, 1)
     
     go func() {
-        if err := subscriber.Subscribe(ctx, d.handleKeyEvent); err != nil {
+        if err := d.transport.Subscribe(ctx, d.handleKeyEvent); err != nil {
             errCh <- err
         }
     }()
@@ -47,7 +123,100 @@ func (d *Daemon) Run(ctx context.Context) error {
     }
 }
 
-func (d *Daemon) handleKeyEvent(event redis.KeyEvent) {
+// statusLoop 定期向主控端上报本节点状态，键的过期时间为三个上报周期
+func (d *Daemon) statusLoop(ctx context.Context) {
+    interval := time.Duration(d.config.Server.StatusIntervalSeconds) * time.Second
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        d.mu.Lock()
+        appliedGen, links := d.appliedGen, len(d.linkKeys)
+        d.mu.Unlock()
+        offset, _, synced := d.clock.Offset()
+
+        err := d.transport.ReportStatus(ctx, transport.Status{
+            Node:        d.config.Clock.NodeID,
+            Version:     d.config.App.Version,
+            Iface:       d.config.Ebpf.Iface,
+            AppliedGen:  appliedGen,
+            Links:       links,
+            OffsetMs:    float64(offset) / float64(time.Millisecond),
+            ClockSynced: synced,
+            LastSeen:    time.Now(),
+        }, 3*interval)
+        if err != nil && ctx.Err() == nil {
+            log.Printf("上报节点状态失败: %v", err)
+        }
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+// newTransport 按配置创建与主控端的通信方式
+func newTransport(cfg *config.Config) (transport.Transport, error) {
+    if cfg.Transport == transport.TypeGRPC {
+        log.Printf("通过gRPC连接主控端 %s", cfg.Grpc.Addr)
+        return rpc.NewClient(cfg)
+    }
+    return redis.NewSubscriber(&cfg.Redis), nil
+}
+
+// newResolver 按配置创建容器端点解析器
+func newResolver(cfg *config.ContainerConfig) *container.Resolver {
+    var runtime container.Runtime
+    if cfg.Runtime == container.RuntimeContainerd {
+        runtime = container.NewContainerd(cfg.ContainerdStateDir)
+    } else {
+        runtime = container.NewDocker(cfg.DockerSocket)
+    }
+    return container.NewResolver(runtime, cfg.Iface, time.Duration(cfg.CacheSeconds)*time.Second)
+}
+
+// openMap 解析目标网卡并加载固定的链路映射。
+// 网卡位于容器中时在其网络命名空间内解析，条目使用命名空间内的网卡索引，与数据面看到的一致
+func (d *Daemon) openMap() error {
+    if d.config.Ebpf.Instance != "" {
+        log.Printf("使用 ebpf-network-emulation 实例 %s 的固定映射 (%s)", d.config.Ebpf.Instance, d.config.Ebpf.PinPath)
+    }
+    if d.config.Ebpf.Iface == "" {
+        m, err := bpfmap.Open(d.config.Ebpf.PinPath)
+        if err != nil {
+            return err
+        }
+        d.bpf = m
+        log.Println("未配置 ebpf.iface，只落地以 dest_container 描述的链路")
+        return nil
+    }
+
+    var iface *net.Interface
+    err := netns.Do(d.config.Ebpf.Netns, func() error {
+        var err error
+        iface, err = net.InterfaceByName(d.config.Ebpf.Iface)
+        return err
+    })
+    if err != nil {
+        return fmt.Errorf("interface %s not found: %w", d.config.Ebpf.Iface, err)
+    }
+    m, err := bpfmap.Open(d.config.Ebpf.PinPath)
+    if err != nil {
+        return err
+    }
+    d.bpf = m
+    d.ifindex = uint32(iface.Index)
+    if d.config.Ebpf.Netns != "" {
+        log.Printf("链路配置将写入网络命名空间 %s 中 %s (ifindex %d) 的eBPF映射", d.config.Ebpf.Netns, iface.Name, iface.Index)
+        return nil
+    }
+    log.Printf("链路配置将写入 %s (ifindex %d) 的eBPF映射", iface.Name, iface.Index)
+    return nil
+}
+
+func (d *Daemon) handleKeyEvent(event transport.Event) {
     // 这里是核心处理逻辑 - 打印到日志
     switch event.EventType {
     case "set":
@@ -59,7 +228,246 @@ func (d *Daemon) handleKeyEvent(event redis.KeyEvent) {
     case "expired":
         log.Printf("EXPIRED事件 - 键: %s, 时间: %s", 
             event.Key, event.Timestamp.Format(time.RFC3339))
+    case transport.SnapshotEvent:
+        log.Printf("全量推送结束 - 时间: %s", event.Timestamp.Format(time.RFC3339))
     default:
         log.Printf("未知事件类型 %s - 键: %s", event.EventType, event.Key)
     }
-}
\ No newline at end of file
+
+    if d.bpf == nil {
+        return
+    }
+    if err := d.applyEvent(event); err != nil {
+        log.Printf("应用事件失败 - 键: %s: %v", event.Key, err)
+    }
+}
+
+// applyEvent 将键事件落地到eBPF映射
+func (d *Daemon) applyEvent(event transport.Event) error {
+    if event.EventType == transport.SnapshotEvent {
+        var ids []string
+        if err := json.Unmarshal([]byte(event.Value), &ids); err != nil {
+            return fmt.Errorf("decode snapshot: %w", err)
+        }
+        return d.pruneLinks(ids)
+    }
+    if event.Key == transport.GenActiveKey {
+        if event.EventType != "set" {
+            return nil
+        }
+        return d.syncGeneration()
+    }
+
+    id, ok := link.IDFromKey(event.Key)
+    if !ok {
+        return nil
+    }
+    switch event.EventType {
+    case "set":
+        l, err := link.Parse(event.Value)
+        if err != nil {
+            return err
+        }
+        if l.ApplyAt > 0 {
+            d.scheduleLink(id, l)
+            return nil
+        }
+        return d.applyLink(id, l)
+    case "del", "expired":
+        return d.removeLink(id)
+    }
+    return nil
+}
+
+// syncGeneration 读取当前生效的配置代并整体替换映射内容。
+// 事件并发到达时只会应用最新的指针值，已应用的代不会重复写入。
+// 无法落地的链路（如容器运行时不可用）记录日志后跳过，不影响同一代的其他链路。
+func (d *Daemon) syncGeneration() error {
+    d.syncMu.Lock()
+    defer d.syncMu.Unlock()
+
+    gen, err := d.transport.ActiveGeneration(d.ctx)
+    if err != nil {
+        return err
+    }
+    d.mu.Lock()
+    appliedGen := d.appliedGen
+    d.mu.Unlock()
+    if gen == 0 || gen == appliedGen {
+        return nil
+    }
+
+    raw, err := d.transport.LoadGeneration(d.ctx, gen)
+    if err != nil {
+        return err
+    }
+    // 新的配置代通常伴随拓扑重建，容器可能已重新创建
+    if d.containers != nil {
+        d.containers.Invalidate()
+    }
+
+    desired := make(map[bpfmap.FlowKey]bpfmap.HandleBpsDelay, len(raw))
+    keys := make(map[string]bpfmap.FlowKey, len(raw))
+    genLinks := make(map[string]bool, len(raw))
+    for id, val := range raw {
+        genLinks[id] = true
+        l, err := link.Parse(val)
+        if err != nil {
+            return fmt.Errorf("generation %d link %s: %w", gen, id, err)
+        }
+        key, value, ok, err := d.entry(l)
+        if err != nil {
+            log.Printf("第 %d 代链路 %s 无法落地，跳过: %v", gen, id, err)
+            continue
+        }
+        if !ok {
+            continue
+        }
+        desired[key] = value
+        keys[id] = key
+    }
+
+    d.mu.Lock()
+    defer d.mu.Unlock()
+    if err := d.bpf.Replace(desired); err != nil {
+        return fmt.Errorf("apply generation %d: %w", gen, err)
+    }
+    log.Printf("已应用第 %d 代配置（上一代: %d），共 %d 条链路", gen, d.appliedGen, len(desired))
+    // 旧配置代上排期的变更不再适用
+    for id := range d.pending {
+        d.cancelPending(id)
+    }
+    d.appliedGen = gen
+    d.linkKeys = keys
+    d.genLinks = genLinks
+    return nil
+}
+
+// applyLink 写入单条链路
+func (d *Daemon) applyLink(id string, l *link.NetworkLink) error {
+    key, value, ok, err := d.entry(l)
+    if err != nil {
+        return err
+    }
+    d.mu.Lock()
+    defer d.mu.Unlock()
+    return d.writeLinkLocked(id, key, value, ok)
+}
+
+// writeLinkLocked 写入 entry 的结果，ok 为 false 时清除链路之前写入的条目。调用方须持有 d.mu
+func (d *Daemon) writeLinkLocked(id string, key bpfmap.FlowKey, value bpfmap.HandleBpsDelay, ok bool) error {
+    old, exists := d.linkKeys[id]
+    if !ok {
+        // 目的容器不在本机（或已迁走），清除之前写入的条目
+        if exists {
+            if err := d.bpf.Delete(old); err != nil {
+                return err
+            }
+            delete(d.linkKeys, id)
+        }
+        return nil
+    }
+    // 同一链路ID更换了源MAC或目的网卡时删除旧条目
+    if exists && old != key {
+        if err := d.bpf.Delete(old); err != nil {
+            return err
+        }
+    }
+    if err := d.bpf.Put(key, value); err != nil {
+        return err
+    }
+    d.linkKeys[id] = key
+    return nil
+}
+
+// entry 将链路转换为本机映射条目。
+// dest_container 解析为宿主机侧veth的ifindex，source_container 在未给出 source_mac 时解析为容器网卡的MAC；
+// ok 为 false 表示链路与本机无关：目的容器不在本机，或既没有目的容器也没有配置 ebpf.iface
+func (d *Daemon) entry(l *link.NetworkLink) (key bpfmap.FlowKey, value bpfmap.HandleBpsDelay, ok bool, err error) {
+    if (l.DestContainer != "" || l.SourceContainer != "") && d.containers == nil {
+        return key, value, false, errors.New("link refers to containers but containers.runtime is not configured")
+    }
+
+    ifindex := d.ifindex
+    if l.DestContainer != "" {
+        ep, err := d.containers.Resolve(d.ctx, l.DestContainer)
+        if errors.Is(err, container.ErrNotFound) {
+            return key, value, false, nil
+        }
+        if err != nil {
+            return key, value, false, fmt.Errorf("resolve dest container: %w", err)
+        }
+        if d.attacher != nil {
+            if err := d.attacher.ensure(ep.HostIfindex); err != nil {
+                return key, value, false, fmt.Errorf("attach to dest container %s: %w", l.DestContainer, err)
+            }
+        }
+        ifindex = uint32(ep.HostIfindex)
+    }
+    if ifindex == 0 {
+        return key, value, false, nil
+    }
+
+    if l.SourceContainer != "" && l.SourceMAC == "" {
+        ep, err := d.containers.Resolve(d.ctx, l.SourceContainer)
+        if err != nil {
+            return key, value, false, fmt.Errorf("resolve source container: %w", err)
+        }
+        resolved := *l
+        resolved.SourceMAC = ep.MAC.String()
+        l = &resolved
+    }
+
+    key, value, err = l.Entry(ifindex)
+    return key, value, err == nil, err
+}
+
+// removeLink 删除单条链路，并取消其尚未生效的排期变更
+func (d *Daemon) removeLink(id string) error {
+    d.mu.Lock()
+    defer d.mu.Unlock()
+
+    d.cancelPending(id)
+    key, ok := d.linkKeys[id]
+    if !ok {
+        return nil
+    }
+    if err := d.bpf.Delete(key); err != nil {
+        return err
+    }
+    delete(d.linkKeys, id)
+    return nil
+}
+
+// pruneLinks 删除既不在全量推送 ids 中、也不属于当前配置代的链路及其排期变更，
+// 即与主控端断开期间被删除的链路
+func (d *Daemon) pruneLinks(ids []string) error {
+    live := make(map[string]bool, len(ids))
+    for _, id := range ids {
+        live[id] = true
+    }
+
+    d.mu.Lock()
+    var stale []string
+    for id := range d.linkKeys {
+        if !live[id] && !d.genLinks[id] {
+            stale = append(stale, id)
+        }
+    }
+    for id := range d.pending {
+        if !live[id] && !d.genLinks[id] {
+            if _, ok := d.linkKeys[id]; !ok {
+                stale = append(stale, id)
+            }
+        }
+    }
+    d.mu.Unlock()
+
+    for _, id := range stale {
+        log.Printf("链路 %s 已不在主控端，删除", id)
+        if err := d.removeLink(id); err != nil {
+            return fmt.Errorf("remove link %s: %w", id, err)
+        }
+    }
+    return nil
+}
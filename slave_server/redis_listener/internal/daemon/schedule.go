@@ -0,0 +1,139 @@
+package daemon
+
+import (
+    "context"
+    "log"
+    "time"
+
+    "netsimlation/distribute/slave_server/redis_listener/internal/link"
+    "netsimlation/distribute/slave_server/redis_listener/internal/transport"
+)
+
+// pendingChange 已排期但尚未生效的链路变更
+type pendingChange struct {
+    applyAt int64
+    timer   *time.Timer
+}
+
+// syncClockLoop 启动时及之后每隔 sync_interval_seconds 与主控端同步一次时钟
+func (d *Daemon) syncClockLoop(ctx context.Context) {
+    interval := time.Duration(d.config.Clock.SyncIntervalSeconds) * time.Second
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        d.syncClock(ctx)
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
+
+func (d *Daemon) syncClock(ctx context.Context) {
+    samples, err := d.transport.ClockExchange(ctx, d.config.Clock.NodeID, d.config.Clock.Samples)
+    if err != nil {
+        if ctx.Err() == nil {
+            log.Printf("时钟同步失败，沿用上次估计: %v", err)
+        }
+        return
+    }
+    d.clock.Update(samples)
+    offset, delay, _ := d.clock.Offset()
+    log.Printf("时钟同步完成: 偏移 %v, 往返时延 %v (%d/%d 样本)", offset, delay, len(samples), d.config.Clock.Samples)
+}
+
+// scheduleLink 按 apply_at 在本地定时应用链路变更。
+// 同一链路上计划时刻不早于新变更的待生效变更会被取消，较早的变更保留以维持时间线顺序。
+func (d *Daemon) scheduleLink(id string, l *link.NetworkLink) {
+    if _, _, synced := d.clock.Offset(); !synced {
+        log.Printf("警告: 尚未与主控端完成时钟同步，链路 %s 按本地时钟排期", id)
+    }
+    at := d.clock.LocalTime(l.ApplyAt)
+
+    d.mu.Lock()
+    defer d.mu.Unlock()
+
+    kept := d.pending[id][:0]
+    for _, p := range d.pending[id] {
+        if p.applyAt >= l.ApplyAt && p.timer.Stop() {
+            continue
+        }
+        kept = append(kept, p)
+    }
+
+    p := &pendingChange{applyAt: l.ApplyAt}
+    p.timer = time.AfterFunc(time.Until(at), func() {
+        d.firePending(id, p, l)
+    })
+    d.pending[id] = append(kept, p)
+    log.Printf("链路 %s 已排期，计划于 %s（本地时间）生效", id, at.Format("15:04:05.000"))
+}
+
+// firePending 定时器到期时应用变更并上报偏差。
+// 排期后链路被删除或整体替换为新的配置代时变更已从 d.pending 中摘除，此时不再应用
+func (d *Daemon) firePending(id string, p *pendingChange, l *link.NetworkLink) {
+    key, value, ok, err := d.entry(l)
+
+    d.mu.Lock()
+    if !d.dropPending(id, p) {
+        d.mu.Unlock()
+        log.Printf("链路 %s 的排期变更已取消", id)
+        return
+    }
+    if err == nil {
+        err = d.writeLinkLocked(id, key, value, ok)
+    }
+    appliedAt := d.clock.MasterNow()
+    d.mu.Unlock()
+
+    if err != nil {
+        log.Printf("定时应用链路 %s 失败: %v", id, err)
+        return
+    }
+    d.reportApply(id, l.ApplyAt, appliedAt)
+}
+
+// dropPending 从待生效列表中摘除 p，p 不在列表中时返回 false。调用方须持有 d.mu
+func (d *Daemon) dropPending(id string, p *pendingChange) bool {
+    list := d.pending[id]
+    for i, q := range list {
+        if q != p {
+            continue
+        }
+        list = append(list[:i], list[i+1:]...)
+        if len(list) == 0 {
+            delete(d.pending, id)
+        } else {
+            d.pending[id] = list
+        }
+        return true
+    }
+    return false
+}
+
+// cancelPending 停止并丢弃链路的全部待生效变更。调用方须持有 d.mu
+func (d *Daemon) cancelPending(id string) {
+    for _, p := range d.pending[id] {
+        p.timer.Stop()
+    }
+    delete(d.pending, id)
+}
+
+// reportApply 计算实际生效时刻与计划时刻的偏差并上报给主控端
+func (d *Daemon) reportApply(id string, applyAt int64, appliedAt time.Time) {
+    offset, _, _ := d.clock.Offset()
+    report := transport.ApplyReport{
+        Node:      d.config.Clock.NodeID,
+        Link:      id,
+        ApplyAt:   applyAt,
+        AppliedAt: appliedAt.UnixMilli(),
+        SkewMs:    float64(appliedAt.UnixNano()-applyAt*int64(time.Millisecond)) / float64(time.Millisecond),
+        OffsetMs:  float64(offset) / float64(time.Millisecond),
+    }
+    log.Printf("链路 %s 已生效，偏差 %.3fms", id, report.SkewMs)
+    if err := d.transport.ReportApply(d.ctx, report); err != nil {
+        log.Printf("上报生效偏差失败: %v", err)
+    }
+}
@@ -0,0 +1,74 @@
+package link
+
+import (
+    "encoding/json"
+    "fmt"
+    "math"
+    "net"
+    "strings"
+
+    "netsimlation/distribute/slave_server/redis_listener/internal/bpfmap"
+)
+
+// KeyPrefix 主控端写入单条链路使用的键前缀
+const KeyPrefix = "network_link:"
+
+// AdminDown 链路被管理性关闭，数据面丢弃其全部流量；空值或 "up" 表示开启
+const AdminDown = "down"
+
+// NetworkLink 与主控端（netsimctl、master-server 等）写入Redis的链路结构保持一致
+type NetworkLink struct {
+    SourceMAC      string  `json:"source_mac"`
+    DestNodeID     int     `json:"dest_node_id"`
+    PacketLossRate float64 `json:"packet_loss_rate"`
+    BandwidthBps   uint64  `json:"bandwidth_bps"`
+    DelayMs        uint32  `json:"delay_ms"`
+    CreatedAt      string  `json:"created_at"`
+    // 主控时钟下的计划生效时刻（Unix毫秒），为0表示立即生效
+    ApplyAt        int64   `json:"apply_at,omitempty"`
+    AdminState     string  `json:"admin_state,omitempty"`
+    // 以容器ID或名称描述的端点，由 container.Resolver 解析为MAC与宿主机侧veth
+    SourceContainer string `json:"source_container,omitempty"`
+    DestContainer   string `json:"dest_container,omitempty"`
+}
+
+// Parse 解析Redis中保存的链路JSON
+func Parse(value string) (*NetworkLink, error) {
+    var l NetworkLink
+    if err := json.Unmarshal([]byte(value), &l); err != nil {
+        return nil, fmt.Errorf("decode network link: %w", err)
+    }
+    return &l, nil
+}
+
+// IDFromKey 从 network_link:<id> 键名中取出链路ID
+func IDFromKey(key string) (string, bool) {
+    if !strings.HasPrefix(key, KeyPrefix) {
+        return "", false
+    }
+    return strings.TrimPrefix(key, KeyPrefix), true
+}
+
+// Entry 将链路转换为指定网卡上的eBPF映射条目
+func (l *NetworkLink) Entry(ifindex uint32) (bpfmap.FlowKey, bpfmap.HandleBpsDelay, error) {
+    var key bpfmap.FlowKey
+    mac, err := net.ParseMAC(l.SourceMAC)
+    if err != nil || len(mac) != 6 {
+        return key, bpfmap.HandleBpsDelay{}, fmt.Errorf("invalid source MAC %q", l.SourceMAC)
+    }
+    key.Ifindex = ifindex
+    copy(key.SrcMac[:], mac)
+    // 数据面的 throttle_rate_bps 为32位
+    if l.BandwidthBps > math.MaxUint32 {
+        return key, bpfmap.HandleBpsDelay{}, fmt.Errorf("bandwidth %d bytes/s exceeds the datapath limit of %d", l.BandwidthBps, uint32(math.MaxUint32))
+    }
+
+    value := bpfmap.HandleBpsDelay{
+        ThrottleRateBps: uint32(l.BandwidthBps),
+        DelayMs:         l.DelayMs,
+    }
+    if l.AdminState == AdminDown {
+        value.AdminState = bpfmap.AdminDown
+    }
+    return key, value, nil
+}
@@ -0,0 +1,69 @@
+package netns
+
+import (
+    "fmt"
+    "path/filepath"
+    "runtime"
+    "strconv"
+    "strings"
+
+    "golang.org/x/sys/unix"
+)
+
+// NamedPath ip netns add 创建的命名空间所在目录
+const NamedPath = "/var/run/netns"
+
+// Path 解析命名空间参数：纯数字为进程PID（/proc/<pid>/ns/net），
+// 含 / 的为命名空间文件路径，其余为 ip netns 的名称
+func Path(spec string) (string, error) {
+    switch {
+    case spec == "":
+        return "", fmt.Errorf("empty network namespace")
+    case strings.Contains(spec, "/"):
+        return spec, nil
+    }
+    if pid, err := strconv.Atoi(spec); err == nil {
+        if pid <= 0 {
+            return "", fmt.Errorf("invalid pid %d", pid)
+        }
+        return fmt.Sprintf("/proc/%d/ns/net", pid), nil
+    }
+    return filepath.Join(NamedPath, spec), nil
+}
+
+// Do 在 spec 指定的网络命名空间中执行 fn，spec 为空时直接执行。
+// 执行期间当前 goroutine 固定在所在线程上；切回原命名空间失败时线程保持锁定，
+// goroutine 结束后由运行时销毁，不会影响其他 goroutine。
+func Do(spec string, fn func() error) error {
+    if spec == "" {
+        return fn()
+    }
+    path, err := Path(spec)
+    if err != nil {
+        return err
+    }
+    target, err := unix.Open(path, unix.O_RDONLY|unix.O_CLOEXEC, 0)
+    if err != nil {
+        return fmt.Errorf("open network namespace %s: %w", path, err)
+    }
+    defer unix.Close(target)
+
+    runtime.LockOSThread()
+    orig, err := unix.Open("/proc/thread-self/ns/net", unix.O_RDONLY|unix.O_CLOEXEC, 0)
+    if err != nil {
+        runtime.UnlockOSThread()
+        return fmt.Errorf("open current network namespace: %w", err)
+    }
+    defer unix.Close(orig)
+
+    if err := unix.Setns(target, unix.CLONE_NEWNET); err != nil {
+        runtime.UnlockOSThread()
+        return fmt.Errorf("enter network namespace %s: %w", path, err)
+    }
+    ferr := fn()
+    if err := unix.Setns(orig, unix.CLONE_NEWNET); err != nil {
+        return fmt.Errorf("restore network namespace: %w", err)
+    }
+    runtime.UnlockOSThread()
+    return ferr
+}
@@ -0,0 +1,104 @@
+package redis
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "time"
+
+    "netsimlation/distribute/slave_server/redis_listener/internal/clock"
+    "netsimlation/distribute/slave_server/redis_listener/internal/transport"
+)
+
+// 与主控端 clocksync 包约定的频道与键名
+const (
+    clockRequestChannel        = "netsim:clock:req"
+    clockResponseChannelPrefix = "netsim:clock:resp:"
+    applyReportChannel         = "netsim:apply:report"
+    applySkewKeyPrefix         = "netsim:apply:skew:"
+
+    clockResponseTimeout = time.Second
+)
+
+type clockRequest struct {
+    Node string `json:"node"`
+    Seq  int    `json:"seq"`
+    T0   int64  `json:"t0"`
+}
+
+type clockResponse struct {
+    Seq int   `json:"seq"`
+    T0  int64 `json:"t0"`
+    T1  int64 `json:"t1"`
+    T2  int64 `json:"t2"`
+}
+
+// ClockExchange 通过Redis频道与主控端进行 samples 次时间戳交换。
+// 超时未收到回复的样本被丢弃，只要有一个样本成功即返回。
+func (s *Subscriber) ClockExchange(ctx context.Context, node string, samples int) ([]clock.Sample, error) {
+    pubsub := s.client.Subscribe(ctx, clockResponseChannelPrefix+node)
+    defer pubsub.Close()
+    // 确认订阅生效后再发请求，避免错过回复
+    if _, err := pubsub.Receive(ctx); err != nil {
+        return nil, err
+    }
+    ch := pubsub.Channel()
+
+    var result []clock.Sample
+    for seq := 0; seq < samples; seq++ {
+        req, _ := json.Marshal(clockRequest{Node: node, Seq: seq, T0: time.Now().UnixNano()})
+        if err := s.client.Publish(ctx, clockRequestChannel, req).Err(); err != nil {
+            return nil, err
+        }
+
+        timeout := time.NewTimer(clockResponseTimeout)
+    wait:
+        for {
+            select {
+            case <-ctx.Done():
+                timeout.Stop()
+                return nil, ctx.Err()
+            case <-timeout.C:
+                break wait
+            case msg, ok := <-ch:
+                if !ok {
+                    timeout.Stop()
+                    return nil, fmt.Errorf("clock response channel closed")
+                }
+                t3 := time.Now().UnixNano()
+                var resp clockResponse
+                // 丢弃上一轮超时后迟到的回复
+                if err := json.Unmarshal([]byte(msg.Payload), &resp); err != nil || resp.Seq != seq {
+                    continue
+                }
+                result = append(result, clock.Sample{T0: resp.T0, T1: resp.T1, T2: resp.T2, T3: t3})
+                timeout.Stop()
+                break wait
+            }
+        }
+    }
+
+    if len(result) == 0 {
+        return nil, fmt.Errorf("no clock response from master within %v", clockResponseTimeout)
+    }
+    return result, nil
+}
+
+// ReportApply 发布生效偏差并记录到 netsim:apply:skew:<node>
+func (s *Subscriber) ReportApply(ctx context.Context, report transport.ApplyReport) error {
+    data, err := json.Marshal(report)
+    if err != nil {
+        return err
+    }
+    pipe := s.client.Pipeline()
+    pipe.Publish(ctx, applyReportChannel, data)
+    pipe.HSet(ctx, applySkewKeyPrefix+report.Node, map[string]interface{}{
+        "link":       report.Link,
+        "apply_at":   report.ApplyAt,
+        "applied_at": report.AppliedAt,
+        "skew_ms":    report.SkewMs,
+        "offset_ms":  report.OffsetMs,
+    })
+    _, err = pipe.Exec(ctx)
+    return err
+}
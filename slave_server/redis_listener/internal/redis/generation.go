@@ -0,0 +1,60 @@
+package redis
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "strconv"
+
+    "github.com/go-redis/redis/v8"
+    "netsimlation/distribute/slave_server/redis_listener/internal/transport"
+)
+
+// 与主控端 store 包约定的配置代键名
+const (
+    GenActiveKey = transport.GenActiveKey
+    genKeyPrefix = "netsim:gen:"
+)
+
+type generationMeta struct {
+    ID        int64  `json:"id"`
+    LinkCount int    `json:"link_count"`
+    CreatedAt string `json:"created_at"`
+}
+
+// ActiveGeneration 读取当前生效的代号，尚未激活任何代时返回0
+func (s *Subscriber) ActiveGeneration(ctx context.Context) (int64, error) {
+    val, err := s.client.Get(ctx, GenActiveKey).Result()
+    if err == redis.Nil {
+        return 0, nil
+    }
+    if err != nil {
+        return 0, err
+    }
+    return strconv.ParseInt(val, 10, 64)
+}
+
+// LoadGeneration 读取某一代的全部链路，返回链路ID到链路JSON的映射。
+// 条目数量与元信息不一致时返回错误，避免应用不完整的配置。
+func (s *Subscriber) LoadGeneration(ctx context.Context, gen int64) (map[string]string, error) {
+    metaVal, err := s.client.Get(ctx, fmt.Sprintf("%s%d:meta", genKeyPrefix, gen)).Result()
+    if err == redis.Nil {
+        return nil, fmt.Errorf("generation %d does not exist", gen)
+    }
+    if err != nil {
+        return nil, err
+    }
+    var meta generationMeta
+    if err := json.Unmarshal([]byte(metaVal), &meta); err != nil {
+        return nil, fmt.Errorf("decode generation %d meta: %w", gen, err)
+    }
+
+    links, err := s.client.HGetAll(ctx, fmt.Sprintf("%s%d", genKeyPrefix, gen)).Result()
+    if err != nil {
+        return nil, err
+    }
+    if len(links) != meta.LinkCount {
+        return nil, fmt.Errorf("generation %d incomplete: %d/%d links", gen, len(links), meta.LinkCount)
+    }
+    return links, nil
+}
@@ -0,0 +1,33 @@
+package redis
+
+import (
+    "context"
+    "time"
+
+    "netsimlation/distribute/slave_server/redis_listener/internal/transport"
+)
+
+// SlaveKeyPrefix 从节点状态键前缀，主控端通过扫描该前缀获取在线的从节点
+const SlaveKeyPrefix = "netsim:slave:"
+
+// ReportStatus 写入本节点状态并设置过期时间，节点停止上报后状态自动消失
+func (s *Subscriber) ReportStatus(ctx context.Context, status transport.Status, ttl time.Duration) error {
+    clockSynced := 0
+    if status.ClockSynced {
+        clockSynced = 1
+    }
+    key := SlaveKeyPrefix + status.Node
+    pipe := s.client.Pipeline()
+    pipe.HSet(ctx, key, map[string]interface{}{
+        "version":      status.Version,
+        "iface":        status.Iface,
+        "applied_gen":  status.AppliedGen,
+        "links":        status.Links,
+        "offset_ms":    status.OffsetMs,
+        "clock_synced": clockSynced,
+        "last_seen":    status.LastSeen.UnixMilli(),
+    })
+    pipe.Expire(ctx, key, ttl)
+    _, err := pipe.Exec(ctx)
+    return err
+}
@@ -8,6 +8,7 @@ import (
 
     "github.com/go-redis/redis/v8"
     "netsimlation/distribute/slave_server/redis_listener/internal/config"
+    "netsimlation/distribute/slave_server/redis_listener/internal/transport"
 )
 
 type Subscriber struct {
@@ -16,14 +17,12 @@ type Subscriber struct {
     isRunning bool
 }
 
-type KeyEvent struct {
-    EventType string    `json:"event_type"`
-    Key       string    `json:"key"`
-    Value     string    `json:"value,omitempty"`
-    Timestamp time.Time `json:"timestamp"`
-}
+// KeyEvent Redis键空间事件，与传输层事件同构
+type KeyEvent = transport.Event
+
+type EventHandler = transport.EventHandler
 
-type EventHandler func(event KeyEvent)
+var _ transport.Transport = (*Subscriber)(nil)
 
 func NewSubscriber(cfg *config.RedisConfig) *Subscriber {
     return &Subscriber{
@@ -0,0 +1,274 @@
+package rpc
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "log"
+    "sort"
+    "strconv"
+    "sync"
+    "time"
+
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/credentials/insecure"
+
+    "netsimlation/distribute/slave_server/redis_listener/internal/clock"
+    "netsimlation/distribute/slave_server/redis_listener/internal/config"
+    "netsimlation/distribute/slave_server/redis_listener/internal/controlpb"
+    "netsimlation/distribute/slave_server/redis_listener/internal/link"
+    "netsimlation/distribute/slave_server/redis_listener/internal/transport"
+)
+
+const clockResponseTimeout = time.Second
+
+// ErrNotConnected 控制流尚未建立或已断开
+var ErrNotConnected = errors.New("control stream not connected")
+
+// Client 通过主控端的gRPC控制服务接收链路变更并上报状态。
+// 主控端推送的消息被转换为与Redis键空间事件同构的 transport.Event。
+type Client struct {
+    config  *config.Config
+    conn    *grpc.ClientConn
+    control controlpb.ControlClient
+
+    // gRPC 流不允许并发 Send，上报与注册共用该锁
+    sendMu sync.Mutex
+    stream controlpb.Control_ConnectClient
+}
+
+var _ transport.Transport = (*Client)(nil)
+
+func NewClient(cfg *config.Config) (*Client, error) {
+    conn, err := grpc.Dial(cfg.Grpc.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+    if err != nil {
+        return nil, fmt.Errorf("dial master %s: %w", cfg.Grpc.Addr, err)
+    }
+    return &Client{
+        config:  cfg,
+        conn:    conn,
+        control: controlpb.NewControlClient(conn),
+    }, nil
+}
+
+// Subscribe 建立控制流并持续接收推送，断开后按 reconnect_seconds 重连。
+// 每次连接后主控端都会先推送当前生效代与全部链路，并以 SnapshotDone 结束；
+// 断线期间的修改由推送补齐，被删除的链路由 transport.SnapshotEvent 交给守护进程清除。
+func (c *Client) Subscribe(ctx context.Context, handler transport.EventHandler) error {
+    retry := time.Duration(c.config.Grpc.ReconnectSeconds) * time.Second
+    for {
+        err := c.receive(ctx, handler)
+        if ctx.Err() != nil {
+            log.Println("停止接收主控端推送")
+            return nil
+        }
+        log.Printf("与主控端 %s 的控制流断开: %v，%v 后重连", c.config.Grpc.Addr, err, retry)
+
+        select {
+        case <-ctx.Done():
+            return nil
+        case <-time.After(retry):
+        }
+    }
+}
+
+// receive 建立一次控制流并处理推送直到断开
+func (c *Client) receive(ctx context.Context, handler transport.EventHandler) error {
+    stream, err := c.control.Connect(ctx)
+    if err != nil {
+        return err
+    }
+
+    c.sendMu.Lock()
+    err = stream.Send(&controlpb.SlaveMessage{Msg: &controlpb.SlaveMessage_Register{Register: &controlpb.Register{
+        Node:    c.config.Clock.NodeID,
+        Version: c.config.App.Version,
+        Iface:   c.config.Ebpf.Iface,
+    }}})
+    if err == nil {
+        c.stream = stream
+    }
+    c.sendMu.Unlock()
+    if err != nil {
+        return err
+    }
+    log.Printf("已连接主控端 %s，开始接收链路推送", c.config.Grpc.Addr)
+
+    defer func() {
+        c.sendMu.Lock()
+        c.stream = nil
+        c.sendMu.Unlock()
+    }()
+
+    // 全量推送中出现的链路，收到 SnapshotDone 后置为 nil
+    snapshot := make(map[string]bool)
+    for {
+        msg, err := stream.Recv()
+        if err == io.EOF {
+            return errors.New("stream closed by master")
+        }
+        if err != nil {
+            return err
+        }
+        if snapshot != nil {
+            if m, ok := msg.Msg.(*controlpb.MasterMessage_LinkUpdate); ok && m.LinkUpdate.GetLink() != nil {
+                snapshot[m.LinkUpdate.Link.Id] = true
+            }
+            if _, ok := msg.Msg.(*controlpb.MasterMessage_SnapshotDone); ok {
+                handler(snapshotEvent(snapshot))
+                snapshot = nil
+                continue
+            }
+        }
+        event, ok := toEvent(msg)
+        if !ok {
+            continue
+        }
+        // 按到达顺序同步处理，保证同一链路的变更不会乱序
+        handler(event)
+    }
+}
+
+// toEvent 将主控端推送转换为键空间事件
+func toEvent(msg *controlpb.MasterMessage) (transport.Event, bool) {
+    event := transport.Event{Timestamp: time.Now()}
+    switch m := msg.Msg.(type) {
+    case *controlpb.MasterMessage_LinkUpdate:
+        if m.LinkUpdate.GetLink() == nil {
+            return event, false
+        }
+        data, err := json.Marshal(fromPB(m.LinkUpdate.Link))
+        if err != nil {
+            return event, false
+        }
+        event.EventType = "set"
+        event.Key = link.KeyPrefix + m.LinkUpdate.Link.Id
+        event.Value = string(data)
+    case *controlpb.MasterMessage_LinkDelete:
+        event.EventType = "del"
+        event.Key = link.KeyPrefix + m.LinkDelete.Id
+    case *controlpb.MasterMessage_GenerationActivated:
+        event.EventType = "set"
+        event.Key = transport.GenActiveKey
+        event.Value = strconv.FormatInt(m.GenerationActivated.Generation, 10)
+    default:
+        return event, false
+    }
+    return event, true
+}
+
+// snapshotEvent 构造全量推送结束事件，Value 为推送中出现的链路ID
+func snapshotEvent(ids map[string]bool) transport.Event {
+    list := make([]string, 0, len(ids))
+    for id := range ids {
+        list = append(list, id)
+    }
+    sort.Strings(list)
+    data, _ := json.Marshal(list)
+    return transport.Event{EventType: transport.SnapshotEvent, Value: string(data), Timestamp: time.Now()}
+}
+
+func fromPB(l *controlpb.Link) *link.NetworkLink {
+    return &link.NetworkLink{
+        SourceMAC:      l.SourceMac,
+        DestNodeID:     int(l.DestNodeId),
+        PacketLossRate: l.PacketLossRate,
+        BandwidthBps:   l.BandwidthBps,
+        DelayMs:        l.DelayMs,
+        CreatedAt:      l.CreatedAt,
+        ApplyAt:        l.ApplyAt,
+        AdminState:     l.AdminState,
+
+        SourceContainer: l.SourceContainer,
+        DestContainer:   l.DestContainer,
+    }
+}
+
+func (c *Client) ActiveGeneration(ctx context.Context) (int64, error) {
+    gen, err := c.control.GetGeneration(ctx, &controlpb.GenerationRequest{HeaderOnly: true})
+    if err != nil {
+        return 0, err
+    }
+    return gen.Generation, nil
+}
+
+func (c *Client) LoadGeneration(ctx context.Context, gen int64) (map[string]string, error) {
+    resp, err := c.control.GetGeneration(ctx, &controlpb.GenerationRequest{Generation: gen})
+    if err != nil {
+        return nil, err
+    }
+    links := make(map[string]string, len(resp.Links))
+    for _, l := range resp.Links {
+        data, err := json.Marshal(fromPB(l))
+        if err != nil {
+            return nil, err
+        }
+        links[l.Id] = string(data)
+    }
+    return links, nil
+}
+
+// ClockExchange 逐次调用 SyncClock，超时的样本被丢弃，只要有一个样本成功即返回
+func (c *Client) ClockExchange(ctx context.Context, node string, samples int) ([]clock.Sample, error) {
+    var result []clock.Sample
+    var lastErr error
+    for seq := 0; seq < samples; seq++ {
+        callCtx, cancel := context.WithTimeout(ctx, clockResponseTimeout)
+        t0 := time.Now().UnixNano()
+        resp, err := c.control.SyncClock(callCtx, &controlpb.ClockRequest{Node: node, Seq: int32(seq), T0: t0})
+        t3 := time.Now().UnixNano()
+        cancel()
+        if err != nil {
+            if ctx.Err() != nil {
+                return nil, ctx.Err()
+            }
+            lastErr = err
+            continue
+        }
+        result = append(result, clock.Sample{T0: t0, T1: resp.T1, T2: resp.T2, T3: t3})
+    }
+    if len(result) == 0 {
+        return nil, fmt.Errorf("no clock response from master: %v", lastErr)
+    }
+    return result, nil
+}
+
+func (c *Client) ReportStatus(ctx context.Context, status transport.Status, ttl time.Duration) error {
+    return c.send(&controlpb.SlaveMessage{Msg: &controlpb.SlaveMessage_Status{Status: &controlpb.SlaveStatus{
+        Node:        status.Node,
+        Version:     status.Version,
+        Iface:       status.Iface,
+        AppliedGen:  status.AppliedGen,
+        Links:       int32(status.Links),
+        OffsetMs:    status.OffsetMs,
+        ClockSynced: status.ClockSynced,
+        LastSeen:    status.LastSeen.UnixMilli(),
+        TtlMs:       ttl.Milliseconds(),
+    }}})
+}
+
+func (c *Client) ReportApply(ctx context.Context, report transport.ApplyReport) error {
+    return c.send(&controlpb.SlaveMessage{Msg: &controlpb.SlaveMessage_ApplyReport{ApplyReport: &controlpb.ApplyReport{
+        Node:      report.Node,
+        Link:      report.Link,
+        ApplyAt:   report.ApplyAt,
+        AppliedAt: report.AppliedAt,
+        SkewMs:    report.SkewMs,
+        OffsetMs:  report.OffsetMs,
+    }}})
+}
+
+func (c *Client) send(msg *controlpb.SlaveMessage) error {
+    c.sendMu.Lock()
+    defer c.sendMu.Unlock()
+    if c.stream == nil {
+        return ErrNotConnected
+    }
+    return c.stream.Send(msg)
+}
+
+func (c *Client) Close() error {
+    return c.conn.Close()
+}
@@ -0,0 +1,73 @@
+package transport
+
+import (
+    "context"
+    "time"
+
+    "netsimlation/distribute/slave_server/redis_listener/internal/clock"
+)
+
+// 支持的传输方式，对应配置项 transport
+const (
+    TypeRedis = "redis"
+    TypeGRPC  = "grpc"
+)
+
+// GenActiveKey 生效配置代指针的键名。gRPC 传输收到代切换通知时以同一键名构造事件，
+// 守护进程因此无需区分事件来源。
+const GenActiveKey = "netsim:gen:active"
+
+// SnapshotEvent gRPC 传输在每次连接的全量推送结束时产生的事件类型，Value 为推送中出现的链路ID的JSON数组。
+// 守护进程据此删除断线期间已在主控端被删除的链路；Redis 传输不产生该事件
+const SnapshotEvent = "snapshot"
+
+// Event 一次配置变更事件，语义与Redis键空间事件一致：
+// EventType 为 set/del/expired，Key 为 network_link:<id> 或 GenActiveKey，set 事件的 Value 为键值；
+// 另有 SnapshotEvent，见上
+type Event struct {
+    EventType string    `json:"event_type"`
+    Key       string    `json:"key"`
+    Value     string    `json:"value,omitempty"`
+    Timestamp time.Time `json:"timestamp"`
+}
+
+type EventHandler func(event Event)
+
+// Status 从节点周期上报的运行状态
+type Status struct {
+    Node        string
+    Version     string
+    Iface       string
+    AppliedGen  int64
+    Links       int
+    OffsetMs    float64
+    ClockSynced bool
+    LastSeen    time.Time
+}
+
+// ApplyReport 定时变更的生效偏差上报
+type ApplyReport struct {
+    Node      string  `json:"node"`
+    Link      string  `json:"link"`
+    ApplyAt   int64   `json:"apply_at"`
+    AppliedAt int64   `json:"applied_at"`
+    SkewMs    float64 `json:"skew_ms"`
+    OffsetMs  float64 `json:"offset_ms"`
+}
+
+// Transport 从节点与主控端之间的通信方式
+type Transport interface {
+    // Subscribe 阻塞接收配置变更事件，直到上下文取消或连接不可恢复地断开
+    Subscribe(ctx context.Context, handler EventHandler) error
+    // ActiveGeneration 返回当前生效的代号，尚未激活任何代时返回0
+    ActiveGeneration(ctx context.Context) (int64, error)
+    // LoadGeneration 返回某一代的全部链路，链路ID到链路JSON的映射
+    LoadGeneration(ctx context.Context, gen int64) (map[string]string, error)
+    // ClockExchange 与主控端进行 samples 次时间戳交换
+    ClockExchange(ctx context.Context, node string, samples int) ([]clock.Sample, error)
+    // ReportStatus 上报本节点状态，主控端在 ttl 内未收到新的上报即视为离线
+    ReportStatus(ctx context.Context, status Status, ttl time.Duration) error
+    // ReportApply 上报定时变更的生效偏差
+    ReportApply(ctx context.Context, report ApplyReport) error
+    Close() error
+}